@@ -20,17 +20,17 @@ var (
 
 	// Name should be alphanum, underscore, slash
 	// Data should be anything permitted in JSON
-	regexpAlphaNum = regexp.MustCompile("^[a-zA-Z0-9._/]*$")
-	regexpJSON     = regexp.MustCompile(`^[a-zA-Z0-9_/ \-"':,\n\t.{}()\[\]]*$`)
+	RegexpAlphaNum = regexp.MustCompile("^[a-zA-Z0-9._/]*$")
+	RegexpJSON     = regexp.MustCompile(`^[a-zA-Z0-9_/ \-"':,\n\t.{}()\[\]]*$`)
 )
 
 // filter strings
 func validateNameRegEntryName(name string) bool {
-	return regexpAlphaNum.Match([]byte(name))
+	return RegexpAlphaNum.Match([]byte(name))
 }
 
 func validateNameRegEntryData(data string) bool {
-	return regexpJSON.Match([]byte(data))
+	return RegexpJSON.Match([]byte(data))
 }
 
 // base cost is "effective" number of bytes
@@ -39,12 +39,19 @@ func (err ErrPermission) Error() string {
 type Debug bool
 
 const (
-	dataStackCapacity       = 1024
-	callStackCapacity       = 100         // TODO ensure usage.
-	memoryCapacity          = 1024 * 1024 // 1 MB
-	dbg               Debug = true
+	dataStackCapacity = 1024
+	callStackCapacity = 100         // TODO ensure usage.
+	memoryCapacity    = 1024 * 1024 // 1 MB
 )
 
+var dbg Debug = true
+
+// SetDebug turns the vm's debug output to stdout on or off.
+// It's global, so set it before running any vms
+func SetDebug(on bool) {
+	dbg = Debug(on)
+}
+
 func (d Debug) Printf(s string, a ...interface{}) {
 	if d {
 		fmt.Printf(s, a...)
@@ -59,7 +66,8 @@ type VM struct {
 
 	callDepth int
 
-	evc events.Fireable
+	evc    events.Fireable
+	tracer Tracer
 }
 
 func NewVM(appState AppState, params Params, origin Word256, txid []byte) *VM {
@@ -77,6 +85,16 @@ func (vm *VM) SetFireable(evc events.Fireable) {
 	vm.evc = evc
 }
 
+// Tracer is called before each instruction is executed.
+// The stack and memory must not be retained or modified.
+type Tracer interface {
+	Step(depth int, pc int64, op OpCode, gas int64, stack []Word256, memory []byte)
+}
+
+func (vm *VM) SetTracer(tracer Tracer) {
+	vm.tracer = tracer
+}
+
 // CONTRACT: it is the duty of the contract writer to call known permissions
 // we do not convey if a permission is not set
 // (unlike in state/execution, where we guarantee HasPermission is called
@@ -171,6 +189,9 @@ func (vm *VM) call(caller, callee *Account, code, input []byte, value int64, gas
 
 		var op = codeGetOp(code, pc)
 		dbg.Printf("(pc) %-3d (op) %-14s (st) %-4d ", pc, op.String(), stack.Len())
+		if vm.tracer != nil {
+			vm.tracer.Step(vm.callDepth, pc, op, *gas, stack.data[:stack.ptr], memory)
+		}
 
 		switch op {
 
@@ -0,0 +1,61 @@
+package fakenode
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/logger"
+)
+
+var log = logger.New("module", "fakenode")
+
+//------------------------------------------------------------------------------------
+// a fake eris-keys, with the keys of the genesis accounts and validator
+
+type keysResponse struct {
+	Response string
+	Error    string
+}
+
+// POST /keys/sign {"hash", "addr"} and /keys/pub {"addr"}
+func (n *Node) serveKeys(w http.ResponseWriter, r *http.Request) {
+	res, err := n.keysRequest(r)
+	resp := keysResponse{Response: res}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (n *Node) keysRequest(r *http.Request) (string, error) {
+	var args map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		return "", fmt.Errorf("bad request: %v", err)
+	}
+	addr, err := hex.DecodeString(args["addr"])
+	if err != nil {
+		return "", fmt.Errorf("bad addr: %v", err)
+	}
+	key, ok := n.keys[string(addr)]
+	if !ok {
+		return "", fmt.Errorf("unknown key %X", addr)
+	}
+
+	switch r.URL.Path {
+	case "/keys/pub":
+		pub := key.PubKey.(acm.PubKeyEd25519)
+		return fmt.Sprintf("%X", pub[:]), nil
+	case "/keys/sign":
+		hash, err := hex.DecodeString(args["hash"])
+		if err != nil {
+			return "", fmt.Errorf("bad hash: %v", err)
+		}
+		sig := key.PrivKey.Sign(hash).(acm.SignatureEd25519)
+		return fmt.Sprintf("%X", sig[:]), nil
+	}
+	return "", fmt.Errorf("unknown method %s", r.URL.Path)
+}
@@ -0,0 +1,258 @@
+// Package fakenode is an in-process tendermint node for tests.
+//
+// It serves the rpc methods used by cclient.Client and the websocket events
+// mintx waits on, from a vendored state.State that commits the txs it's sent
+// into a block on a timer. It also serves a fake eris-keys daemon holding the
+// keys of the genesis accounts, so mintx can sign, broadcast and wait on txs
+// without a network, a tendermint or an eris-keys.
+//
+//	node := fakenode.New(fakenode.Config{})
+//	defer node.Close()
+//	c := client.New(node.ChainID, node.URL, client.NewKeysDaemon(node.KeysURL))
+package fakenode
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	cfg "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/config"
+	dbm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/db"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/events"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	rpcserver "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/server"
+	sm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/state"
+	stypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/state/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/wire"
+)
+
+var (
+	DefaultChainID       = "fakenode"
+	DefaultBalance       = int64(1000000000)
+	DefaultBlockInterval = 100 * time.Millisecond
+)
+
+type Config struct {
+	ChainID  string
+	Accounts int   // how many funded accounts to make. The first has every permission. Default 1
+	Balance  int64 // of each account
+
+	// how often to commit the txs we've been sent.
+	// If negative, blocks are only made by calling Commit
+	BlockInterval time.Duration
+
+	// the global permissions. Default ptypes.DefaultAccountPermissions
+	GlobalPermissions *ptypes.AccountPermissions
+
+	// tendermint's log level. Default "warn"
+	LogLevel string
+}
+
+type Node struct {
+	ChainID  string
+	Accounts []*acm.PrivAccount // the genesis accounts, whose keys the keys daemon holds
+
+	URL     string // the rpc, eg. for cclient.NewClient or --node-addr
+	KeysURL string // the keys daemon, eg. for --sign-addr
+
+	mtx     sync.Mutex
+	genDoc  *stypes.GenesisDoc
+	genHash []byte
+	state   *sm.State
+	mempool *sm.BlockCache // the state with the pending txs run on it
+	pending []types.Tx
+	blocks  []*types.Block
+	keys    map[string]*acm.PrivAccount
+
+	evsw   *wsEvents
+	server *httptest.Server
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+// start a node serving on localhost
+func New(config Config) *Node {
+	if config.ChainID == "" {
+		config.ChainID = DefaultChainID
+	}
+	if config.Accounts == 0 {
+		config.Accounts = 1
+	}
+	if config.Balance == 0 {
+		config.Balance = DefaultBalance
+	}
+	if config.BlockInterval == 0 {
+		config.BlockInterval = DefaultBlockInterval
+	}
+	if config.LogLevel == "" {
+		config.LogLevel = "warn"
+	}
+
+	// the tendermint packages hash blocks with the chain id from the global config,
+	// so there should only be one chain per process
+	cfg.ApplyConfig(cfg.MapConfig{
+		"chain_id":  config.ChainID,
+		"log_level": config.LogLevel,
+	})
+
+	n := &Node{
+		ChainID: config.ChainID,
+		keys:    make(map[string]*acm.PrivAccount),
+		evsw:    newWSEvents(),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	n.genDoc = n.makeGenesis(config)
+	n.genHash = wire.BinarySha256(n.genDoc)
+	n.state = sm.MakeGenesisState(dbm.NewMemDB(), n.genDoc)
+	n.mempool = sm.NewBlockCache(n.state)
+
+	mux := http.NewServeMux()
+	funcMap := n.rpcFuncs()
+	rpcserver.RegisterRPCFuncs(mux, funcMap)
+	mux.HandleFunc("/websocket", n.evsw.handler(funcMap))
+	mux.HandleFunc("/keys/", n.serveKeys)
+	n.server = httptest.NewUnstartedServer(mux)
+	n.server.Config.ConnState = n.evsw.trackConn
+	n.server.Start()
+	n.URL = n.server.URL + "/"
+	n.KeysURL = n.server.URL + "/keys"
+
+	if config.BlockInterval > 0 {
+		go n.commitRoutine(config.BlockInterval)
+	} else {
+		close(n.done)
+	}
+	return n
+}
+
+func (n *Node) makeGenesis(config Config) *stypes.GenesisDoc {
+	genDoc := &stypes.GenesisDoc{
+		GenesisTime: time.Now(),
+		ChainID:     config.ChainID,
+	}
+	if config.GlobalPermissions != nil {
+		genDoc.Params = &stypes.GenesisParams{GlobalPermissions: config.GlobalPermissions}
+	}
+	for i := 0; i < config.Accounts; i++ {
+		key := acm.GenPrivAccountFromSecret(fmt.Sprintf("%s/%d", config.ChainID, i))
+		perms := ptypes.DefaultAccountPermissions
+		if i == 0 {
+			perms.Base.Perms = ptypes.AllPermFlags
+		}
+		genDoc.Accounts = append(genDoc.Accounts, stypes.GenesisAccount{
+			Address:     key.Address,
+			Amount:      config.Balance,
+			Name:        fmt.Sprintf("account%d", i),
+			Permissions: &perms,
+		})
+		n.Accounts = append(n.Accounts, key)
+		n.keys[string(key.Address)] = key
+	}
+
+	// someone has to be bonded
+	val := acm.GenPrivAccountFromSecret(config.ChainID + "/validator")
+	genDoc.Validators = []stypes.GenesisValidator{{
+		PubKey:   val.PubKey.(acm.PubKeyEd25519),
+		Amount:   1,
+		Name:     "validator",
+		UnbondTo: []stypes.BasicAccount{{Address: val.Address, Amount: 1}},
+	}}
+	n.keys[string(val.Address)] = val
+	return genDoc
+}
+
+// stop serving and making blocks
+func (n *Node) Close() {
+	select {
+	case <-n.quit:
+		return
+	default:
+	}
+	close(n.quit)
+	<-n.done
+	n.server.Close()
+	n.evsw.Close()
+}
+
+func (n *Node) commitRoutine(interval time.Duration) {
+	defer close(n.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			n.Commit()
+		case <-n.quit:
+			return
+		}
+	}
+}
+
+// make a block from the pending txs, and fire its events
+func (n *Node) Commit() *types.Block {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	// txs run against the last block's height, as in state.ExecBlock
+	s := n.state
+	cache := sm.NewBlockCache(s)
+	evc := events.NewEventCache(n.evsw)
+	var txs []types.Tx
+	for _, tx := range n.pending {
+		if err := sm.ExecTx(cache, tx, true, evc); err != nil {
+			// it passed the mempool, so this shouldn't happen
+			log.Warn("Dropping tx that failed in block", "error", err)
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	cache.Sync()
+
+	block := &types.Block{
+		Header: &types.Header{
+			ChainID:        n.ChainID,
+			Height:         s.LastBlockHeight + 1,
+			Time:           time.Now(),
+			NumTxs:         len(txs),
+			LastBlockHash:  s.LastBlockHash,
+			LastBlockParts: s.LastBlockParts,
+			StateHash:      s.Hash(),
+		},
+		Data:           &types.Data{Txs: txs},
+		LastValidation: &types.Validation{},
+	}
+
+	s.LastBlockHeight = block.Height
+	s.LastBlockHash = block.Hash()
+	s.LastBlockParts = block.MakePartSet().Header()
+	s.LastBlockTime = block.Time
+	// as the node does after a block. The trees can't be copied (eg. to list names) until they're saved
+	s.Save()
+	n.blocks = append(n.blocks, block)
+	n.pending = nil
+	n.mempool = sm.NewBlockCache(s)
+
+	// clients waiting on a tx expect the block before its events
+	n.evsw.FireEvent(types.EventStringNewBlock(), types.EventDataNewBlock{Block: block})
+	evc.Flush()
+	return block
+}
+
+// the height of the last block
+func (n *Node) Height() int {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return n.state.LastBlockHeight
+}
+
+// the account at addr, or nil
+func (n *Node) Account(addr []byte) *acm.Account {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return n.state.GetAccount(addr)
+}
@@ -0,0 +1,178 @@
+package fakenode
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eris-ltd/mint-client/mintx/client"
+
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	. "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/common"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+func TestWait(t *testing.T) {
+	node := New(Config{Accounts: 2})
+	defer node.Close()
+	from, to := node.Accounts[0], node.Accounts[1]
+	c := client.New(node.ChainID, node.URL, client.NewKeysDaemon(node.KeysURL))
+	ctx := context.Background()
+
+	tx, err := c.Send(ctx, client.SendOpts{Input: client.Input{Address: from.Address}, To: to.Address, Amount: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := c.SignAndBroadcast(ctx, tx, true, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result.Hash, types.TxID(node.ChainID, tx)) || result.BlockHash == nil {
+		t.Fatalf("bad result %v", result)
+	}
+	if acc := node.Account(to.Address); acc.Balance != DefaultBalance+10 {
+		t.Fatalf("expected balance %d, got %d", DefaultBalance+10, acc.Balance)
+	}
+
+	// the nonce comes from the committed state
+	name, err := c.Name(ctx, client.NameOpts{Input: client.Input{Address: from.Address}, Amount: 1000, Name: "fake", Data: "node"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name.Input.Sequence != 2 {
+		t.Fatalf("expected nonce 2, got %d", name.Input.Sequence)
+	}
+	if _, err := c.SignAndBroadcast(ctx, name, true, true, true); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := c.RPC.GetName("fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Entry.Data != "node" || !bytes.Equal(entry.Entry.Owner, from.Address) {
+		t.Fatalf("bad entry %v", entry.Entry)
+	}
+
+	// replays don't make it into the mempool
+	if _, err := c.SignAndBroadcast(ctx, name, false, true, false); err == nil {
+		t.Fatal("expected error rebroadcasting tx")
+	}
+}
+
+func TestRPC(t *testing.T) {
+	node := New(Config{BlockInterval: -1})
+	defer node.Close()
+	key := node.Accounts[0]
+
+	for _, rpc := range []cclient.Client{cclient.NewClient(node.URL, "JSONRPC"), cclient.NewClient(node.URL, "HTTP")} {
+		status, err := rpc.Status()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.NodeInfo.ChainID != node.ChainID || status.LatestBlockHeight != node.Height() {
+			t.Fatalf("bad status %v", status)
+		}
+		accounts, err := rpc.ListAccounts()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the account and the validator
+		if len(accounts.Accounts) != 2 {
+			t.Fatalf("expected 2 accounts, got %d", len(accounts.Accounts))
+		}
+
+		// PUSH1 5 PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+		code := []byte{0x60, 0x05, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xF3}
+		call, err := rpc.CallCode(key.Address, code, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(call.Return, LeftPadBytes([]byte{5}, 32)) {
+			t.Fatalf("bad return %X", call.Return)
+		}
+	}
+
+	// blocks are only made when we ask
+	rpc := cclient.NewClient(node.URL, "JSONRPC")
+	tx := types.NewSendTx()
+	tx.AddInputWithNonce(key.PubKey, 1, 1)
+	tx.AddOutput(acm.GenPrivAccountFromSecret("new").Address, 1)
+	tx.Inputs[0].Signature = key.Sign(node.ChainID, tx).(acm.SignatureEd25519)
+	if _, err := rpc.BroadcastTx(tx); err != nil {
+		t.Fatal(err)
+	}
+	if unconfirmed, err := rpc.ListUnconfirmedTxs(); err != nil || len(unconfirmed.Txs) != 1 {
+		t.Fatalf("expected 1 unconfirmed tx, got %v %v", unconfirmed, err)
+	}
+
+	block := node.Commit()
+	if len(block.Txs) != 1 || node.Height() != 1 {
+		t.Fatalf("expected a block at height 1 with 1 tx, got %d at %d", len(block.Txs), node.Height())
+	}
+	got, err := rpc.GetBlock(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.BlockMeta.Hash, block.Header.Hash()) || len(got.Block.Txs) != 1 {
+		t.Fatalf("bad block %v", got.BlockMeta)
+	}
+	if chain, err := rpc.BlockchainInfo(0, 0); err != nil || chain.LastHeight != 1 || len(chain.BlockMetas) != 1 {
+		t.Fatalf("bad blockchain %v %v", chain, err)
+	}
+}
+
+func TestWebsocket(t *testing.T) {
+	node := New(Config{BlockInterval: -1})
+	defer node.Close()
+
+	// every connection gets the block, and Close hangs them up
+	wsAddr := client.WebsocketAddr(strings.TrimSuffix(node.URL, "/"))
+	var conns []*cclient.WSClient
+	for i := 0; i < 3; i++ {
+		wsc := cclient.NewWSClient(wsAddr)
+		if _, err := wsc.Start(); err != nil {
+			t.Fatal(err)
+		}
+		if err := wsc.Subscribe(types.EventStringNewBlock()); err != nil {
+			t.Fatal(err)
+		}
+		conns = append(conns, wsc)
+	}
+	// subscribing isn't acknowledged, so commit until everyone has a block
+	for _, wsc := range conns {
+		timeout := time.After(5 * time.Second)
+	wait:
+		for {
+			node.Commit()
+			select {
+			case ev := <-wsc.EventsCh:
+				if block := ev.Data.(types.EventDataNewBlock).Block; block.Height == 0 {
+					t.Fatalf("bad block %v", block)
+				}
+				break wait
+			case <-time.After(10 * time.Millisecond):
+			case <-timeout:
+				t.Fatal("timed out waiting for a block")
+			}
+		}
+	}
+
+	node.Close()
+	for _, wsc := range conns {
+		timeout := time.After(5 * time.Second)
+	hangup:
+		for {
+			select {
+			case <-wsc.EventsCh:
+				// it stops reading when it's full
+			case <-wsc.Quit:
+				break hangup
+			case <-timeout:
+				t.Fatal("expected Close to hang up the websocket")
+			}
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package fakenode
+
+import (
+	"fmt"
+
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	. "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/common"
+	ctypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core/types"
+	rpcserver "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/server"
+	sm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/state"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/vm"
+)
+
+//------------------------------------------------------------------------------------
+// the rpc methods cclient.Client calls, named as in core_client
+
+func (n *Node) rpcFuncs() map[string]*rpcserver.RPCFunc {
+	return map[string]*rpcserver.RPCFunc{
+		"status":               rpcserver.NewRPCFunc(n.status, []string{}),
+		"net_info":             rpcserver.NewRPCFunc(n.netInfo, []string{}),
+		"blockchain":           rpcserver.NewRPCFunc(n.blockchainInfo, []string{"minHeight", "maxHeight"}),
+		"genesis":              rpcserver.NewRPCFunc(n.genesis, []string{}),
+		"get_block":            rpcserver.NewRPCFunc(n.getBlock, []string{"height"}),
+		"get_account":          rpcserver.NewRPCFunc(n.getAccount, []string{"address"}),
+		"get_storage":          rpcserver.NewRPCFunc(n.getStorage, []string{"address", "key"}),
+		"call":                 rpcserver.NewRPCFunc(n.call, []string{"fromAddress", "toAddress", "data"}),
+		"call_code":            rpcserver.NewRPCFunc(n.callCode, []string{"fromAddress", "code", "data"}),
+		"list_validators":      rpcserver.NewRPCFunc(n.listValidators, []string{}),
+		"dump_storage":         rpcserver.NewRPCFunc(n.dumpStorage, []string{"address"}),
+		"broadcast_tx":         rpcserver.NewRPCFunc(n.broadcastTx, []string{"tx"}),
+		"list_unconfirmed_txs": rpcserver.NewRPCFunc(n.listUnconfirmedTxs, []string{}),
+		"list_accounts":        rpcserver.NewRPCFunc(n.listAccounts, []string{}),
+		"get_name":             rpcserver.NewRPCFunc(n.getName, []string{"name"}),
+		"list_names":           rpcserver.NewRPCFunc(n.listNames, []string{}),
+	}
+}
+
+func (n *Node) status() (*ctypes.ResultStatus, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	s := n.state
+	val := n.genDoc.Validators[0]
+	return &ctypes.ResultStatus{
+		NodeInfo: &types.NodeInfo{
+			PubKey:  val.PubKey,
+			Moniker: "fakenode",
+			ChainID: n.ChainID,
+			Version: "0.0.0",
+		},
+		GenesisHash:       n.genHash,
+		PubKey:            val.PubKey,
+		LatestBlockHash:   s.LastBlockHash,
+		LatestBlockHeight: s.LastBlockHeight,
+		LatestBlockTime:   s.LastBlockTime.UnixNano(),
+	}, nil
+}
+
+func (n *Node) netInfo() (*ctypes.ResultNetInfo, error) {
+	return &ctypes.ResultNetInfo{Listening: true, Listeners: []string{n.URL}}, nil
+}
+
+func (n *Node) genesis() (*ctypes.ResultGenesis, error) {
+	return &ctypes.ResultGenesis{Genesis: n.genDoc}, nil
+}
+
+func (n *Node) blockchainInfo(minHeight, maxHeight int) (*ctypes.ResultBlockchainInfo, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	last := len(n.blocks)
+	if maxHeight == 0 || maxHeight > last {
+		maxHeight = last
+	}
+	if minHeight < 1 {
+		minHeight = 1
+	}
+	var metas []*types.BlockMeta
+	for h := maxHeight; h >= minHeight; h-- {
+		metas = append(metas, blockMeta(copyBlock(n.blocks[h-1])))
+	}
+	return &ctypes.ResultBlockchainInfo{LastHeight: last, BlockMetas: metas}, nil
+}
+
+func (n *Node) getBlock(height int) (*ctypes.ResultGetBlock, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if height < 1 || height > len(n.blocks) {
+		return nil, fmt.Errorf("height must be between 1 and %d", len(n.blocks))
+	}
+	// the result is serialized after we unlock
+	block := copyBlock(n.blocks[height-1])
+	return &ctypes.ResultGetBlock{BlockMeta: blockMeta(block), Block: block}, nil
+}
+
+// the meta shares the block's header, so pass a copy
+func blockMeta(block *types.Block) *types.BlockMeta {
+	return types.NewBlockMeta(block, block.MakePartSet())
+}
+
+func (n *Node) getAccount(address []byte) (*ctypes.ResultGetAccount, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return &ctypes.ResultGetAccount{Account: n.state.GetAccount(address)}, nil
+}
+
+func (n *Node) listAccounts() (*ctypes.ResultListAccounts, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	var accounts []*acm.Account
+	n.state.GetAccounts().Iterate(func(key interface{}, value interface{}) bool {
+		accounts = append(accounts, value.(*acm.Account))
+		return false
+	})
+	return &ctypes.ResultListAccounts{BlockHeight: n.state.LastBlockHeight, Accounts: accounts}, nil
+}
+
+func (n *Node) getStorage(address, key []byte) (*ctypes.ResultGetStorage, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	acc := n.state.GetAccount(address)
+	if acc == nil {
+		return nil, fmt.Errorf("Unknown address: %X", address)
+	}
+	storage := n.state.LoadStorage(acc.StorageRoot)
+	_, value := storage.Get(LeftPadWord256(key).Bytes())
+	if value == nil {
+		return &ctypes.ResultGetStorage{Key: key}, nil
+	}
+	return &ctypes.ResultGetStorage{Key: key, Value: value.([]byte)}, nil
+}
+
+func (n *Node) dumpStorage(address []byte) (*ctypes.ResultDumpStorage, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	acc := n.state.GetAccount(address)
+	if acc == nil {
+		return nil, fmt.Errorf("Unknown address: %X", address)
+	}
+	var items []ctypes.StorageItem
+	n.state.LoadStorage(acc.StorageRoot).Iterate(func(key interface{}, value interface{}) bool {
+		items = append(items, ctypes.StorageItem{Key: key.([]byte), Value: value.([]byte)})
+		return false
+	})
+	return &ctypes.ResultDumpStorage{StorageRoot: acc.StorageRoot, StorageItems: items}, nil
+}
+
+func (n *Node) call(fromAddress, toAddress, data []byte) (*ctypes.ResultCall, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	cache := sm.NewBlockCache(n.state)
+	callee := cache.GetAccount(toAddress)
+	if callee == nil {
+		return nil, fmt.Errorf("Account %X does not exist", toAddress)
+	}
+	return n.runCode(cache, fromAddress, toVMAccount(callee), callee.Code, data)
+}
+
+func (n *Node) callCode(fromAddress, code, data []byte) (*ctypes.ResultCall, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	cache := sm.NewBlockCache(n.state)
+	callee := &vm.Account{Address: LeftPadWord256([]byte{0})}
+	return n.runCode(cache, fromAddress, callee, code, data)
+}
+
+// run code without committing anything, as a simulated call
+func (n *Node) runCode(cache *sm.BlockCache, fromAddress []byte, callee *vm.Account, code, data []byte) (*ctypes.ResultCall, error) {
+	s := n.state
+	caller := &vm.Account{Address: LeftPadWord256(fromAddress)}
+	params := vm.Params{
+		BlockHeight: int64(s.LastBlockHeight),
+		BlockHash:   LeftPadWord256(s.LastBlockHash),
+		BlockTime:   s.LastBlockTime.Unix(),
+		GasLimit:    s.GetGasLimit(),
+	}
+	vmach := vm.NewVM(sm.NewTxCache(cache), params, caller.Address, nil)
+	gas := params.GasLimit
+	ret, err := vmach.Call(caller, callee, code, data, 0, &gas)
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultCall{Return: ret, GasUsed: params.GasLimit - gas}, nil
+}
+
+func toVMAccount(acc *acm.Account) *vm.Account {
+	return &vm.Account{
+		Address:     LeftPadWord256(acc.Address),
+		Balance:     acc.Balance,
+		Code:        acc.Code,
+		Nonce:       int64(acc.Sequence),
+		Permissions: acc.Permissions,
+	}
+}
+
+func (n *Node) listValidators() (*ctypes.ResultListValidators, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	s := n.state
+	return &ctypes.ResultListValidators{
+		BlockHeight:         s.LastBlockHeight,
+		BondedValidators:    s.BondedValidators.Validators,
+		UnbondingValidators: s.UnbondingValidators.Validators,
+	}, nil
+}
+
+func (n *Node) getName(name string) (*ctypes.ResultGetName, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	entry := n.state.GetNameRegEntry(name)
+	if entry == nil {
+		return nil, fmt.Errorf("Name %s not found", name)
+	}
+	return &ctypes.ResultGetName{Entry: entry}, nil
+}
+
+func (n *Node) listNames() (*ctypes.ResultListNames, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	var names []*types.NameRegEntry
+	n.state.GetNames().Iterate(func(key interface{}, value interface{}) bool {
+		names = append(names, value.(*types.NameRegEntry))
+		return false
+	})
+	return &ctypes.ResultListNames{BlockHeight: n.state.LastBlockHeight, Names: names}, nil
+}
+
+//------------------------------------------------------------------------------------
+// the mempool
+
+// check the tx against the state with the pending txs run on it,
+// and queue it for the next block
+func (n *Node) broadcastTx(tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if err := sm.ExecTx(n.mempool, tx, false, nil); err != nil {
+		// a failed tx can leave changes in the cache (eg. its input's pubkey),
+		// so start again from the txs that passed
+		n.mempool = sm.NewBlockCache(n.state)
+		for _, tx := range n.pending {
+			sm.ExecTx(n.mempool, tx, false, nil)
+		}
+		return nil, fmt.Errorf("Error broadcasting transaction: %v", err)
+	}
+	n.pending = append(n.pending, tx)
+
+	receipt := ctypes.Receipt{TxHash: types.TxID(n.ChainID, tx)}
+	if callTx, ok := tx.(*types.CallTx); ok && len(callTx.Address) == 0 {
+		receipt.CreatesContract = 1
+		receipt.ContractAddr = types.NewContractAddress(callTx.Input.Address, callTx.Input.Sequence)
+	}
+	return &ctypes.ResultBroadcastTx{Receipt: receipt}, nil
+}
+
+func (n *Node) listUnconfirmedTxs() (*ctypes.ResultListUnconfirmedTxs, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	txs := make([]types.Tx, len(n.pending))
+	copy(txs, n.pending)
+	return &ctypes.ResultListUnconfirmedTxs{Txs: txs}, nil
+}
@@ -0,0 +1,99 @@
+package fakenode
+
+import (
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/events"
+	rpcserver "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/server"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+// wsEvents gives each websocket connection its own event switch,
+// so each gets its own copy of a block. Connections serialize their events
+// concurrently, and formatting a block hashes it, which writes its header.
+// It implements events.Fireable
+type wsEvents struct {
+	mtx      sync.Mutex
+	switches map[*events.EventSwitch]struct{}
+	conns    map[net.Conn]struct{} // hijacked by the websocket handler
+	handlers sync.WaitGroup
+}
+
+func newWSEvents() *wsEvents {
+	return &wsEvents{
+		switches: make(map[*events.EventSwitch]struct{}),
+		conns:    make(map[net.Conn]struct{}),
+	}
+}
+
+func (ws *wsEvents) FireEvent(event string, data types.EventData) {
+	ws.mtx.Lock()
+	var switches []*events.EventSwitch
+	for evsw := range ws.switches {
+		switches = append(switches, evsw)
+	}
+	ws.mtx.Unlock()
+
+	for _, evsw := range switches {
+		msg := data
+		if block, ok := data.(types.EventDataNewBlock); ok {
+			msg = types.EventDataNewBlock{Block: copyBlock(block.Block)}
+		}
+		evsw.FireEvent(event, msg)
+	}
+}
+
+// serve a websocket connection with its own event switch.
+// The switch is never stopped, since a subscribe read just before the
+// connection closed can still be added to it after the handler returns
+func (ws *wsEvents) handler(funcMap map[string]*rpcserver.RPCFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		evsw := events.NewEventSwitch()
+		evsw.Start()
+		ws.mtx.Lock()
+		ws.switches[evsw] = struct{}{}
+		ws.handlers.Add(1)
+		ws.mtx.Unlock()
+		defer func() {
+			ws.mtx.Lock()
+			delete(ws.switches, evsw)
+			ws.mtx.Unlock()
+			ws.handlers.Done()
+		}()
+		rpcserver.NewWebsocketManager(funcMap, evsw).WebsocketHandler(w, r)
+	}
+}
+
+// for http.Server.ConnState. The server forgets hijacked connections,
+// so we close them
+func (ws *wsEvents) trackConn(conn net.Conn, state http.ConnState) {
+	ws.mtx.Lock()
+	defer ws.mtx.Unlock()
+	switch state {
+	case http.StateHijacked:
+		ws.conns[conn] = struct{}{}
+	case http.StateClosed:
+		delete(ws.conns, conn)
+	}
+}
+
+// close the websocket connections and wait for their handlers.
+// The http server must be closed first, so no handlers start
+func (ws *wsEvents) Close() {
+	ws.mtx.Lock()
+	for conn := range ws.conns {
+		conn.Close()
+	}
+	ws.conns = make(map[net.Conn]struct{})
+	ws.mtx.Unlock()
+	ws.handlers.Wait()
+}
+
+// a copy of the block for a reader that may format or hash it.
+// The txs are shared, the lazily hashed parts aren't
+func copyBlock(b *types.Block) *types.Block {
+	header, data, validation := *b.Header, *b.Data, *b.LastValidation
+	return &types.Block{Header: &header, Data: &data, LastValidation: &validation}
+}
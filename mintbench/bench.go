@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eris-ltd/mint-client/mintx/client"
+
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+var (
+	DefaultWait = 30 * time.Second
+
+	// where sends go when there's only one key
+	sinkAddress = acm.GenPrivAccountFromSecret("mintbench").Address
+)
+
+type Config struct {
+	ChainID  string
+	NodeAddr string
+	Keys     []*acm.PrivAccount
+	Mix      Mix
+
+	TPS         float64       // the rate to submit at. 0 to go as fast as the workers can
+	Concurrency int           // how many workers submit txs. Each owns some of the keys
+	Txs         int           // stop after submitting this many, if > 0
+	Duration    time.Duration // stop after this long, if > 0
+	Wait        time.Duration // how long to wait for commits once we stop. Default DefaultWait
+
+	Amount   int64
+	Fee      int64
+	GasLimit int64
+	CallTo   []byte // the contract calls go to. Defaults to the other keys
+	CallData []byte
+}
+
+type Bench struct {
+	config Config
+	rpc    cclient.Client
+	stats  *stats
+
+	sent  int // txs claimed by the workers, under mtx
+	mtx   sync.Mutex
+	seeds *rand.Rand
+}
+
+func NewBench(config Config) (*Bench, error) {
+	if config.ChainID == "" {
+		return nil, fmt.Errorf("a chain id must be given")
+	}
+	if len(config.Keys) == 0 {
+		return nil, fmt.Errorf("at least one key must be given")
+	}
+	if len(config.Mix) == 0 {
+		return nil, fmt.Errorf("a mix of txs must be given")
+	}
+	if config.Txs <= 0 && config.Duration <= 0 {
+		return nil, fmt.Errorf("one of the number of txs or the duration must be given")
+	}
+	if config.TPS < 0 {
+		return nil, fmt.Errorf("tps must not be negative")
+	}
+	if config.Concurrency <= 0 || config.Concurrency > len(config.Keys) {
+		config.Concurrency = len(config.Keys)
+	}
+	if config.Wait == 0 {
+		config.Wait = DefaultWait
+	}
+	return &Bench{
+		config: config,
+		rpc:    cclient.NewClient(config.NodeAddr, "JSONRPC"),
+		stats:  newStats(),
+		seeds:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+// submit txs until we've sent enough, the time is up or ctx is done,
+// then wait for them to be committed
+func (b *Bench) Run(ctx context.Context) (*Report, error) {
+	ws, err := b.subscribe()
+	if err != nil {
+		return nil, err
+	}
+	defer ws.Stop()
+	eventsDone := make(chan struct{})
+	defer close(eventsDone)
+	go b.readEvents(ws, eventsDone)
+
+	workers := make([]*worker, b.config.Concurrency)
+	for i := range workers {
+		workers[i] = &worker{bench: b, nonces: make(map[string]int), rand: rand.New(rand.NewSource(b.seeds.Int63()))}
+	}
+	for i, key := range b.config.Keys {
+		w := workers[i%len(workers)]
+		nonce, err := b.fetchNonce(key.Address)
+		if err != nil {
+			return nil, err
+		}
+		w.keys = append(w.keys, key)
+		w.nonces[string(key.Address)] = nonce
+	}
+
+	submitCtx, cancel := ctx, context.CancelFunc(func() {})
+	if b.config.Duration > 0 {
+		submitCtx, cancel = context.WithTimeout(ctx, b.config.Duration)
+	}
+	defer cancel()
+	tokens := b.limit(submitCtx)
+
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+			w.run(submitCtx, tokens)
+		}(w)
+	}
+	wg.Wait()
+
+	b.waitForCommits(ctx)
+	return b.stats.finish(), nil
+}
+
+// we only listen for blocks: the node drops websockets that fall
+// more than a few events behind, and a block is one event however many txs it has
+func (b *Bench) subscribe() (*cclient.WSClient, error) {
+	ws := cclient.NewWSClient(client.WebsocketAddr(b.config.NodeAddr))
+	if _, err := ws.Start(); err != nil {
+		return nil, fmt.Errorf("Error connecting to websocket: %v", err)
+	}
+	if err := ws.Subscribe(types.EventStringNewBlock()); err != nil {
+		ws.Stop()
+		return nil, fmt.Errorf("Error subscribing to NewBlock event: %v", err)
+	}
+	return ws, nil
+}
+
+func (b *Bench) readEvents(ws *cclient.WSClient, done chan struct{}) {
+	for {
+		select {
+		case result := <-ws.EventsCh:
+			data, ok := result.Data.(types.EventDataNewBlock)
+			if !ok || data.Block == nil || data.Block.Data == nil {
+				continue
+			}
+			now := time.Now()
+			for _, tx := range data.Block.Txs {
+				b.stats.committed(types.TxID(b.config.ChainID, tx), now)
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// a channel to take a token from before each tx, or nil if there's no rate limit
+func (b *Bench) limit(ctx context.Context) <-chan struct{} {
+	if b.config.TPS == 0 {
+		return nil
+	}
+	tokens := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / b.config.TPS))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				select {
+				case tokens <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return tokens
+}
+
+// claim the next tx, if we haven't sent enough
+func (b *Bench) claim() bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.config.Txs > 0 && b.sent >= b.config.Txs {
+		return false
+	}
+	b.sent++
+	return true
+}
+
+func (b *Bench) waitForCommits(ctx context.Context) {
+	timeout := time.After(b.config.Wait)
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for b.stats.numPending() > 0 {
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			logger.Infof("Gave up waiting for %d txs\n", b.stats.numPending())
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// the nonce of the last tx from addr the node has committed
+func (b *Bench) fetchNonce(addr []byte) (int, error) {
+	ac, err := b.rpc.GetAccount(addr)
+	if err != nil {
+		return 0, fmt.Errorf("Error getting account %X: %v", addr, err)
+	}
+	if ac == nil || ac.Account == nil {
+		return 0, fmt.Errorf("Account %X does not exist", addr)
+	}
+	return ac.Account.Sequence, nil
+}
+
+// someone other than key to send to
+func (b *Bench) other(key *acm.PrivAccount, r *rand.Rand) []byte {
+	keys := b.config.Keys
+	if len(keys) == 1 {
+		return sinkAddress
+	}
+	for {
+		if other := keys[r.Intn(len(keys))]; other != key {
+			return other.Address
+		}
+	}
+}
+
+//------------------------------------------------------------------------------------
+// workers
+
+// a worker sends txs from its own keys, so it can pipeline their nonces
+// without waiting for blocks
+type worker struct {
+	bench  *Bench
+	keys   []*acm.PrivAccount
+	nonces map[string]int // address -> the last nonce we used
+	next   int
+	rand   *rand.Rand
+}
+
+func (w *worker) run(ctx context.Context, tokens <-chan struct{}) {
+	for {
+		if tokens != nil {
+			select {
+			case <-tokens:
+			case <-ctx.Done():
+				return
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+		if !w.bench.claim() {
+			return
+		}
+		w.send()
+	}
+}
+
+func (w *worker) send() {
+	b := w.bench
+	key := w.keys[w.next%len(w.keys)]
+	w.next++
+	addr := string(key.Address)
+	kind := b.config.Mix.pick(w.rand)
+	tx := w.makeTx(kind, key, w.nonces[addr]+1)
+	txid := types.TxID(b.config.ChainID, tx)
+
+	b.stats.sending(txid, time.Now())
+	_, err := b.rpc.BroadcastTx(tx)
+	if err != nil {
+		b.stats.failed(txid, err)
+		logger.Debugf("Error broadcasting %s tx from %X: %v\n", kind, key.Address, err)
+		if nonce, ok := expectedNonce(err); ok {
+			w.nonces[addr] = nonce - 1
+		} else if errorCategory(err) == ErrorNonce {
+			if nonce, err := b.fetchNonce(key.Address); err == nil {
+				w.nonces[addr] = nonce
+			}
+		}
+		return
+	}
+	w.nonces[addr]++
+	b.stats.submitted(kind, time.Now())
+}
+
+var expectedNonceRe = regexp.MustCompile(`invalid sequence. Got \d+, expected (\d+)`)
+
+// the node tells us the nonce it wants
+func expectedNonce(err error) (int, bool) {
+	m := expectedNonceRe.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0, false
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n, true
+}
+
+func (w *worker) makeTx(kind string, key *acm.PrivAccount, nonce int) types.Tx {
+	c := w.bench.config
+	switch kind {
+	case "call":
+		to := c.CallTo
+		if len(to) == 0 {
+			to = w.bench.other(key, w.rand)
+		}
+		tx := types.NewCallTxWithNonce(key.PubKey, to, c.CallData, c.Amount, c.GasLimit, c.Fee, nonce)
+		tx.Input.Signature = key.Sign(c.ChainID, tx)
+		return tx
+	case "name":
+		name := fmt.Sprintf("mintbench/%X/%d", key.Address[:4], nonce)
+		data := "mintbench"
+		amt := types.NameCostPerBlock * types.BaseEntryCost(name, data) * int64(types.MinNameRegistrationPeriod)
+		tx := types.NewNameTxWithNonce(key.PubKey, name, data, amt, c.Fee, nonce)
+		tx.Input.Signature = key.Sign(c.ChainID, tx)
+		return tx
+	case "perms":
+		// turns on send for a bench key, which needs it anyway. The signer needs set_base,
+		// and the target must exist
+		target := key.Address
+		if len(w.bench.config.Keys) > 1 {
+			target = w.bench.other(key, w.rand)
+		}
+		args := &ptypes.SetBaseArgs{Address: target, Permission: ptypes.Send, Value: true}
+		tx := types.NewPermissionsTxWithNonce(key.PubKey, args, nonce)
+		tx.Input.Signature = key.Sign(c.ChainID, tx)
+		return tx
+	}
+	tx := types.NewSendTx()
+	tx.AddInputWithNonce(key.PubKey, c.Amount, nonce)
+	tx.AddOutput(w.bench.other(key, w.rand), c.Amount)
+	tx.SignInput(c.ChainID, 0, key)
+	return tx
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/eris-ltd/mint-client/fakenode"
+
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+func TestParseMix(t *testing.T) {
+	mix, err := ParseMix("send=3, name, call=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mix.String() != "send=3,name=1" {
+		t.Fatalf("bad mix %s", mix)
+	}
+	counts := make(map[string]int)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 4000; i++ {
+		counts[mix.pick(r)]++
+	}
+	if counts["send"] < 2800 || counts["send"] > 3200 || counts["call"] != 0 {
+		t.Fatalf("bad picks %v", counts)
+	}
+
+	for _, bad := range []string{"", "send=0", "bond=1", "send=x", "send=-1", "send,send"} {
+		if _, err := ParseMix(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestErrorCategory(t *testing.T) {
+	cases := map[error]string{
+		types.ErrTxInvalidSequence{Got: 3, Expected: 5}:                                ErrorNonce,
+		fmt.Errorf("Error broadcasting transaction: %v", types.ErrTxInsufficientFunds): ErrorFunds,
+		errors.New("Account 01 does not have Name permission"):                         ErrorPermission,
+		types.ErrTxInvalidSignature:                                                    ErrorSignature,
+		errors.New("Names must be registered for at least 5 blocks"):                   ErrorName,
+		&url.Error{Op: "Post", URL: "http://x", Err: errors.New("refused")}:            ErrorRPC,
+		errors.New("something else"):                                                   ErrorOther,
+	}
+	for err, category := range cases {
+		if got := errorCategory(err); got != category {
+			t.Fatalf("expected %s for %v, got %s", category, err, got)
+		}
+	}
+	if n, ok := expectedNonce(types.ErrTxInvalidSequence{Got: 3, Expected: 5}); !ok || n != 5 {
+		t.Fatalf("expected nonce 5, got %d %v", n, ok)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	r := &Report{}
+	for i := 1; i <= 100; i++ {
+		r.Latencies = append(r.Latencies, time.Duration(i)*time.Millisecond)
+	}
+	if r.Percentile(50) != 50*time.Millisecond || r.Percentile(99) != 99*time.Millisecond || r.Percentile(100) != 100*time.Millisecond {
+		t.Fatalf("bad percentiles %v %v %v", r.Percentile(50), r.Percentile(99), r.Percentile(100))
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	key := acm.GenPrivAccountFromSecret("bench")
+	f, err := ioutil.TempFile("", "mintbench")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	priv := key.PrivKey.(acm.PrivKeyEd25519)
+	fmt.Fprintf(f, "# keys\n\n%X\n", priv[:])
+	f.Close()
+
+	keys, err := LoadKeys(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || !bytes.Equal(keys[0].Address, key.Address) {
+		t.Fatalf("bad keys %v", keys)
+	}
+}
+
+func TestBench(t *testing.T) {
+	node := fakenode.New(fakenode.Config{Accounts: 3, BlockInterval: 20 * time.Millisecond})
+	defer node.Close()
+
+	mix, _ := ParseMix("send=3,call=1,name=1")
+	bench, err := NewBench(Config{
+		ChainID:     node.ChainID,
+		NodeAddr:    node.URL,
+		Keys:        node.Accounts,
+		Mix:         mix,
+		Concurrency: 2,
+		Txs:         30,
+		Wait:        5 * time.Second,
+		Amount:      1,
+		GasLimit:    1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := bench.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Submitted != 30 || report.Committed != 30 || len(report.Errors) != 0 {
+		t.Fatalf("expected 30 txs submitted and committed, got %d and %d with errors %v", report.Submitted, report.Committed, report.Errors)
+	}
+	if len(report.Latencies) != 30 || report.Percentile(50) <= 0 {
+		t.Fatalf("bad latencies %v", report.Latencies)
+	}
+
+	// 5 txs at 100 tx/s take at least 40ms
+	bench, err = NewBench(Config{
+		ChainID:  node.ChainID,
+		NodeAddr: node.URL,
+		Keys:     node.Accounts[:1],
+		Mix:      Mix{{"send", 1}},
+		TPS:      100,
+		Txs:      5,
+		Amount:   1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report, err = bench.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if report.Committed != 5 || report.SubmitTime < 40*time.Millisecond {
+		t.Fatalf("expected 5 txs over 40ms, got %d over %v", report.Committed, report.SubmitTime)
+	}
+
+	// only the first account may set permissions
+	mix, _ = ParseMix("perms")
+	bench, err = NewBench(Config{
+		ChainID:  node.ChainID,
+		NodeAddr: node.URL,
+		Keys:     node.Accounts[1:2],
+		Mix:      mix,
+		Txs:      3,
+		Wait:     time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err = bench.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Submitted != 0 || report.Errors[ErrorPermission] != 3 {
+		t.Fatalf("expected 3 permission errors, got %d submitted, errors %v", report.Submitted, report.Errors)
+	}
+}
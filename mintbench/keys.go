@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+)
+
+// load private keys from a file of hex ed25519 private keys (64 bytes), one per line.
+// Blank lines and lines starting with # are skipped
+func LoadKeys(file string) ([]*acm.PrivAccount, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys []*acm.PrivAccount
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keyBytes, err := hex.DecodeString(line)
+		if err != nil || len(keyBytes) != 64 {
+			return nil, fmt.Errorf("%s:%d: expected a 64 byte hex private key", file, n)
+		}
+		keys = append(keys, acm.GenPrivAccountFromPrivKeyBytes(keyBytes))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys in %s", file)
+	}
+	return keys, nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/codegangsta/cli"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/eris-ltd/common/go/log"
+)
+
+var (
+	DefaultNodeRPCHost = "pinkpenguin.chaintest.net"
+	DefaultNodeRPCPort = "46657"
+	DefaultNodeRPCAddr = "http://" + DefaultNodeRPCHost + ":" + DefaultNodeRPCPort
+
+	DefaultChainID string
+)
+
+// override the hardcoded defaults with env variables if they're set
+func init() {
+	nodeAddr := os.Getenv("MINTX_NODE_ADDR")
+	if nodeAddr != "" {
+		DefaultNodeRPCAddr = nodeAddr
+	}
+
+	chainID := os.Getenv("MINTX_CHAINID")
+	if chainID != "" {
+		DefaultChainID = chainID
+	}
+}
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "mintbench"
+	app.Usage = "Load a tendermint chain with txs and measure how fast they're committed"
+	app.Version = "0.0.1"
+	app.Author = "Ethan Buchman"
+	app.Email = "ethan@erisindustries.com"
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "node-addr",
+			Usage: "set the address of the tendermint rpc server",
+			Value: DefaultNodeRPCAddr,
+		},
+		cli.StringFlag{
+			Name:  "chainID",
+			Usage: "specify the chainID",
+			Value: DefaultChainID,
+		},
+		cli.StringFlag{
+			Name:  "keys",
+			Usage: "file of hex private keys to send from, one per line",
+		},
+		cli.StringFlag{
+			Name:  "mix",
+			Usage: "weighted mix of txs to send, eg. send=5,call=1,name=2,perms=1",
+			Value: "send=1",
+		},
+		cli.Float64Flag{
+			Name:  "tps",
+			Usage: "target rate of txs per second. 0 to send as fast as the workers can",
+		},
+		cli.IntFlag{
+			Name:  "concurrency",
+			Usage: "how many workers send txs. Each owns some of the keys. Defaults to one per key",
+		},
+		cli.IntFlag{
+			Name:  "txs",
+			Usage: "stop after submitting this many txs",
+		},
+		cli.DurationFlag{
+			Name:  "duration",
+			Usage: "stop after this long",
+		},
+		cli.DurationFlag{
+			Name:  "wait",
+			Usage: "how long to wait for txs to be committed once we stop",
+			Value: DefaultWait,
+		},
+		cli.IntFlag{
+			Name:  "amt",
+			Usage: "amount for sends and calls",
+			Value: 1,
+		},
+		cli.IntFlag{
+			Name:  "fee",
+			Usage: "fee for calls and names",
+		},
+		cli.IntFlag{
+			Name:  "gas",
+			Usage: "gas limit for calls",
+			Value: 1000,
+		},
+		cli.StringFlag{
+			Name:  "call-to",
+			Usage: "contract address for calls. Defaults to the other keys, which have no code",
+		},
+		cli.StringFlag{
+			Name:  "call-data",
+			Usage: "hex data for calls",
+		},
+		cli.BoolFlag{
+			Name:  "debug",
+			Usage: "print debug messages",
+		},
+	}
+	app.Before = before
+	app.After = after
+	app.Action = cliBench
+
+	app.Run(os.Args)
+}
+
+func before(c *cli.Context) error {
+	var level int
+	if c.Bool("debug") {
+		level = 2
+	}
+	log.SetLoggers(level, os.Stdout, os.Stderr)
+	return nil
+}
+
+func after(c *cli.Context) error {
+	log.Flush()
+	return nil
+}
+
+func cliBench(c *cli.Context) {
+	if c.String("keys") == "" {
+		ifExit(fmt.Errorf("a file of private keys must be given with --keys"))
+	}
+	keys, err := LoadKeys(c.String("keys"))
+	ifExit(err)
+	mix, err := ParseMix(c.String("mix"))
+	ifExit(err)
+	callTo, err := hex.DecodeString(c.String("call-to"))
+	if err != nil {
+		ifExit(fmt.Errorf("call-to is bad hex: %v", err))
+	}
+	callData, err := hex.DecodeString(c.String("call-data"))
+	if err != nil {
+		ifExit(fmt.Errorf("call-data is bad hex: %v", err))
+	}
+
+	bench, err := NewBench(Config{
+		ChainID:     c.String("chainID"),
+		NodeAddr:    c.String("node-addr"),
+		Keys:        keys,
+		Mix:         mix,
+		TPS:         c.Float64("tps"),
+		Concurrency: c.Int("concurrency"),
+		Txs:         c.Int("txs"),
+		Duration:    c.Duration("duration"),
+		Wait:        c.Duration("wait"),
+		Amount:      int64(c.Int("amt")),
+		Fee:         int64(c.Int("fee")),
+		GasLimit:    int64(c.Int("gas")),
+		CallTo:      callTo,
+		CallData:    callData,
+	})
+	ifExit(err)
+
+	// stop submitting on ctrl-c, but still report
+	ctx, cancel := context.WithCancel(context.Background())
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	go func() {
+		<-sigs
+		cancel()
+	}()
+
+	logger.Infof("Sending %s from %d keys to %s\n", mix, len(keys), c.String("node-addr"))
+	report, err := bench.Run(ctx)
+	ifExit(err)
+	report.Print(os.Stdout)
+}
+
+func ifExit(err error) {
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// the kinds of tx mintbench sends
+var TxKinds = []string{"send", "call", "name", "perms"}
+
+type mixEntry struct {
+	kind   string
+	weight int
+}
+
+// Mix is a weighted choice of tx kinds
+type Mix []mixEntry
+
+// parse a mix like "send=5,call=1,name=2". A kind without a weight gets 1
+func ParseMix(s string) (Mix, error) {
+	var mix Mix
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kind, weight := part, 1
+		if i := strings.Index(part, "="); i >= 0 {
+			kind = part[:i]
+			w, err := strconv.Atoi(part[i+1:])
+			if err != nil || w < 0 {
+				return nil, fmt.Errorf("bad weight for %s: %q", kind, part[i+1:])
+			}
+			weight = w
+		}
+		if !isTxKind(kind) {
+			return nil, fmt.Errorf("unknown tx kind %q. Options are %s", kind, strings.Join(TxKinds, ", "))
+		}
+		if seen[kind] {
+			return nil, fmt.Errorf("tx kind %s given twice", kind)
+		}
+		seen[kind] = true
+		if weight > 0 {
+			mix = append(mix, mixEntry{kind, weight})
+		}
+	}
+	if len(mix) == 0 {
+		return nil, fmt.Errorf("the mix must give some tx kind a weight")
+	}
+	return mix, nil
+}
+
+func isTxKind(kind string) bool {
+	for _, k := range TxKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Mix) total() int {
+	var total int
+	for _, e := range m {
+		total += e.weight
+	}
+	return total
+}
+
+// pick a kind in proportion to its weight
+func (m Mix) pick(r *rand.Rand) string {
+	n := r.Intn(m.total())
+	for _, e := range m {
+		if n < e.weight {
+			return e.kind
+		}
+		n -= e.weight
+	}
+	return m[len(m)-1].kind
+}
+
+func (m Mix) String() string {
+	parts := make([]string, len(m))
+	for i, e := range m {
+		parts[i] = fmt.Sprintf("%s=%d", e.kind, e.weight)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Report is what happened to the txs we sent
+type Report struct {
+	SubmitTime time.Duration // from the first broadcast to the last
+	TotalTime  time.Duration // from the first broadcast to the last commit we saw
+
+	Submitted   int            // accepted by the node's mempool
+	Committed   int            // seen in a block, over the websocket
+	SubmittedBy map[string]int // kind -> submitted
+	Errors      map[string]int // category -> broadcast errors
+
+	Latencies []time.Duration // from broadcast to commit, sorted
+}
+
+func (r *Report) SubmittedTPS() float64 {
+	return rate(r.Submitted, r.SubmitTime)
+}
+
+func (r *Report) CommittedTPS() float64 {
+	return rate(r.Committed, r.TotalTime)
+}
+
+func rate(n int, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / d.Seconds()
+}
+
+// the commit latency below which p percent of committed txs fall
+func (r *Report) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	i := int(p/100*float64(len(r.Latencies))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(r.Latencies) {
+		i = len(r.Latencies) - 1
+	}
+	return r.Latencies[i]
+}
+
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "submitted:  %d in %v (%.1f tx/s)\n", r.Submitted, r.SubmitTime, r.SubmittedTPS())
+	fmt.Fprintf(w, "committed:  %d in %v (%.1f tx/s)\n", r.Committed, r.TotalTime, r.CommittedTPS())
+	if r.Submitted > r.Committed {
+		fmt.Fprintf(w, "lost:       %d\n", r.Submitted-r.Committed)
+	}
+	fmt.Fprintf(w, "by kind:    %s\n", formatCounts(r.SubmittedBy))
+	if len(r.Latencies) > 0 {
+		fmt.Fprintf(w, "latency:    p50 %v  p90 %v  p99 %v  max %v\n",
+			r.Percentile(50), r.Percentile(90), r.Percentile(99), r.Latencies[len(r.Latencies)-1])
+	}
+	if len(r.Errors) > 0 {
+		fmt.Fprintf(w, "errors:     %s\n", formatCounts(r.Errors))
+	}
+}
+
+func formatCounts(counts map[string]int) string {
+	var keys []string
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%d", k, counts[k])
+	}
+	return strings.Join(parts, " ")
+}
+
+//------------------------------------------------------------------------------------
+// errors
+
+const (
+	ErrorNonce      = "nonce"
+	ErrorFunds      = "funds"
+	ErrorPermission = "permission"
+	ErrorSignature  = "signature"
+	ErrorName       = "name"
+	ErrorRPC        = "rpc"
+	ErrorOther      = "other"
+)
+
+// sort broadcast errors by cause. The node only gives us strings
+func errorCategory(err error) string {
+	switch err.(type) {
+	case *url.Error, net.Error:
+		return ErrorRPC
+	}
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "invalid sequence"):
+		return ErrorNonce
+	case strings.Contains(msg, "insufficient funds"):
+		return ErrorFunds
+	case strings.Contains(msg, "permission"):
+		return ErrorPermission
+	case strings.Contains(msg, "invalid signature"), strings.Contains(msg, "pubkey"):
+		return ErrorSignature
+	case strings.Contains(msg, "names must be"), strings.Contains(msg, "invalid string"):
+		return ErrorName
+	}
+	return ErrorOther
+}
+
+//------------------------------------------------------------------------------------
+// collecting the numbers
+
+type stats struct {
+	mtx sync.Mutex
+
+	first, lastSubmit, lastCommit time.Time
+
+	pending map[string]time.Time // txid -> when it was broadcast
+	report  Report
+}
+
+func newStats() *stats {
+	return &stats{
+		pending: make(map[string]time.Time),
+		report: Report{
+			SubmittedBy: make(map[string]int),
+			Errors:      make(map[string]int),
+		},
+	}
+}
+
+// remember when a tx went out, before we broadcast it, so we can't miss its commit
+func (s *stats) sending(txid []byte, t time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pending[string(txid)] = t
+	if s.first.IsZero() {
+		s.first = t
+	}
+}
+
+func (s *stats) submitted(kind string, t time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.report.Submitted++
+	s.report.SubmittedBy[kind]++
+	s.lastSubmit = t
+}
+
+func (s *stats) failed(txid []byte, err error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.pending, string(txid))
+	s.report.Errors[errorCategory(err)]++
+}
+
+// a tx is in a block. It may not be ours
+func (s *stats) committed(txid []byte, t time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	sent, ok := s.pending[string(txid)]
+	if !ok {
+		return
+	}
+	delete(s.pending, string(txid))
+	s.report.Committed++
+	s.report.Latencies = append(s.report.Latencies, t.Sub(sent))
+	s.lastCommit = t
+}
+
+func (s *stats) numPending() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.pending)
+}
+
+func (s *stats) finish() *Report {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	r := s.report
+	r.Latencies = append([]time.Duration(nil), r.Latencies...)
+	sort.Sort(durations(r.Latencies))
+	if !s.first.IsZero() {
+		r.SubmitTime = s.lastSubmit.Sub(s.first)
+		end := s.lastCommit
+		if end.Before(s.lastSubmit) {
+			end = s.lastSubmit
+		}
+		r.TotalTime = end.Sub(s.first)
+	}
+	return &r
+}
+
+type durations []time.Duration
+
+func (d durations) Len() int           { return len(d) }
+func (d durations) Less(i, j int) bool { return d[i] < d[j] }
+func (d durations) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
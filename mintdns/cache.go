@@ -0,0 +1,161 @@
+package main
+
+import (
+	"sync"
+
+	ctypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core/types"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------
+// name registry cache
+
+// Cache holds name reg entries until they expire.
+// Entries are refreshed from the node when a NameReg event for them comes in,
+// and the height is kept up to date with NewBlock events.
+// Without events, entries are refetched once they look expired.
+// Names the node doesn't have are cached until the height changes,
+// since they can only be registered in a new block
+type Cache struct {
+	client cclient.Client
+
+	mtx     sync.Mutex
+	height  int
+	entries map[string]*types.NameRegEntry
+	missing map[string]bool // at this height
+
+	// called for names that are newly cached, to subscribe to their events
+	subscribe func(name string)
+}
+
+func NewCache(client cclient.Client) *Cache {
+	return &Cache{
+		client:  client,
+		entries: make(map[string]*types.NameRegEntry),
+		missing: make(map[string]bool),
+	}
+}
+
+// load every entry in the registry
+func (c *Cache) Load() error {
+	r, err := c.client.ListNames()
+	if err != nil {
+		return err
+	}
+	c.mtx.Lock()
+	c.setHeight(r.BlockHeight)
+	var names []string
+	for _, entry := range r.Names {
+		if entry.Expires > c.height {
+			c.entries[entry.Name] = entry
+			names = append(names, entry.Name)
+		}
+	}
+	subscribe := c.subscribe
+	c.mtx.Unlock()
+
+	if subscribe != nil {
+		for _, name := range names {
+			subscribe(name)
+		}
+	}
+	return nil
+}
+
+func (c *Cache) SetSubscriber(subscribe func(name string)) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.subscribe = subscribe
+}
+
+func (c *Cache) SetHeight(height int) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.setHeight(height)
+}
+
+func (c *Cache) setHeight(height int) {
+	if height > c.height {
+		c.height = height
+		c.missing = make(map[string]bool)
+	}
+}
+
+// fetch the height from the node
+func (c *Cache) UpdateHeight() error {
+	status, err := c.client.Status()
+	if err != nil {
+		return err
+	}
+	c.SetHeight(status.LatestBlockHeight)
+	return nil
+}
+
+// the unexpired entry for a name, or nil if there isn't one
+func (c *Cache) Get(name string) (*types.NameRegEntry, error) {
+	c.mtx.Lock()
+	entry, ok := c.entries[name]
+	missing := c.missing[name]
+	height := c.height
+	c.mtx.Unlock()
+	if ok && entry.Expires > height {
+		return entry, nil
+	}
+	if missing {
+		return nil, nil
+	}
+	return c.Refresh(name)
+}
+
+// fetch an entry from the node
+func (c *Cache) Refresh(name string) (*types.NameRegEntry, error) {
+	status, err := c.client.Status()
+	if err != nil {
+		return nil, err
+	}
+	c.SetHeight(status.LatestBlockHeight)
+
+	// the node errors for names it doesn't have,
+	// and we know it's up since we just got its status
+	var entry *types.NameRegEntry
+	if r, err := c.client.GetName(name); err == nil && r != nil {
+		entry = r.Entry
+	}
+
+	c.mtx.Lock()
+	if entry == nil || entry.Expires <= c.height {
+		delete(c.entries, name)
+		// unless a newer block came in while we were asking
+		if c.height == status.LatestBlockHeight {
+			c.missing[name] = true
+		}
+		c.mtx.Unlock()
+		return nil, nil
+	}
+	delete(c.missing, name)
+	_, cached := c.entries[name]
+	c.entries[name] = entry
+	subscribe := c.subscribe
+	c.mtx.Unlock()
+
+	if !cached && subscribe != nil {
+		subscribe(name)
+	}
+	return entry, nil
+}
+
+func (c *Cache) HandleEvent(event ctypes.ResultEvent) {
+	switch data := event.Data.(type) {
+	case types.EventDataNewBlock:
+		if data.Block != nil {
+			c.SetHeight(data.Block.Height)
+		}
+	case types.EventDataTx:
+		if tx, ok := data.Tx.(*types.NameTx); ok {
+			if _, err := c.Refresh(tx.Name); err != nil {
+				logger.Errorf("Error refreshing %s: %v\n", tx.Name, err)
+			}
+		}
+	}
+}
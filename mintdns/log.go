@@ -0,0 +1,11 @@
+package main
+
+import (
+	. "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/eris-ltd/common/go/log"
+)
+
+var logger *Logger
+
+func init() {
+	logger = AddLogger("mintdns")
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/eris-ltd/mint-client/mintx/client"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/codegangsta/cli"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/eris-ltd/common/go/log"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+var (
+	DefaultNodeRPCHost = "pinkpenguin.chaintest.net"
+	DefaultNodeRPCPort = "46657"
+	DefaultNodeRPCAddr = "http://" + DefaultNodeRPCHost + ":" + DefaultNodeRPCPort
+
+	REQUEST_TYPE = "JSONRPC"
+
+	// how often to fetch the height when there are no events
+	HeightPollInterval = 5 * time.Second
+
+	// so a subscribe can't hang a lookup
+	wsWriteTimeout = 10 * time.Second
+)
+
+// override the hardcoded defaults with env variables if they're set
+func init() {
+	nodeAddr := os.Getenv("MINTX_NODE_ADDR")
+	if nodeAddr != "" {
+		DefaultNodeRPCAddr = nodeAddr
+	}
+}
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "mintdns"
+	app.Usage = "Serve DNS records from the name registry of a tendermint chain"
+	app.Version = "0.0.1"
+	app.Author = "Ethan Buchman"
+	app.Email = "ethan@erisindustries.com"
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "node-addr",
+			Usage: "set the address of the tendermint rpc server",
+			Value: DefaultNodeRPCAddr,
+		},
+		cli.StringFlag{
+			Name:  "listen",
+			Usage: "address to serve dns on, over udp and tcp",
+			Value: ":53",
+		},
+		cli.IntFlag{
+			Name:  "ttl",
+			Usage: "ttl in seconds for the records we serve",
+			Value: 60,
+		},
+		cli.BoolFlag{
+			Name:  "debug",
+			Usage: "print debug messages",
+		},
+	}
+	app.Before = before
+	app.After = after
+	app.Action = cliServe
+
+	app.Run(os.Args)
+}
+
+func before(c *cli.Context) error {
+	var level int
+	if c.Bool("debug") {
+		level = 2
+	}
+	log.SetLoggers(level, os.Stdout, os.Stderr)
+	return nil
+}
+
+func after(c *cli.Context) error {
+	log.Flush()
+	return nil
+}
+
+func cliServe(c *cli.Context) {
+	nodeAddr := c.String("node-addr")
+	cache := NewCache(cclient.NewClient(nodeAddr, REQUEST_TYPE))
+	eventsDown, err := subscribeEvents(cache, nodeAddr)
+	if err != nil {
+		logger.Errorf("%v, continuing without events\n", err)
+	}
+	go pollHeight(cache, eventsDown, HeightPollInterval)
+	ifExit(cache.Load())
+
+	listenAddr := c.String("listen")
+	udp, err := net.ListenPacket("udp", listenAddr)
+	ifExit(err)
+	tcp, err := net.Listen("tcp", listenAddr)
+	ifExit(err)
+
+	server := NewServer(cache, uint32(c.Int("ttl")))
+	logger.Infof("Serving dns on %s from %s\n", listenAddr, nodeAddr)
+	go func() {
+		ifExit(server.ServeTCP(tcp))
+	}()
+	ifExit(server.ServeUDP(udp))
+}
+
+// keep the cache fresh with events from the node.
+// If we can't connect, entries are refetched once they look expired.
+// The returned channel is closed when the events stop
+func subscribeEvents(cache *Cache, nodeAddr string) (<-chan struct{}, error) {
+	down := make(chan struct{})
+	close(down)
+
+	wsAddr := client.WebsocketAddr(nodeAddr)
+	wsClient := cclient.NewWSClient(wsAddr)
+	if _, err := wsClient.Start(); err != nil {
+		return down, fmt.Errorf("Error connecting to %s: %v", wsAddr, err)
+	}
+	if err := wsClient.Subscribe(types.EventStringNewBlock()); err != nil {
+		wsClient.Stop()
+		return down, fmt.Errorf("Error subscribing to NewBlock event: %v", err)
+	}
+	// names are subscribed to from every lookup,
+	// but the websocket only takes one writer at a time
+	var mtx sync.Mutex
+	cache.SetSubscriber(func(name string) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		wsClient.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		if err := wsClient.Subscribe(types.EventStringNameReg(name)); err != nil {
+			logger.Errorf("Error subscribing to NameReg event for %s: %v\n", name, err)
+		}
+	})
+	go func() {
+		for event := range wsClient.EventsCh {
+			cache.HandleEvent(event)
+		}
+	}()
+	return wsClient.Quit, nil
+}
+
+// once the events are down, fetch the height on a timer
+// so expired names stop being served
+func pollHeight(cache *Cache, eventsDown <-chan struct{}, interval time.Duration) {
+	<-eventsDown
+	logger.Infof("No events, fetching the height every %v\n", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := cache.UpdateHeight(); err != nil {
+			logger.Errorf("Error fetching the height: %v\n", err)
+		}
+	}
+}
+
+func exit(err error) {
+	fmt.Println(err)
+	os.Exit(1)
+}
+
+func ifExit(err error) {
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+//------------------------------------------------------------------------------
+// just enough of the dns wire format (rfc 1035) to answer queries
+
+const (
+	typeA     uint16 = 1
+	typeNS    uint16 = 2
+	typeCNAME uint16 = 5
+	typeTXT   uint16 = 16
+	typeAAAA  uint16 = 28
+
+	classIN uint16 = 1
+
+	rcodeSuccess  = 0
+	rcodeFormErr  = 1
+	rcodeServFail = 2
+	rcodeNXDomain = 3
+	rcodeNotImp   = 4
+
+	flagResponse      = 1 << 15
+	flagAuthoritative = 1 << 10
+	flagRecursion     = 1 << 8 // recursion desired
+	opcodeMask        = 0xf << 11
+
+	headerLen = 12
+)
+
+var recordTypes = map[string]uint16{
+	"A":     typeA,
+	"AAAA":  typeAAAA,
+	"CNAME": typeCNAME,
+	"NS":    typeNS,
+	"TXT":   typeTXT,
+}
+
+type question struct {
+	name   string // lower case, without the trailing dot
+	qtype  uint16
+	qclass uint16
+}
+
+type resourceRecord struct {
+	name  string
+	rtype uint16
+	ttl   uint32
+	rdata []byte
+}
+
+type query struct {
+	id       uint16
+	flags    uint16
+	question question
+}
+
+func parseQuery(b []byte) (*query, error) {
+	if len(b) < headerLen {
+		return nil, fmt.Errorf("message too short")
+	}
+	q := &query{
+		id:    binary.BigEndian.Uint16(b[0:]),
+		flags: binary.BigEndian.Uint16(b[2:]),
+	}
+	if q.flags&flagResponse != 0 {
+		return q, fmt.Errorf("message is not a query")
+	}
+	if binary.BigEndian.Uint16(b[4:]) != 1 {
+		return q, fmt.Errorf("expected one question")
+	}
+	name, off, err := parseName(b, headerLen)
+	if err != nil {
+		return q, err
+	}
+	if off+4 > len(b) {
+		return q, fmt.Errorf("question too short")
+	}
+	q.question = question{
+		name:   strings.ToLower(name),
+		qtype:  binary.BigEndian.Uint16(b[off:]),
+		qclass: binary.BigEndian.Uint16(b[off+2:]),
+	}
+	return q, nil
+}
+
+// parse a possibly compressed name starting at off,
+// returning it and the offset just after it
+func parseName(b []byte, off int) (string, int, error) {
+	var labels []string
+	end := -1
+	for jumps := 0; ; {
+		if off >= len(b) {
+			return "", 0, fmt.Errorf("name out of bounds")
+		}
+		l := int(b[off])
+		switch {
+		case l == 0:
+			if end < 0 {
+				end = off + 1
+			}
+			return strings.Join(labels, "."), end, nil
+		case l&0xc0 == 0xc0:
+			if off+1 >= len(b) {
+				return "", 0, fmt.Errorf("name out of bounds")
+			}
+			if jumps++; jumps > 10 {
+				return "", 0, fmt.Errorf("too many compression pointers")
+			}
+			if end < 0 {
+				end = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(b[off:]) & 0x3fff)
+		case l > 63:
+			return "", 0, fmt.Errorf("bad label length %d", l)
+		default:
+			if off+1+l > len(b) {
+				return "", 0, fmt.Errorf("label out of bounds")
+			}
+			labels = append(labels, string(b[off+1:off+1+l]))
+			off += 1 + l
+		}
+	}
+}
+
+func encodeName(name string) []byte {
+	var b []byte
+	name = strings.TrimSuffix(name, ".")
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			b = append(b, byte(len(label)))
+			b = append(b, label...)
+		}
+	}
+	return append(b, 0)
+}
+
+// txt data is a sequence of strings of up to 255 bytes
+func encodeTXT(s string) []byte {
+	var b []byte
+	for {
+		n := len(s)
+		if n > 255 {
+			n = 255
+		}
+		b = append(b, byte(n))
+		b = append(b, s[:n]...)
+		s = s[n:]
+		if len(s) == 0 {
+			return b
+		}
+	}
+}
+
+func (rr resourceRecord) encode() []byte {
+	b := encodeName(rr.name)
+	var fixed [10]byte
+	binary.BigEndian.PutUint16(fixed[0:], rr.rtype)
+	binary.BigEndian.PutUint16(fixed[2:], classIN)
+	binary.BigEndian.PutUint32(fixed[4:], rr.ttl)
+	binary.BigEndian.PutUint16(fixed[8:], uint16(len(rr.rdata)))
+	b = append(b, fixed[:]...)
+	return append(b, rr.rdata...)
+}
+
+// a response echoing the query's id, opcode and question
+func buildResponse(q *query, rcode int, answers, authority, additional []resourceRecord) []byte {
+	b := make([]byte, headerLen)
+	binary.BigEndian.PutUint16(b[0:], q.id)
+	flags := flagResponse | flagAuthoritative | q.flags&(opcodeMask|flagRecursion) | uint16(rcode)
+	binary.BigEndian.PutUint16(b[2:], flags)
+
+	if q.question.name != "" || q.question.qtype != 0 {
+		binary.BigEndian.PutUint16(b[4:], 1)
+		b = append(b, encodeName(q.question.name)...)
+		var qt [4]byte
+		binary.BigEndian.PutUint16(qt[0:], q.question.qtype)
+		binary.BigEndian.PutUint16(qt[2:], q.question.qclass)
+		b = append(b, qt[:]...)
+	}
+
+	binary.BigEndian.PutUint16(b[6:], uint16(len(answers)))
+	binary.BigEndian.PutUint16(b[8:], uint16(len(authority)))
+	binary.BigEndian.PutUint16(b[10:], uint16(len(additional)))
+	for _, section := range [][]resourceRecord{answers, authority, additional} {
+		for _, rr := range section {
+			b = append(b, rr.encode()...)
+		}
+	}
+	return b
+}
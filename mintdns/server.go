@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/eris-ltd/mint-client/mintx/core"
+)
+
+//------------------------------------------------------------------------------
+// dns server
+
+const (
+	maxUDPSize = 512
+	maxCNAMEs  = 8
+)
+
+type Server struct {
+	cache *Cache
+	ttl   uint32
+}
+
+func NewServer(cache *Cache, ttl uint32) *Server {
+	return &Server{cache: cache, ttl: ttl}
+}
+
+func (s *Server) ServeUDP(conn net.PacketConn) error {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		resp := s.Handle(buf[:n])
+		if resp == nil {
+			continue
+		}
+		if len(resp) > maxUDPSize {
+			resp = truncate(resp)
+		}
+		if _, err := conn.WriteTo(resp, addr); err != nil {
+			logger.Errorf("Error writing response to %v: %v\n", addr, err)
+		}
+	}
+}
+
+func (s *Server) ServeTCP(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveTCPConn(conn)
+	}
+}
+
+// messages over tcp are prefixed with their length
+func (s *Server) serveTCPConn(conn net.Conn) {
+	defer conn.Close()
+	for {
+		var l [2]byte
+		if _, err := io.ReadFull(conn, l[:]); err != nil {
+			return
+		}
+		msg := make([]byte, binary.BigEndian.Uint16(l[:]))
+		if _, err := io.ReadFull(conn, msg); err != nil {
+			return
+		}
+		resp := s.Handle(msg)
+		if resp == nil {
+			return
+		}
+		binary.BigEndian.PutUint16(l[:], uint16(len(resp)))
+		if _, err := conn.Write(append(l[:], resp...)); err != nil {
+			return
+		}
+	}
+}
+
+// too big for udp: send just the header and question with the truncated bit set
+// so the client retries over tcp
+func truncate(resp []byte) []byte {
+	_, end, err := parseName(resp, headerLen)
+	if err != nil || end+4 > len(resp) {
+		return resp[:headerLen]
+	}
+	resp = resp[:end+4]
+	resp[2] |= 0x02 // TC
+	binary.BigEndian.PutUint16(resp[6:], 0)
+	binary.BigEndian.PutUint16(resp[8:], 0)
+	binary.BigEndian.PutUint16(resp[10:], 0)
+	return resp
+}
+
+// respond to a raw query. Returns nil if it's not worth responding to
+func (s *Server) Handle(msg []byte) []byte {
+	q, err := parseQuery(msg)
+	if err != nil {
+		if q == nil || q.flags&flagResponse != 0 {
+			return nil
+		}
+		logger.Debugf("Bad query: %v\n", err)
+		q.question = question{}
+		return buildResponse(q, rcodeFormErr, nil, nil, nil)
+	}
+	if q.flags&opcodeMask != 0 || q.question.qclass != classIN {
+		return buildResponse(q, rcodeNotImp, nil, nil, nil)
+	}
+	rcode, answers, authority, additional := s.answer(q.question)
+	logger.Debugf("%s %d: rcode %d, %d answers\n", q.question.name, q.question.qtype, rcode, len(answers))
+	return buildResponse(q, rcode, answers, authority, additional)
+}
+
+func (s *Server) answer(q question) (rcode int, answers, authority, additional []resourceRecord) {
+	name := q.name
+	for i := 0; i < maxCNAMEs; i++ {
+		rec, err := s.lookup(name)
+		if err != nil {
+			logger.Errorf("Error looking up %s: %v\n", name, err)
+			return rcodeServFail, nil, nil, nil
+		}
+		if rec == nil {
+			if len(answers) > 0 {
+				// dangling cname
+				return rcodeSuccess, answers, nil, nil
+			}
+			return s.delegation(name, q.qtype)
+		}
+
+		rtype := recordTypes[rec.Type]
+		switch {
+		case rtype == q.qtype:
+			rr, glue := s.records(name, rec)
+			return rcodeSuccess, append(answers, rr), nil, glue
+		case rtype == typeCNAME:
+			rr, _ := s.records(name, rec)
+			answers = append(answers, rr)
+			name = strings.ToLower(strings.TrimSuffix(rec.Address, "."))
+		case rtype == typeNS:
+			// the name is delegated to another server
+			rr, glue := s.records(name, rec)
+			return rcodeSuccess, answers, []resourceRecord{rr}, glue
+		default:
+			// the name exists but has no records of this type
+			return rcodeSuccess, answers, nil, nil
+		}
+	}
+	return rcodeSuccess, answers, nil, nil
+}
+
+// refer queries for names under a zone with an NS record to its name server.
+// We're authoritative for the name server's hostname if we made it up
+func (s *Server) delegation(name string, qtype uint16) (rcode int, answers, authority, additional []resourceRecord) {
+	labels := strings.Split(name, ".")
+	for i := 1; i < len(labels); i++ {
+		parent := strings.Join(labels[i:], ".")
+		rec, err := s.lookup(parent)
+		if err != nil {
+			logger.Errorf("Error looking up %s: %v\n", parent, err)
+			return rcodeServFail, nil, nil, nil
+		}
+		if rec != nil && rec.Type == "NS" {
+			rr, glue := s.records(parent, rec)
+			if len(glue) > 0 && glue[0].name == name {
+				if glue[0].rtype == qtype {
+					return rcodeSuccess, glue, nil, nil
+				}
+				return rcodeSuccess, nil, nil, nil
+			}
+			return rcodeSuccess, nil, []resourceRecord{rr}, glue
+		}
+	}
+	return rcodeNXDomain, nil, nil, nil
+}
+
+// the dns record stored for a name, or nil if there isn't a valid one
+func (s *Server) lookup(name string) (*core.DNSRecord, error) {
+	entry, err := s.cache.Get(name)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	rec := new(core.DNSRecord)
+	if err := json.Unmarshal([]byte(entry.Data), rec); err != nil {
+		return nil, nil
+	}
+	rec.Type = strings.ToUpper(rec.Type)
+	if err := rec.Validate(); err != nil {
+		logger.Debugf("Ignoring bad record for %s: %v\n", name, err)
+		return nil, nil
+	}
+	return rec, nil
+}
+
+// the resource record for a dns record, and any glue records it needs.
+// NS records may hold the name server's address instead of its hostname,
+// in which case we call it ns1.<name> and return its address as glue
+func (s *Server) records(name string, rec *core.DNSRecord) (resourceRecord, []resourceRecord) {
+	rr := resourceRecord{name: name, rtype: recordTypes[rec.Type], ttl: s.ttl}
+	switch rr.rtype {
+	case typeA:
+		rr.rdata = net.ParseIP(rec.Address).To4()
+	case typeAAAA:
+		rr.rdata = net.ParseIP(rec.Address).To16()
+	case typeCNAME:
+		rr.rdata = encodeName(rec.Address)
+	case typeTXT:
+		rr.rdata = encodeTXT(rec.Address)
+	case typeNS:
+		ip := net.ParseIP(rec.Address)
+		if ip == nil {
+			rr.rdata = encodeName(rec.Address)
+			return rr, nil
+		}
+		host := "ns1." + name
+		rr.rdata = encodeName(host)
+		glue := resourceRecord{name: host, rtype: typeA, ttl: s.ttl, rdata: ip.To4()}
+		if glue.rdata == nil {
+			glue.rtype, glue.rdata = typeAAAA, ip.To16()
+		}
+		return rr, []resourceRecord{glue}
+	}
+	return rr, nil
+}
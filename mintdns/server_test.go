@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eris-ltd/mint-client/fakenode"
+	"github.com/eris-ltd/mint-client/mintx/client"
+
+	ctypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core/types"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	rpctypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/wire"
+)
+
+// a json-rpc server with just enough of a node to serve names
+type fakeNode struct {
+	mtx      sync.Mutex
+	height   int
+	names    map[string]*types.NameRegEntry
+	getNames int // calls to get_name
+}
+
+func (f *fakeNode) set(name, typ, value string, expires int) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	data := fmt.Sprintf(`{"fqdn":%q,"address":%q,"type":%q}`, name, value, typ)
+	f.names[name] = &types.NameRegEntry{Name: name, Data: data, Expires: expires}
+}
+
+func (f *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	var req rpctypes.RPCRequest
+	json.NewDecoder(r.Body).Decode(&req)
+	resp := ctypes.Response{JSONRPC: "2.0", Id: req.Id}
+	switch req.Method {
+	case "status":
+		resp.Result = &ctypes.ResultStatus{LatestBlockHeight: f.height}
+	case "list_names":
+		var names []*types.NameRegEntry
+		for _, entry := range f.names {
+			names = append(names, entry)
+		}
+		resp.Result = &ctypes.ResultListNames{BlockHeight: f.height, Names: names}
+	case "get_name":
+		f.getNames++
+		name, _ := req.Params[0].(string)
+		if entry, ok := f.names[name]; ok {
+			resp.Result = &ctypes.ResultGetName{Entry: entry}
+		} else {
+			resp.Error = "Cannot find name"
+		}
+	default:
+		resp.Error = "unknown method " + req.Method
+	}
+	w.Write(wire.JSONBytes(resp))
+}
+
+func TestServer(t *testing.T) {
+	node := &fakeNode{height: 10, names: make(map[string]*types.NameRegEntry)}
+	node.set("magma.interblock.io", "A", "1.2.3.4", 100)
+	node.set("www.interblock.io", "CNAME", "magma.interblock.io", 100)
+	node.set("six.interblock.io", "AAAA", "2001:db8::1", 100)
+	node.set("txt.interblock.io", "TXT", "v=spf1 -all", 100)
+	node.set("newdomain.io", "NS", "4.3.2.1", 100)
+	node.set("old.interblock.io", "A", "5.6.7.8", 5)
+	rpc := httptest.NewServer(node)
+	defer rpc.Close()
+
+	cache := NewCache(cclient.NewClient(rpc.URL, REQUEST_TYPE))
+	if err := cache.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	go NewServer(cache, 60).ServeUDP(conn)
+
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return net.Dial("udp", conn.LocalAddr().String())
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	expectHosts := func(name string, expected ...string) {
+		addrs, err := resolver.LookupHost(ctx, name)
+		if err != nil {
+			t.Fatalf("error looking up %s: %v", name, err)
+		}
+		sort.Strings(addrs)
+		if fmt.Sprint(addrs) != fmt.Sprint(expected) {
+			t.Fatalf("expected %s to resolve to %v, got %v", name, expected, addrs)
+		}
+	}
+
+	expectHosts("magma.interblock.io", "1.2.3.4")
+	expectHosts("WWW.interblock.io.", "1.2.3.4")
+	expectHosts("six.interblock.io", "2001:db8::1")
+
+	if cname, err := resolver.LookupCNAME(ctx, "www.interblock.io"); err != nil || cname != "magma.interblock.io." {
+		t.Fatalf("bad cname %q: %v", cname, err)
+	}
+	if txts, err := resolver.LookupTXT(ctx, "txt.interblock.io"); err != nil || len(txts) != 1 || txts[0] != "v=spf1 -all" {
+		t.Fatalf("bad txt %v: %v", txts, err)
+	}
+	if ns, err := resolver.LookupNS(ctx, "newdomain.io"); err != nil || len(ns) != 1 || ns[0].Host != "ns1.newdomain.io." {
+		t.Fatalf("bad ns %v: %v", ns, err)
+	}
+	expectHosts("ns1.newdomain.io", "4.3.2.1")
+
+	for _, name := range []string{"old.interblock.io", "nothere.interblock.io"} {
+		if _, err := resolver.LookupHost(ctx, name); err == nil {
+			t.Fatalf("expected %s not to resolve", name)
+		} else if dnsErr, ok := err.(*net.DNSError); !ok || !dnsErr.IsNotFound {
+			t.Fatalf("expected %s to be not found, got %v", name, err)
+		}
+	}
+
+	// cached entries are only refetched on events
+	node.set("magma.interblock.io", "A", "4.4.4.4", 100)
+	expectHosts("magma.interblock.io", "1.2.3.4")
+	cache.HandleEvent(ctypes.ResultEvent{
+		Event: types.EventStringNameReg("magma.interblock.io"),
+		Data:  types.EventDataTx{Tx: &types.NameTx{Name: "magma.interblock.io"}},
+	})
+	expectHosts("magma.interblock.io", "4.4.4.4")
+
+	// and expire with the chain
+	node.mtx.Lock()
+	node.height = 100
+	node.mtx.Unlock()
+	cache.HandleEvent(ctypes.ResultEvent{
+		Event: types.EventStringNewBlock(),
+		Data:  types.EventDataNewBlock{Block: &types.Block{Header: &types.Header{Height: 100}}},
+	})
+	if _, err := resolver.LookupHost(ctx, "magma.interblock.io"); err == nil {
+		t.Fatal("expected magma.interblock.io to have expired")
+	}
+}
+
+func TestTCP(t *testing.T) {
+	node := &fakeNode{height: 10, names: make(map[string]*types.NameRegEntry)}
+	node.set("magma.interblock.io", "A", "1.2.3.4", 100)
+	rpc := httptest.NewServer(node)
+	defer rpc.Close()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go NewServer(NewCache(cclient.NewClient(rpc.URL, REQUEST_TYPE)), 60).ServeTCP(l)
+
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return net.Dial("tcp", l.Addr().String())
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addrs, err := resolver.LookupHost(ctx, "magma.interblock.io")
+	if err != nil || len(addrs) != 1 || addrs[0] != "1.2.3.4" {
+		t.Fatalf("bad lookup over tcp %v: %v", addrs, err)
+	}
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	// the default has no trailing slash
+	if addr := client.WebsocketAddr(DefaultNodeRPCAddr); addr != "ws://pinkpenguin.chaintest.net:46657/websocket" {
+		t.Fatalf("bad websocket address %s", addr)
+	}
+
+	node := fakenode.New(fakenode.Config{})
+	defer node.Close()
+	nodeAddr := strings.TrimSuffix(node.URL, "/")
+	cache := NewCache(cclient.NewClient(nodeAddr, REQUEST_TYPE))
+	down, err := subscribeEvents(cache, nodeAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// lookups subscribe concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cache.subscribe(fmt.Sprintf("name%d", i))
+		}(i)
+	}
+	wg.Wait()
+
+	select {
+	case <-down:
+		t.Fatal("expected the events to be up")
+	default:
+	}
+	node.Close()
+	select {
+	case <-down:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the events to go down with the node")
+	}
+
+	// and they're down if we can't connect
+	if down, err := subscribeEvents(cache, nodeAddr); err == nil {
+		t.Fatal("expected an error connecting to a closed node")
+	} else {
+		<-down
+	}
+}
+
+func TestCache(t *testing.T) {
+	node := &fakeNode{height: 10, names: make(map[string]*types.NameRegEntry)}
+	node.set("magma.interblock.io", "A", "1.2.3.4", 12)
+	rpc := httptest.NewServer(node)
+	defer rpc.Close()
+	cache := NewCache(cclient.NewClient(rpc.URL, REQUEST_TYPE))
+
+	setHeight := func(height int) {
+		node.mtx.Lock()
+		node.height = height
+		node.mtx.Unlock()
+		if err := cache.UpdateHeight(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect := func(name string, found bool, getNames int) {
+		entry, err := cache.Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (entry != nil) != found {
+			t.Fatalf("expected %s found=%v, got %v", name, found, entry)
+		}
+		node.mtx.Lock()
+		defer node.mtx.Unlock()
+		if node.getNames != getNames {
+			t.Fatalf("expected %d get_name calls, got %d", getNames, node.getNames)
+		}
+	}
+
+	// names that aren't there are only asked for once a block
+	expect("nothere.interblock.io", false, 1)
+	expect("nothere.interblock.io", false, 1)
+	node.set("nothere.interblock.io", "A", "5.6.7.8", 100)
+	expect("nothere.interblock.io", false, 1)
+	setHeight(11)
+	expect("nothere.interblock.io", true, 2)
+	expect("nothere.interblock.io", true, 2)
+
+	// without events, the polled height expires entries
+	expect("magma.interblock.io", true, 3)
+	setHeight(12)
+	expect("magma.interblock.io", false, 4)
+	expect("magma.interblock.io", false, 4)
+}
@@ -36,6 +36,27 @@ func cliKnown(cmd *cobra.Command, args []string) {
 	fmt.Println(string(genesisBytes))
 }
 
+func cliFromSpec(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		Exit(fmt.Errorf("Enter the path to a genesis spec"))
+	}
+	genesisBytes, err := coreFromSpec(args[0])
+	IfExit(err)
+	fmt.Println(string(genesisBytes))
+}
+
+func cliValidate(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		Exit(fmt.Errorf("Enter the path to a genesis.json"))
+	}
+	report, err := coreValidate(args[0])
+	IfExit(err)
+	printValidate(os.Stdout, report)
+	if len(report.Errors) > 0 {
+		Exit(fmt.Errorf("%s is not a valid genesis", args[0]))
+	}
+}
+
 func cliRandom(cmd *cobra.Command, args []string) {
 	if len(args) < 2 {
 		Exit(fmt.Errorf("Enter the number of validators and a chain_id"))
@@ -93,17 +114,7 @@ func coreKnown(chainID, csvFile, pubKeys string) ([]byte, error) {
 		genDoc = genesisFromPrivValBytes(chainID, privJSON)
 	}
 
-	buf, buf2, n := new(bytes.Buffer), new(bytes.Buffer), new(int64)
-	wire.WriteJSON(genDoc, buf, n, &err)
-	if err != nil {
-		return nil, err
-	}
-	if err := json.Indent(buf2, buf.Bytes(), "", "\t"); err != nil {
-		return nil, err
-	}
-	genesisBytes := buf2.Bytes()
-
-	return genesisBytes, nil
+	return genDocJSON(genDoc)
 }
 
 func coreRandom(N int, chainID string) (genesisBytes []byte, privVals []*types.PrivValidator, err error) {
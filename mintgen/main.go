@@ -40,6 +40,20 @@ func main() {
 		Run:   cliKnown,
 	}
 
+	var fromSpecCmd = &cobra.Command{
+		Use:   "from-spec",
+		Short: "mintgen from-spec <genesis.toml>",
+		Long:  "Create a genesis.json from a toml spec of the accounts, validators and global permissions",
+		Run:   cliFromSpec,
+	}
+
+	var validateCmd = &cobra.Command{
+		Use:   "validate",
+		Short: "mintgen validate <genesis.json>",
+		Long:  "Check a genesis.json for problems that would stop a node, and print its genesis state hash",
+		Run:   cliValidate,
+	}
+
 	randomCmd.Flags().StringVarP(&DirFlag, "dir", "d", "", "Directory to save genesis and priv_validators in. Default is ~/.eris/data/<chain_id>")
 
 	knownCmd.Flags().StringVarP(&PubkeyFlag, "pub", "", "", "pubkeys to include when generating genesis.json. flag is req'd")
@@ -51,6 +65,6 @@ func main() {
 		Short: "a tool for generating tendermint genesis files",
 		Long:  "a tool for generating tendermint genesis files",
 	}
-	rootCmd.AddCommand(randomCmd, knownCmd, versionCmd)
+	rootCmd.AddCommand(randomCmd, knownCmd, fromSpecCmd, validateCmd, versionCmd)
 	rootCmd.Execute()
 }
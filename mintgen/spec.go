@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/naoina/toml"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	stypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/state/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/wire"
+)
+
+//------------------------------------------------------------------------------------
+// genesis specs: a toml file declaring the accounts, validators and global permissions
+
+type GenesisSpec struct {
+	ChainID     string          `toml:"chain_id"`
+	GenesisTime time.Time       `toml:"genesis_time"` // defaults to now
+	Global      *SpecGlobal     `toml:"global"`
+	Accounts    []SpecAccount   `toml:"accounts"`
+	Validators  []SpecValidator `toml:"validators"`
+}
+
+// the permissions of accounts that don't set their own.
+// Perms that aren't listed keep their defaults
+type SpecGlobal struct {
+	Perms map[string]bool `toml:"perms"`
+	Roles []string        `toml:"roles"`
+}
+
+// one of Address or PubKey is needed.
+// Perms that aren't listed are unset, so they fall back to global
+type SpecAccount struct {
+	Address string          `toml:"address"`
+	PubKey  string          `toml:"pub_key"`
+	Amount  int64           `toml:"amount"`
+	Name    string          `toml:"name"`
+	Perms   map[string]bool `toml:"perms"`
+	Roles   []string        `toml:"roles"`
+}
+
+// UnbondTo defaults to the validator's own address.
+// A single entry without an amount gets the whole bond
+type SpecValidator struct {
+	PubKey   string       `toml:"pub_key"`
+	Amount   int64        `toml:"amount"`
+	Name     string       `toml:"name"`
+	UnbondTo []SpecUnbond `toml:"unbond_to"`
+}
+
+type SpecUnbond struct {
+	Address string `toml:"address"`
+	Amount  int64  `toml:"amount"`
+}
+
+func coreFromSpec(specFile string) ([]byte, error) {
+	b, err := ioutil.ReadFile(specFile)
+	if err != nil {
+		return nil, err
+	}
+	spec := new(GenesisSpec)
+	if err := toml.Unmarshal(b, spec); err != nil {
+		return nil, fmt.Errorf("Error parsing spec %s: %v", specFile, err)
+	}
+	genDoc, err := genDocFromSpec(spec)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid spec %s: %v", specFile, err)
+	}
+	return genDocJSON(genDoc)
+}
+
+func genDocFromSpec(spec *GenesisSpec) (*stypes.GenesisDoc, error) {
+	if spec.ChainID == "" {
+		return nil, fmt.Errorf("chain_id must be given")
+	}
+	if len(spec.Validators) == 0 {
+		return nil, fmt.Errorf("at least one validator must be given")
+	}
+	genDoc := &stypes.GenesisDoc{
+		GenesisTime: spec.GenesisTime,
+		ChainID:     spec.ChainID,
+	}
+	if genDoc.GenesisTime.IsZero() {
+		genDoc.GenesisTime = time.Now()
+	}
+
+	if spec.Global != nil {
+		perms := ptypes.DefaultAccountPermissions
+		perms.Roles = nil
+		if err := setSpecPerms(&perms, spec.Global.Perms, spec.Global.Roles); err != nil {
+			return nil, fmt.Errorf("global: %v", err)
+		}
+		// the chain needs every global permission set
+		perms.Base.SetBit = ptypes.AllPermFlags
+		genDoc.Params = &stypes.GenesisParams{GlobalPermissions: &perms}
+	}
+
+	seen := make(map[string]int)
+	for i, acc := range spec.Accounts {
+		addr, err := specAddress(acc.Address, acc.PubKey)
+		if err != nil {
+			return nil, fmt.Errorf("account %d: %v", i, err)
+		}
+		if j, ok := seen[string(addr)]; ok {
+			return nil, fmt.Errorf("accounts %d and %d have the same address %X", j, i, addr)
+		}
+		seen[string(addr)] = i
+		perms := ptypes.ZeroAccountPermissions
+		if err := setSpecPerms(&perms, acc.Perms, acc.Roles); err != nil {
+			return nil, fmt.Errorf("account %d: %v", i, err)
+		}
+		genDoc.Accounts = append(genDoc.Accounts, stypes.GenesisAccount{
+			Address:     addr,
+			Amount:      acc.Amount,
+			Name:        acc.Name,
+			Permissions: &perms,
+		})
+	}
+
+	seen = make(map[string]int)
+	for i, val := range spec.Validators {
+		pubKey, err := specPubKey(val.PubKey)
+		if err != nil {
+			return nil, fmt.Errorf("validator %d: %v", i, err)
+		}
+		if j, ok := seen[string(pubKey[:])]; ok {
+			return nil, fmt.Errorf("validators %d and %d have the same pub_key", j, i)
+		}
+		seen[string(pubKey[:])] = i
+		if val.Amount <= 0 {
+			return nil, fmt.Errorf("validator %d: amount must be positive", i)
+		}
+		unbondTo, err := specUnbondTo(pubKey, val.Amount, val.UnbondTo)
+		if err != nil {
+			return nil, fmt.Errorf("validator %d: %v", i, err)
+		}
+		genDoc.Validators = append(genDoc.Validators, stypes.GenesisValidator{
+			PubKey:   pubKey,
+			Amount:   val.Amount,
+			Name:     val.Name,
+			UnbondTo: unbondTo,
+		})
+	}
+	return genDoc, nil
+}
+
+// set the listed perms, and add the roles
+func setSpecPerms(perms *ptypes.AccountPermissions, m map[string]bool, roles []string) error {
+	for name, v := range m {
+		pf, err := ptypes.PermStringToFlag(name)
+		if err != nil {
+			return err
+		}
+		perms.Base.Set(pf, v)
+	}
+	for _, role := range roles {
+		if role == "" || len(role) > 32 {
+			return fmt.Errorf("role %q must be 1 to 32 bytes", role)
+		}
+		// pads the role to a word, as HasRole expects
+		if !perms.AddRole(role) {
+			return fmt.Errorf("role %s is listed twice", role)
+		}
+	}
+	return nil
+}
+
+func specAddress(addrS, pubKeyS string) ([]byte, error) {
+	var addr []byte
+	if addrS != "" {
+		var err error
+		if addr, err = hex.DecodeString(addrS); err != nil {
+			return nil, fmt.Errorf("address is bad hex: %v", err)
+		}
+		if len(addr) != 20 {
+			return nil, fmt.Errorf("address must be 20 bytes, got %d", len(addr))
+		}
+	}
+	if pubKeyS == "" {
+		if addr == nil {
+			return nil, fmt.Errorf("one of address or pub_key must be given")
+		}
+		return addr, nil
+	}
+	pubKey, err := specPubKey(pubKeyS)
+	if err != nil {
+		return nil, err
+	}
+	if addr != nil && !bytes.Equal(addr, pubKey.Address()) {
+		return nil, fmt.Errorf("address %X is not the address of pub_key %s", addr, pubKeyS)
+	}
+	return pubKey.Address(), nil
+}
+
+func specPubKey(pubKeyS string) (account.PubKeyEd25519, error) {
+	var pubKey account.PubKeyEd25519
+	b, err := hex.DecodeString(pubKeyS)
+	if err != nil {
+		return pubKey, fmt.Errorf("pub_key is bad hex: %v", err)
+	}
+	if len(b) != len(pubKey) {
+		return pubKey, fmt.Errorf("pub_key must be %d bytes, got %d", len(pubKey), len(b))
+	}
+	copy(pubKey[:], b)
+	return pubKey, nil
+}
+
+// where the bond goes when the validator unbonds. The amounts must add up to the bond
+func specUnbondTo(pubKey account.PubKeyEd25519, amount int64, specs []SpecUnbond) ([]stypes.BasicAccount, error) {
+	if len(specs) == 0 {
+		return []stypes.BasicAccount{{Address: pubKey.Address(), Amount: amount}}, nil
+	}
+	if len(specs) == 1 && specs[0].Amount == 0 {
+		specs = []SpecUnbond{{Address: specs[0].Address, Amount: amount}}
+	}
+	var unbondTo []stypes.BasicAccount
+	var total int64
+	for _, u := range specs {
+		addr, err := specAddress(u.Address, "")
+		if err != nil {
+			return nil, fmt.Errorf("unbond_to: %v", err)
+		}
+		if u.Amount <= 0 {
+			return nil, fmt.Errorf("unbond_to %X: amount must be positive", addr)
+		}
+		if u.Amount > amount-total {
+			return nil, fmt.Errorf("unbond_to amounts add up to more than the bond amount %d", amount)
+		}
+		total += u.Amount
+		unbondTo = append(unbondTo, stypes.BasicAccount{Address: addr, Amount: u.Amount})
+	}
+	if total != amount {
+		return nil, fmt.Errorf("unbond_to amounts add up to %d, not the bond amount %d", total, amount)
+	}
+	return unbondTo, nil
+}
+
+func genDocJSON(genDoc *stypes.GenesisDoc) ([]byte, error) {
+	var err error
+	buf, buf2, n := new(bytes.Buffer), new(bytes.Buffer), new(int64)
+	wire.WriteJSON(genDoc, buf, n, &err)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Indent(buf2, buf.Bytes(), "", "\t"); err != nil {
+		return nil, err
+	}
+	return buf2.Bytes(), nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	dbm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/db"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	sm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/state"
+)
+
+func TestFromSpec(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "mintgen-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	alice := account.GenPrivAccountFromSecret("alice")
+	bob := account.GenPrivAccountFromSecret("bob")
+	val := account.GenPrivAccountFromSecret("val")
+	pubHex := func(acc *account.PrivAccount) string {
+		pub := acc.PubKey.(account.PubKeyEd25519)
+		return fmt.Sprintf("%X", pub[:])
+	}
+	spec := fmt.Sprintf(`
+chain_id = "spec_chain"
+genesis_time = 2015-10-01T00:00:00Z
+
+[global.perms]
+create_contract = false
+
+[[accounts]]
+pub_key = "%s"
+amount = 1000
+name = "alice"
+roles = ["admin", "ops"]
+  [accounts.perms]
+  root = true
+  call = false
+
+[[accounts]]
+address = "%X"
+amount = 50
+
+[[validators]]
+pub_key = "%s"
+amount = 100
+name = "val0"
+  [[validators.unbond_to]]
+  address = "%X"
+  amount = 60
+  [[validators.unbond_to]]
+  address = "%X"
+  amount = 40
+
+[[validators]]
+pub_key = "%s"
+amount = 10
+`, pubHex(alice), bob.Address, pubHex(val), alice.Address, bob.Address, pubHex(alice))
+
+	specFile := path.Join(dir, "genesis.toml")
+	ioutil.WriteFile(specFile, []byte(spec), 0600)
+	genBytes, err := coreFromSpec(specFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	genFile := path.Join(dir, "genesis.json")
+	ioutil.WriteFile(genFile, genBytes, 0600)
+
+	gDoc, st := sm.MakeGenesisStateFromFile(dbm.NewMemDB(), genFile)
+	if gDoc.ChainID != "spec_chain" || gDoc.GenesisTime.Year() != 2015 {
+		t.Fatalf("bad genesis %s %v", gDoc.ChainID, gDoc.GenesisTime)
+	}
+	if len(gDoc.Validators) != 2 || len(gDoc.Validators[0].UnbondTo) != 2 || gDoc.Validators[0].UnbondTo[1].Amount != 40 {
+		t.Fatalf("bad validators %v", gDoc.Validators)
+	}
+	if u := gDoc.Validators[1].UnbondTo; len(u) != 1 || u[0].Amount != 10 || string(u[0].Address) != string(alice.Address) {
+		t.Fatalf("bad default unbond_to %v", u)
+	}
+
+	acc := st.GetAccount(alice.Address)
+	if acc == nil || acc.Balance != 1000 {
+		t.Fatalf("bad account %v", acc)
+	}
+	if !sm.HasPermission(st, acc, ptypes.Root) || sm.HasPermission(st, acc, ptypes.Call) || !sm.HasPermission(st, acc, ptypes.Send) {
+		t.Fatalf("bad perms %v", acc.Permissions)
+	}
+	if !acc.Permissions.HasRole("admin") || !acc.Permissions.HasRole("ops") {
+		t.Fatalf("bad roles %q", acc.Permissions.Roles)
+	}
+	// the rest of global keeps its defaults
+	bobAcc := st.GetAccount(bob.Address)
+	if sm.HasPermission(st, bobAcc, ptypes.CreateContract) || !sm.HasPermission(st, bobAcc, ptypes.Name) {
+		t.Fatalf("bad global perms %v", st.GetAccount(ptypes.GlobalPermissionsAddress).Permissions)
+	}
+
+	bad := map[string]string{
+		"chain_id":            "[[validators]]\npub_key = \"" + pubHex(val) + "\"\namount = 1\n",
+		"validator":           "chain_id = \"x\"\n",
+		"unbond_to amounts":   "chain_id = \"x\"\n[[validators]]\npub_key = \"" + pubHex(val) + "\"\namount = 10\n[[validators.unbond_to]]\naddress = \"" + fmt.Sprintf("%X", bob.Address) + "\"\namount = 9\n",
+		"Unknown permission":  "chain_id = \"x\"\n[[accounts]]\naddress = \"" + fmt.Sprintf("%X", bob.Address) + "\"\n[accounts.perms]\nsned = true\n[[validators]]\npub_key = \"" + pubHex(val) + "\"\namount = 1\n",
+		"same address":        "chain_id = \"x\"\n[[accounts]]\naddress = \"" + fmt.Sprintf("%X", bob.Address) + "\"\n[[accounts]]\naddress = \"" + fmt.Sprintf("%X", bob.Address) + "\"\n[[validators]]\npub_key = \"" + pubHex(val) + "\"\namount = 1\n",
+		"is not the address":  "chain_id = \"x\"\n[[accounts]]\naddress = \"" + fmt.Sprintf("%X", bob.Address) + "\"\npub_key = \"" + pubHex(alice) + "\"\n[[validators]]\npub_key = \"" + pubHex(val) + "\"\namount = 1\n",
+		"pub_key must be":     "chain_id = \"x\"\n[[validators]]\npub_key = \"0102\"\namount = 1\n",
+		"not defined":         "chain_id = \"x\"\nchainid = \"y\"\n",
+		"amount must be":      "chain_id = \"x\"\n[[validators]]\npub_key = \"" + pubHex(val) + "\"\n",
+		"role \"\" must be 1": "chain_id = \"x\"\n[[accounts]]\naddress = \"" + fmt.Sprintf("%X", bob.Address) + "\"\nroles = [\"\"]\n[[validators]]\npub_key = \"" + pubHex(val) + "\"\namount = 1\n",
+	}
+	for expected, spec := range bad {
+		ioutil.WriteFile(specFile, []byte(spec), 0600)
+		_, err := coreFromSpec(specFile)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected error containing %q for\n%s\ngot %v", expected, spec, err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	dbm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/db"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	sm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/state"
+	stypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/state/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/wire"
+)
+
+//------------------------------------------------------------------------------------
+// linting a genesis.json before a node panics on it at startup
+
+// chain ids end up in paths like ~/.eris/data/<chain_id>
+var chainIDRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Errors break the chain or a node's startup.
+// Warnings are for genesis files that work but probably aren't what was meant
+type GenesisReport struct {
+	ChainID   string
+	Errors    []string
+	Warnings  []string
+	StateHash []byte // nil if the state couldn't be made
+}
+
+// errors are for files that can't be read or parsed.
+// Anything else wrong with the genesis goes in the report
+func coreValidate(genFile string) (*GenesisReport, error) {
+	b, err := ioutil.ReadFile(genFile)
+	if err != nil {
+		return nil, err
+	}
+	var genDoc *stypes.GenesisDoc
+	wire.ReadJSONPtr(&genDoc, b, &err)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing genesis %s: %v", genFile, err)
+	}
+	if genDoc == nil {
+		return nil, fmt.Errorf("Genesis %s is empty", genFile)
+	}
+
+	report := &GenesisReport{ChainID: genDoc.ChainID}
+	report.Errors, report.Warnings = lintGenDoc(genDoc)
+	// the state is only made when the node won't exit on it
+	if len(genDoc.Validators) == 0 {
+		return report, nil
+	}
+	st, err := makeGenesisState(genFile)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return report, nil
+	}
+	report.StateHash = st.Hash()
+	return report, nil
+}
+
+func makeGenesisState(genFile string) (st *sm.State, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("making the genesis state panicked: %v", r)
+		}
+	}()
+	_, st = sm.MakeGenesisStateFromFile(dbm.NewMemDB(), genFile)
+	return st, nil
+}
+
+func lintGenDoc(genDoc *stypes.GenesisDoc) (errs, warnings []string) {
+	errorf := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+	warn := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	switch {
+	case genDoc.ChainID == "":
+		errorf("chain_id is empty")
+	case !chainIDRegexp.MatchString(genDoc.ChainID):
+		errorf("chain_id %q should only have letters, digits, _, . and -", genDoc.ChainID)
+	}
+
+	if genDoc.Params == nil || genDoc.Params.GlobalPermissions == nil {
+		warn("params.global_permissions is missing, so the chain uses the defaults")
+	} else {
+		global := genDoc.Params.GlobalPermissions
+		for _, p := range lintPerms(*global) {
+			errorf("global: %s", p)
+		}
+		if global.Base.SetBit != ptypes.AllPermFlags {
+			// the chain sets them all, so the ones that aren't set become false
+			warn("global: SetBit %d is not every permission (%d); the unset ones will be false", global.Base.SetBit, ptypes.AllPermFlags)
+		}
+	}
+
+	funded := make(map[string]bool)
+	seen := make(map[string]int)
+	var total int64
+	for i, acc := range genDoc.Accounts {
+		if len(acc.Address) != 20 {
+			errorf("accounts[%d]: address must be 20 bytes, got %d", i, len(acc.Address))
+		}
+		if j, ok := seen[string(acc.Address)]; ok {
+			errorf("accounts[%d]: same address %X as accounts[%d]", i, acc.Address, j)
+		}
+		seen[string(acc.Address)] = i
+		switch {
+		case acc.Amount < 0:
+			errorf("accounts[%d]: amount %d is negative", i, acc.Amount)
+		case acc.Amount == 0:
+			warn("accounts[%d]: amount is zero", i)
+		case acc.Amount > math.MaxInt64-total:
+			errorf("accounts[%d]: amounts overflow", i)
+		default:
+			total += acc.Amount
+			funded[string(acc.Address)] = true
+		}
+		if acc.Permissions != nil {
+			for _, p := range lintPerms(*acc.Permissions) {
+				errorf("accounts[%d]: %s", i, p)
+			}
+		}
+	}
+
+	if len(genDoc.Validators) == 0 {
+		errorf("there are no validators")
+	}
+	seen = make(map[string]int)
+	for i, val := range genDoc.Validators {
+		if val.PubKey == (account.PubKeyEd25519{}) {
+			errorf("validators[%d]: pub_key is empty", i)
+		}
+		addr := val.PubKey.Address()
+		if j, ok := seen[string(addr)]; ok {
+			errorf("validators[%d]: same pub_key as validators[%d]", i, j)
+		}
+		seen[string(addr)] = i
+		if !funded[string(addr)] {
+			warn("validators[%d]: address %X has no funded account", i, addr)
+		}
+		if val.Amount <= 0 {
+			errorf("validators[%d]: amount %d must be positive", i, val.Amount)
+		}
+		var unbond int64
+		overflow := false
+		for j, u := range val.UnbondTo {
+			if len(u.Address) != 20 {
+				errorf("validators[%d].unbond_to[%d]: address must be 20 bytes, got %d", i, j, len(u.Address))
+			}
+			if u.Amount <= 0 {
+				errorf("validators[%d].unbond_to[%d]: amount %d must be positive", i, j, u.Amount)
+			} else if u.Amount > math.MaxInt64-unbond {
+				overflow = true
+			} else {
+				unbond += u.Amount
+			}
+		}
+		switch {
+		case len(val.UnbondTo) == 0:
+			warn("validators[%d]: unbond_to is empty, so the bond is lost on unbonding", i)
+		case overflow:
+			errorf("validators[%d]: unbond_to amounts overflow", i)
+		case unbond != val.Amount:
+			errorf("validators[%d]: unbond_to amounts add up to %d, not the bond amount %d", i, unbond, val.Amount)
+		}
+	}
+	return errs, warnings
+}
+
+func lintPerms(perms ptypes.AccountPermissions) []string {
+	var problems []string
+	if perms.Base.SetBit > ptypes.AllPermFlags {
+		problems = append(problems, fmt.Sprintf("SetBit %d has bits above the top permission (%d)", perms.Base.SetBit, ptypes.TopPermFlag))
+	}
+	if extra := perms.Base.Perms &^ perms.Base.SetBit; extra != 0 {
+		problems = append(problems, fmt.Sprintf("PermFlag %d has bits that aren't set in SetBit %d: %b", perms.Base.Perms, perms.Base.SetBit, extra))
+	}
+	seen := make(map[string]bool)
+	for _, role := range perms.Roles {
+		name := strings.TrimLeft(role, "\x00")
+		switch {
+		case name == "":
+			problems = append(problems, "a role is empty")
+		case len(role) > 32:
+			problems = append(problems, fmt.Sprintf("role %q is over 32 bytes", name))
+		case len(role) < 32:
+			// HasRole pads what it looks for, so it never finds these
+			problems = append(problems, fmt.Sprintf("role %q is not left padded with zeros to 32 bytes, so it never matches", name))
+		}
+		if seen[name] {
+			problems = append(problems, fmt.Sprintf("role %q is listed twice", name))
+		}
+		seen[name] = true
+	}
+	return problems
+}
+
+func printValidate(out io.Writer, report *GenesisReport) {
+	fmt.Fprintf(out, "Chain ID: %s\n", report.ChainID)
+	if report.StateHash != nil {
+		fmt.Fprintf(out, "State hash: %X\n", report.StateHash)
+	}
+	if len(report.Errors) == 0 && len(report.Warnings) == 0 {
+		fmt.Fprintln(out, "No problems found")
+		return
+	}
+	for _, e := range report.Errors {
+		fmt.Fprintf(out, "error: %s\n", e)
+	}
+	for _, w := range report.Warnings {
+		fmt.Fprintf(out, "warning: %s\n", w)
+	}
+}
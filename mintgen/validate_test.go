@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	dbm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/db"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	sm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/state"
+	stypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/state/types"
+)
+
+func TestValidate(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "mintgen-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	genFile := path.Join(dir, "genesis.json")
+
+	val := account.GenPrivAccountFromSecret("val")
+	other := account.GenPrivAccountFromSecret("other")
+	pubKey := val.PubKey.(account.PubKeyEd25519)
+	newGenDoc := func() *stypes.GenesisDoc {
+		global := ptypes.DefaultAccountPermissions
+		global.Roles = nil
+		global.Base.SetBit = ptypes.AllPermFlags
+		genDoc := &stypes.GenesisDoc{
+			GenesisTime: time.Date(2015, 10, 1, 0, 0, 0, 0, time.UTC),
+			ChainID:     "lint_chain",
+			Params:      &stypes.GenesisParams{GlobalPermissions: &global},
+		}
+		perms := ptypes.ZeroAccountPermissions
+		perms.AddRole("ops")
+		genDoc.Accounts = []stypes.GenesisAccount{
+			{Address: val.Address, Amount: 100, Permissions: &perms},
+			{Address: other.Address, Amount: 50},
+		}
+		genDoc.Validators = []stypes.GenesisValidator{{
+			PubKey:   pubKey,
+			Amount:   10,
+			UnbondTo: []stypes.BasicAccount{{Address: val.Address, Amount: 10}},
+		}}
+		return genDoc
+	}
+	validate := func(genDoc *stypes.GenesisDoc) *GenesisReport {
+		b, err := genDocJSON(genDoc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.WriteFile(genFile, b, 0600)
+		report, err := coreValidate(genFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+
+	report := validate(newGenDoc())
+	if len(report.Errors) != 0 || len(report.Warnings) != 0 {
+		t.Fatalf("expected no problems, got %v %v", report.Errors, report.Warnings)
+	}
+	_, st := sm.MakeGenesisStateFromFile(dbm.NewMemDB(), genFile)
+	if !bytes.Equal(report.StateHash, st.Hash()) {
+		t.Fatalf("state hash %X, expected %X", report.StateHash, st.Hash())
+	}
+
+	bad := map[string]func(*stypes.GenesisDoc){
+		"chain_id \"my chain\"": func(g *stypes.GenesisDoc) { g.ChainID = "my chain" },
+		"same address":          func(g *stypes.GenesisDoc) { g.Accounts[1].Address = val.Address },
+		"amounts overflow": func(g *stypes.GenesisDoc) {
+			// json amounts are floats, so keep them exact
+			g.Accounts[1].Amount = 1 << 62
+			g.Accounts = append(g.Accounts, stypes.GenesisAccount{Address: bytes.Repeat([]byte{1}, 20), Amount: 1 << 62})
+		},
+		"is negative": func(g *stypes.GenesisDoc) { g.Accounts[1].Amount = -1 },
+		"aren't set in SetBit": func(g *stypes.GenesisDoc) {
+			g.Accounts[0].Permissions.Base = ptypes.BasePermissions{Perms: ptypes.Send | ptypes.Call, SetBit: ptypes.Send}
+		},
+		"not left padded":    func(g *stypes.GenesisDoc) { g.Accounts[0].Permissions.Roles = []string{"ops"} },
+		"not the bond":       func(g *stypes.GenesisDoc) { g.Validators[0].UnbondTo[0].Amount = 9 },
+		"must be positive":   func(g *stypes.GenesisDoc) { g.Validators[0].Amount = 0 },
+		"no validators":      func(g *stypes.GenesisDoc) { g.Validators = nil },
+		"same pub_key":       func(g *stypes.GenesisDoc) { g.Validators = append(g.Validators, g.Validators[0]) },
+		"above the top perm": func(g *stypes.GenesisDoc) { g.Params.GlobalPermissions.Base.SetBit = ptypes.AllPermFlags + 1 },
+		// AddRole won't add a role twice
+		"listed twice": func(g *stypes.GenesisDoc) {
+			g.Accounts[0].Permissions.Roles = append(g.Accounts[0].Permissions.Roles, g.Accounts[0].Permissions.Roles[0])
+		},
+	}
+	for expected, mutate := range bad {
+		genDoc := newGenDoc()
+		mutate(genDoc)
+		report := validate(genDoc)
+		if !containsString(report.Errors, expected) {
+			t.Fatalf("expected an error containing %q, got %v", expected, report.Errors)
+		}
+	}
+
+	warnings := map[string]func(*stypes.GenesisDoc){
+		"global_permissions is missing": func(g *stypes.GenesisDoc) { g.Params = nil },
+		"no funded account":             func(g *stypes.GenesisDoc) { g.Accounts = g.Accounts[1:] },
+		"amount is zero":                func(g *stypes.GenesisDoc) { g.Accounts[1].Amount = 0 },
+	}
+	for expected, mutate := range warnings {
+		genDoc := newGenDoc()
+		mutate(genDoc)
+		report := validate(genDoc)
+		if len(report.Errors) != 0 || !containsString(report.Warnings, expected) {
+			t.Fatalf("expected only a warning containing %q, got %v %v", expected, report.Errors, report.Warnings)
+		}
+		if report.StateHash == nil {
+			t.Fatal("expected a state hash")
+		}
+	}
+
+	ioutil.WriteFile(genFile, []byte(`{"chain_id": `), 0600)
+	if _, err := coreValidate(genFile); err == nil {
+		t.Fatal("expected an error for bad json")
+	}
+}
+
+func containsString(strs []string, sub string) bool {
+	for _, s := range strs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
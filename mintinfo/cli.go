@@ -5,9 +5,12 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"os"
 	"reflect"
 	"strconv"
 
+	"github.com/eris-ltd/mint-client/mintx/core"
+
 	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/codegangsta/cli"
 	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
 	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/wire"
@@ -134,10 +137,43 @@ func cliAccounts(c *cli.Context) {
 }
 
 func cliNames(c *cli.Context) {
+	if domain := c.String("export-zone"); domain != "" {
+		r, err := client.ListNames()
+		ifExit(err)
+		ifExit(core.WriteZone(os.Stdout, domain, r.BlockHeight, r.Names))
+		return
+	}
+
+	filter, err := newNameFilter(c)
+	ifExit(err)
+
 	args := c.Args()
-	if len(args) == 0 {
+	if c.String("schema") != "" {
+		schema, err := core.LoadSchema(c.String("schema"))
+		ifExit(err)
+		var entries []*types.NameRegEntry
+		if len(args) > 0 && filter == nil {
+			r, err := client.GetName(args[0])
+			ifExit(err)
+			entries = append(entries, r.Entry)
+		} else {
+			r, err := client.ListNames()
+			ifExit(err)
+			entries = r.Names
+			if filter != nil {
+				entries = filter.filter(entries, r.BlockHeight)
+			}
+		}
+		printNameData(os.Stdout, entries, schema)
+		return
+	}
+
+	if len(args) == 0 || filter != nil {
 		r, err := client.ListNames()
 		ifExit(err)
+		if filter != nil {
+			r.Names = filter.filter(r.Names, r.BlockHeight)
+		}
 		s, err := formatOutput(c, 1, r)
 		ifExit(err)
 		fmt.Println(s)
@@ -189,6 +225,10 @@ func cliBlocks(c *cli.Context) {
 }
 
 func cliStorage(c *cli.Context) {
+	if c.String("layout") != "" || c.String("diff") != "" {
+		cliStorageLayout(c)
+		return
+	}
 	args := c.Args()
 	if len(args) == 0 {
 		exit(fmt.Errorf("must specify an address to dump all storage, and an optional key to get just that storage"))
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/codegangsta/cli"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/vm"
+)
+
+//------------------------------------------------------------------------------
+// contract code disassembly
+
+type Instruction struct {
+	PC   int       `json:"pc"`
+	Op   vm.OpCode `json:"op"`
+	Name string    `json:"name"`
+	Push []byte    `json:"push"`
+}
+
+// the destination of a PUSH immediately followed by a JUMP or JUMPI
+type Jump struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// an entry in the solidity function selector dispatch table
+type Selector struct {
+	Selector []byte `json:"selector"`
+	Dest     int    `json:"dest"`
+}
+
+type Disassembly struct {
+	Instructions []Instruction `json:"instructions"`
+	JumpDests    []int         `json:"jump_dests"`
+	Jumps        []Jump        `json:"jumps"`
+	Selectors    []Selector    `json:"selectors"`
+}
+
+func cliCode(c *cli.Context) {
+	args := c.Args()
+	if len(args) == 0 {
+		exit(fmt.Errorf("must specify the address of a contract"))
+	}
+	addr := args[0]
+	addrBytes, err := hex.DecodeString(addr)
+	if err != nil {
+		exit(fmt.Errorf("Addr %s is improper hex: %v", addr, err))
+	}
+	acc, err := getAccount(addrBytes)
+	ifExit(err)
+	if acc == nil {
+		exit(fmt.Errorf("Account %X does not exist", addrBytes))
+	}
+	if len(acc.Code) == 0 {
+		exit(fmt.Errorf("Account %X has no code", addrBytes))
+	}
+
+	if binFile := c.String("compare"); binFile != "" {
+		b, err := ioutil.ReadFile(binFile)
+		ifExit(err)
+		local, err := decodeBin(b)
+		ifExit(err)
+		if offset := compareCode(acc.Code, local); offset >= 0 {
+			exit(fmt.Errorf("Code does not match at offset 0x%x (on chain %d bytes, local %d bytes)\non chain: %s\nlocal:    %s",
+				offset, len(acc.Code), len(local), instructionAt(acc.Code, offset), instructionAt(local, offset)))
+		}
+		fmt.Printf("Code at %X matches %s (%d bytes)\n", addrBytes, binFile, len(local))
+		return
+	}
+
+	d := disassemble(acc.Code)
+	if c.Bool("json") {
+		s, err := prettyPrint(d)
+		ifExit(err)
+		fmt.Println(s)
+		return
+	}
+	printDisassembly(d)
+}
+
+// decode a hex .bin file as output by solc
+func decodeBin(b []byte) ([]byte, error) {
+	s := strings.TrimSpace(string(b))
+	s = strings.TrimPrefix(s, "0x")
+	code, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("bin file is improper hex: %v", err)
+	}
+	return code, nil
+}
+
+func isPush(op vm.OpCode) bool {
+	return op >= vm.PUSH1 && op <= vm.PUSH32
+}
+
+func disassemble(code []byte) *Disassembly {
+	d := new(Disassembly)
+	for pc := 0; pc < len(code); pc++ {
+		op := vm.OpCode(code[pc])
+		ins := Instruction{PC: pc, Op: op, Name: op.String()}
+		if isPush(ins.Op) {
+			end := pc + 1 + int(ins.Op-vm.PUSH1) + 1
+			if end > len(code) {
+				// truncated push data is padded with zeros by the vm
+				end = len(code)
+			}
+			ins.Push = code[pc+1 : end]
+			pc = end - 1
+		}
+		if ins.Op == vm.JUMPDEST {
+			d.JumpDests = append(d.JumpDests, ins.PC)
+		}
+		d.Instructions = append(d.Instructions, ins)
+	}
+
+	ins := d.Instructions
+	for i := 1; i < len(ins); i++ {
+		if (ins[i].Op == vm.JUMP || ins[i].Op == vm.JUMPI) && isPush(ins[i-1].Op) {
+			d.Jumps = append(d.Jumps, Jump{From: ins[i].PC, To: pushInt(ins[i-1].Push)})
+		}
+	}
+	d.Selectors = findSelectors(ins)
+	return d
+}
+
+// solc dispatches on the first four bytes of the call data with runs like
+// DUP1 PUSH4 <selector> EQ PUSH2 <dest> JUMPI
+// (or PUSH4 <selector> DUP2 EQ ...)
+func findSelectors(ins []Instruction) (selectors []Selector) {
+	for i := range ins {
+		if ins[i].Op != vm.PUSH4 {
+			continue
+		}
+		for j := i + 1; j < len(ins)-2 && j <= i+2; j++ {
+			if ins[j].Op == vm.EQ && isPush(ins[j+1].Op) && ins[j+2].Op == vm.JUMPI {
+				selectors = append(selectors, Selector{Selector: ins[i].Push, Dest: pushInt(ins[j+1].Push)})
+				break
+			}
+		}
+	}
+	return
+}
+
+func pushInt(b []byte) int {
+	return int(new(big.Int).SetBytes(b).Int64())
+}
+
+func printDisassembly(d *Disassembly) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	if len(d.Selectors) > 0 {
+		fmt.Fprintln(w, "Function selectors:")
+		for _, s := range d.Selectors {
+			fmt.Fprintf(w, "  0x%X\t-> 0x%04x\n", s.Selector, s.Dest)
+		}
+		fmt.Fprintln(w)
+	}
+
+	jumps := make(map[int]int)
+	for _, j := range d.Jumps {
+		jumps[j.From] = j.To
+	}
+	for _, ins := range d.Instructions {
+		if ins.Op == vm.JUMPDEST {
+			fmt.Fprintf(w, "\nloc_%04x:\n", ins.PC)
+		}
+		fmt.Fprintf(w, "%04x\t%s", ins.PC, ins.Op)
+		if ins.Push != nil {
+			fmt.Fprintf(w, "\t0x%X", ins.Push)
+		}
+		if to, ok := jumps[ins.PC]; ok {
+			fmt.Fprintf(w, "\t-> loc_%04x", to)
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+}
+
+// the offset of the first difference between the two codes, or -1 if they're equal
+func compareCode(onChain, local []byte) int {
+	if bytes.Equal(onChain, local) {
+		return -1
+	}
+	for i := 0; i < len(onChain) && i < len(local); i++ {
+		if onChain[i] != local[i] {
+			return i
+		}
+	}
+	if len(onChain) < len(local) {
+		return len(onChain)
+	}
+	return len(local)
+}
+
+// the instruction covering the given offset
+func instructionAt(code []byte, offset int) string {
+	for _, ins := range disassemble(code).Instructions {
+		if offset >= ins.PC && offset <= ins.PC+len(ins.Push) {
+			if ins.Push != nil {
+				return fmt.Sprintf("%04x %s 0x%X", ins.PC, ins.Op, ins.Push)
+			}
+			return fmt.Sprintf("%04x %s", ins.PC, ins.Op)
+		}
+	}
+	return "<end of code>"
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+// dispatch to a single function, the way solc does it
+var testCode = "6000" + // PUSH1 0x00
+	"35" + // CALLDATALOAD
+	"60e0" + // PUSH1 0xe0
+	"6002" + // PUSH1 0x02
+	"0a" + // EXP
+	"90" + // SWAP1
+	"04" + // DIV
+	"80" + // DUP1
+	"6360fe47b1" + // PUSH4 0x60fe47b1
+	"14" + // EQ
+	"6015" + // PUSH1 0x15
+	"57" + // JUMPI
+	"00" + // STOP
+	"5b" + // JUMPDEST
+	"00" // STOP
+
+func TestDisassemble(t *testing.T) {
+	code, _ := hex.DecodeString(testCode)
+	d := disassemble(code)
+
+	if d.Instructions[0].Name != "PUSH1" || fmt.Sprintf("%X", d.Instructions[0].Push) != "00" {
+		t.Fatalf("bad first instruction %v", d.Instructions[0])
+	}
+	if len(d.Selectors) != 1 || fmt.Sprintf("%X", d.Selectors[0].Selector) != "60FE47B1" || d.Selectors[0].Dest != 0x15 {
+		t.Fatalf("bad selectors %v", d.Selectors)
+	}
+	if fmt.Sprint(d.JumpDests) != "[21]" {
+		t.Fatalf("bad jump dests %v", d.JumpDests)
+	}
+	if len(d.Jumps) != 1 || d.Jumps[0].From != 0x13 || d.Jumps[0].To != 0x15 {
+		t.Fatalf("bad jumps %v", d.Jumps)
+	}
+
+	// truncated push data
+	d = disassemble([]byte{0x61, 0x01})
+	if len(d.Instructions) != 1 || len(d.Instructions[0].Push) != 1 {
+		t.Fatalf("bad truncated push %v", d.Instructions)
+	}
+}
+
+func TestCompareCode(t *testing.T) {
+	code, _ := hex.DecodeString(testCode)
+	if i := compareCode(code, code); i != -1 {
+		t.Fatalf("expected equal code, got offset %d", i)
+	}
+	if i := compareCode(code, code[:10]); i != 10 {
+		t.Fatalf("expected difference at 10, got %d", i)
+	}
+	local, err := decodeBin([]byte("0x" + testCode + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	local[13] ^= 0xff
+	if i := compareCode(code, local); i != 13 {
+		t.Fatalf("expected difference at 13, got %d", i)
+	}
+	if s := instructionAt(code, 13); s != "000b PUSH4 0x60FE47B1" {
+		t.Fatalf("bad instruction at 13: %s", s)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/codegangsta/cli"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------
+// prometheus exporter
+
+// Exporter serves chain and node metrics in the prometheus text format.
+// The node is queried on every scrape.
+type Exporter struct {
+	client  cclient.Client
+	watched [][]byte
+}
+
+func NewExporter(client cclient.Client, watched [][]byte) *Exporter {
+	return &Exporter{client: client, watched: watched}
+}
+
+func cliExporter(c *cli.Context) {
+	var watched [][]byte
+	if w := c.String("watch"); w != "" {
+		for _, addr := range strings.Split(w, ",") {
+			addrBytes, err := hex.DecodeString(addr)
+			if err != nil {
+				exit(fmt.Errorf("Addr %s is improper hex: %v", addr, err))
+			}
+			watched = append(watched, addrBytes)
+		}
+	}
+
+	listen := c.String("listen")
+	http.Handle("/metrics", NewExporter(client, watched))
+	fmt.Printf("Serving metrics on %s/metrics\n", listen)
+	ifExit(http.ListenAndServe(listen, nil))
+}
+
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	buf := new(bytes.Buffer)
+	up := 1
+	if err := e.collect(buf, time.Now()); err != nil {
+		fmt.Fprintf(buf, "# error: %v\n", err)
+		up = 0
+	}
+	writeMetric(buf, "mint_up", "gauge", "Whether the last scrape of the node succeeded", "", up)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(buf.Bytes())
+}
+
+func (e *Exporter) collect(buf *bytes.Buffer, now time.Time) error {
+	status, err := e.client.Status()
+	if err != nil {
+		return err
+	}
+	blockTime := time.Unix(0, status.LatestBlockTime)
+	writeMetric(buf, "mint_latest_block_height", "gauge", "Height of the latest block", "", status.LatestBlockHeight)
+	writeMetric(buf, "mint_latest_block_time_seconds", "gauge", "Unix time of the latest block", "", blockTime.Unix())
+	writeMetric(buf, "mint_seconds_since_last_block", "gauge", "Seconds since the latest block", "", now.Sub(blockTime).Seconds())
+
+	netInfo, err := e.client.NetInfo()
+	if err != nil {
+		return err
+	}
+	writeMetric(buf, "mint_peers", "gauge", "Number of connected peers", "", len(netInfo.Peers))
+
+	txs, err := e.client.ListUnconfirmedTxs()
+	if err != nil {
+		return err
+	}
+	writeMetric(buf, "mint_mempool_txs", "gauge", "Number of txs in the mempool", "", len(txs.Txs))
+
+	vals, err := e.client.ListValidators()
+	if err != nil {
+		return err
+	}
+	writeHeader(buf, "mint_validators", "gauge", "Number of validators")
+	writeSample(buf, "mint_validators", `state="bonded"`, len(vals.BondedValidators))
+	writeSample(buf, "mint_validators", `state="unbonding"`, len(vals.UnbondingValidators))
+	writeHeader(buf, "mint_voting_power", "gauge", "Total voting power of the validators")
+	writeSample(buf, "mint_voting_power", `state="bonded"`, votingPower(vals.BondedValidators))
+	writeSample(buf, "mint_voting_power", `state="unbonding"`, votingPower(vals.UnbondingValidators))
+
+	cs, err := e.client.DumpConsensusState()
+	if err != nil {
+		return err
+	}
+	rs := parseRoundState(cs.RoundState)
+	writeMetric(buf, "mint_consensus_height", "gauge", "Height the node is trying to commit", "", rs.Height)
+	writeMetric(buf, "mint_consensus_round", "gauge", "Consensus round at the current height", "", rs.Round)
+
+	if len(e.watched) > 0 {
+		writeHeader(buf, "mint_account_balance", "gauge", "Balance of a watched account")
+		for _, addr := range e.watched {
+			r, err := e.client.GetAccount(addr)
+			if err != nil {
+				return err
+			}
+			var balance int64
+			if r != nil && r.Account != nil {
+				balance = r.Account.Balance
+			}
+			writeSample(buf, "mint_account_balance", fmt.Sprintf(`address="%X"`, addr), balance)
+		}
+	}
+	return nil
+}
+
+func votingPower(vals []*types.Validator) (power int64) {
+	for _, v := range vals {
+		power += v.VotingPower
+	}
+	return
+}
+
+func writeMetric(buf *bytes.Buffer, name, typ, help, labels string, value interface{}) {
+	writeHeader(buf, name, typ, help)
+	writeSample(buf, name, labels, value)
+}
+
+func writeHeader(buf *bytes.Buffer, name, typ, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func writeSample(buf *bytes.Buffer, name, labels string, value interface{}) {
+	if labels != "" {
+		name = name + "{" + labels + "}"
+	}
+	fmt.Fprintf(buf, "%s %v\n", name, value)
+}
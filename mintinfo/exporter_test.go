@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	ctypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+func (f *fakeNode) ListValidators() (*ctypes.ResultListValidators, error) {
+	return &ctypes.ResultListValidators{
+		BondedValidators:    []*types.Validator{{VotingPower: 10}, {VotingPower: 5}},
+		UnbondingValidators: []*types.Validator{{VotingPower: 1}},
+	}, nil
+}
+
+func (f *fakeNode) GetAccount(addr []byte) (*ctypes.ResultGetAccount, error) {
+	if !bytes.Equal(addr, []byte{0xAB}) {
+		return &ctypes.ResultGetAccount{}, nil
+	}
+	return &ctypes.ResultGetAccount{Account: &acm.Account{Address: addr, Balance: 42}}, nil
+}
+
+func TestExporter(t *testing.T) {
+	node := &fakeNode{height: 7, peers: 3, mempool: 2}
+	w := httptest.NewRecorder()
+	NewExporter(node, [][]byte{{0xAB}, {0xCD}}).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := w.Body.String()
+	for _, line := range []string{
+		"mint_latest_block_height 7",
+		"mint_peers 3",
+		"mint_mempool_txs 2",
+		`mint_validators{state="bonded"} 2`,
+		`mint_voting_power{state="bonded"} 15`,
+		`mint_voting_power{state="unbonding"} 1`,
+		"mint_consensus_height 8",
+		"mint_consensus_round 0",
+		`mint_account_balance{address="AB"} 42`,
+		`mint_account_balance{address="CD"} 0`,
+		"mint_up 1",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("expected metric %q in:\n%s", line, body)
+		}
+	}
+
+	node.down = true
+	w = httptest.NewRecorder()
+	NewExporter(node, nil).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.Contains(w.Body.String(), "mint_up 0\n") {
+		t.Fatalf("expected mint_up 0 for a down node, got:\n%s", w.Body.String())
+	}
+}
@@ -0,0 +1,490 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/codegangsta/cli"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/common"
+	ctypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/vm/sha3"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/wire"
+)
+
+//------------------------------------------------------------------------------
+// typed storage using solc's storage layout
+
+// as output by solc --storage-layout
+type StorageLayout struct {
+	Storage []StorageVar            `json:"storage"`
+	Types   map[string]*StorageType `json:"types"`
+}
+
+type StorageVar struct {
+	Label  string `json:"label"`
+	Offset int    `json:"offset"`
+	Slot   string `json:"slot"`
+	Type   string `json:"type"`
+}
+
+type StorageType struct {
+	Encoding      string       `json:"encoding"` // inplace, mapping, bytes or dynamic_array
+	Label         string       `json:"label"`
+	NumberOfBytes string       `json:"numberOfBytes"`
+	Key           string       `json:"key"`     // mappings
+	Value         string       `json:"value"`   // mappings
+	Base          string       `json:"base"`    // arrays
+	Members       []StorageVar `json:"members"` // structs
+}
+
+type StorageValue struct {
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Slot  []byte `json:"slot"`
+	Value string `json:"value"`
+}
+
+type StorageChange struct {
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Slot  []byte `json:"slot"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// the storage of a contract, keyed by slot. Missing slots are zero
+type storageMap map[common.Word256]common.Word256
+
+func (s storageMap) get(slot *big.Int) common.Word256 {
+	return s[slotWord(slot)]
+}
+
+func cliStorageLayout(c *cli.Context) {
+	args := c.Args()
+	var layout *StorageLayout
+	if layoutFile := c.String("layout"); layoutFile != "" {
+		var err error
+		layout, err = readLayout(layoutFile)
+		ifExit(err)
+	}
+	var keys []string
+	if k := c.String("key"); k != "" {
+		if layout == nil {
+			exit(fmt.Errorf("--key requires a --layout"))
+		}
+		keys = strings.Split(k, ",")
+	}
+
+	if diff := c.String("diff"); diff != "" {
+		files := strings.Split(diff, ",")
+		old, err := readStorageSnapshot(files[0])
+		ifExit(err)
+		var cur storageMap
+		if len(files) > 1 {
+			cur, err = readStorageSnapshot(files[1])
+		} else {
+			if len(args) == 0 {
+				exit(fmt.Errorf("must specify an address to diff against, or a second snapshot"))
+			}
+			cur, err = dumpStorage(args[0])
+		}
+		ifExit(err)
+		changes, err := diffStorage(layout, old, cur, keys)
+		ifExit(err)
+		if c.Bool("json") {
+			s, err := prettyPrint(changes)
+			ifExit(err)
+			fmt.Println(s)
+			return
+		}
+		printStorageChanges(changes)
+		return
+	}
+
+	if len(args) == 0 {
+		exit(fmt.Errorf("must specify an address"))
+	}
+	storage, err := dumpStorage(args[0])
+	ifExit(err)
+	values, _, err := decodeStorage(layout, storage, keys)
+	ifExit(err)
+	if c.Bool("json") {
+		s, err := prettyPrint(values)
+		ifExit(err)
+		fmt.Println(s)
+		return
+	}
+	printStorageValues(values)
+}
+
+func readLayout(file string) (*StorageLayout, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	layout := new(StorageLayout)
+	if err := json.Unmarshal(b, layout); err != nil {
+		return nil, fmt.Errorf("error reading storage layout %s: %v", file, err)
+	}
+	return layout, nil
+}
+
+// a snapshot is the output of `mintinfo storage <addr>`
+func readStorageSnapshot(file string) (storageMap, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	dump := new(ctypes.ResultDumpStorage)
+	wire.ReadJSONPtr(dump, b, &err)
+	if err != nil {
+		return nil, fmt.Errorf("error reading storage snapshot %s: %v", file, err)
+	}
+	return newStorageMap(dump), nil
+}
+
+func dumpStorage(addr string) (storageMap, error) {
+	addrBytes, err := hex.DecodeString(addr)
+	if err != nil {
+		return nil, fmt.Errorf("Addr %s is improper hex: %v", addr, err)
+	}
+	dump, err := client.DumpStorage(addrBytes)
+	if err != nil {
+		return nil, err
+	}
+	return newStorageMap(dump), nil
+}
+
+func newStorageMap(dump *ctypes.ResultDumpStorage) storageMap {
+	storage := make(storageMap)
+	for _, item := range dump.StorageItems {
+		storage[common.LeftPadWord256(item.Key)] = common.LeftPadWord256(item.Value)
+	}
+	return storage
+}
+
+//------------------------------------------------------------------------------
+// decoding
+
+var two256 = new(big.Int).Lsh(big.NewInt(1), 256)
+
+func slotWord(slot *big.Int) common.Word256 {
+	return common.LeftPadWord256(new(big.Int).Mod(slot, two256).Bytes())
+}
+
+func keccakSlot(data ...[]byte) *big.Int {
+	return new(big.Int).SetBytes(sha3.Sha3(data...))
+}
+
+// decode the state variables in the layout, and any mapping entries asked for with keys.
+// Also returns the slots that were read, so the rest can be reported raw
+func decodeStorage(layout *StorageLayout, storage storageMap, keys []string) ([]*StorageValue, map[common.Word256]bool, error) {
+	d := &decoder{
+		layout:  layout,
+		storage: storage,
+		read:    make(map[common.Word256]bool),
+	}
+	if layout == nil {
+		return nil, d.read, nil
+	}
+	for _, v := range layout.Storage {
+		slot, ok := new(big.Int).SetString(v.Slot, 10)
+		if !ok {
+			return nil, nil, fmt.Errorf("bad slot %q for %s", v.Slot, v.Label)
+		}
+		if err := d.decode(v.Label, v.Type, slot, v.Offset); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, key := range keys {
+		if err := d.lookup(key); err != nil {
+			return nil, nil, err
+		}
+	}
+	return d.values, d.read, nil
+}
+
+type decoder struct {
+	layout  *StorageLayout
+	storage storageMap
+	read    map[common.Word256]bool
+	values  []*StorageValue
+}
+
+func (d *decoder) word(slot *big.Int) common.Word256 {
+	d.read[slotWord(slot)] = true
+	return d.storage.get(slot)
+}
+
+func (d *decoder) add(label string, t *StorageType, slot *big.Int, value string) {
+	d.values = append(d.values, &StorageValue{Label: label, Type: t.Label, Slot: slotWord(slot).Bytes(), Value: value})
+}
+
+func (d *decoder) decode(label, typeID string, slot *big.Int, offset int) error {
+	t, ok := d.layout.Types[typeID]
+	if !ok {
+		return fmt.Errorf("unknown type %s for %s", typeID, label)
+	}
+	switch t.Encoding {
+	case "mapping":
+		d.add(label, t, slot, "(mapping, look up entries with --key)")
+	case "dynamic_array":
+		d.add(label, t, slot, fmt.Sprintf("(array of length %s)", new(big.Int).SetBytes(d.word(slot).Bytes())))
+	case "bytes":
+		d.add(label, t, slot, d.decodeBytes(t, slot))
+	case "inplace":
+		if len(t.Members) > 0 {
+			for _, m := range t.Members {
+				mslot, ok := new(big.Int).SetString(m.Slot, 10)
+				if !ok {
+					return fmt.Errorf("bad slot %q for %s.%s", m.Slot, label, m.Label)
+				}
+				if err := d.decode(label+"."+m.Label, m.Type, mslot.Add(mslot, slot), m.Offset); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if t.Base != "" {
+			d.add(label, t, slot, "(static array, not decoded)")
+			return nil
+		}
+		size, err := strconv.Atoi(t.NumberOfBytes)
+		if err != nil || size < 1 || offset+size > 32 {
+			return fmt.Errorf("bad size %q for %s", t.NumberOfBytes, label)
+		}
+		w := d.word(slot)
+		d.add(label, t, slot, formatStorageValue(t.Label, w[32-offset-size:32-offset]))
+	default:
+		return fmt.Errorf("unknown encoding %s for %s", t.Encoding, label)
+	}
+	return nil
+}
+
+// strings and bytes under 32 bytes are stored in the slot along with twice their length.
+// Longer ones store twice their length plus one, with the data starting at keccak(slot)
+func (d *decoder) decodeBytes(t *StorageType, slot *big.Int) string {
+	w := d.word(slot)
+	var data []byte
+	if w[31]&1 == 0 {
+		// at most 31 bytes fit with the length. More means a wrong layout or slot
+		n := int(w[31] / 2)
+		if n > 31 {
+			return fmt.Sprintf("(malformed %s)", t.Label)
+		}
+		data = w[:n]
+	} else {
+		n := new(big.Int).SetBytes(w.Bytes())
+		n.Rsh(n, 1)
+		if !n.IsInt64() || n.Int64() > 1<<20 {
+			return fmt.Sprintf("(%s of length %s)", t.Label, n)
+		}
+		length := int(n.Int64())
+		start := keccakSlot(slotWord(slot).Bytes())
+		for i := 0; len(data) < length; i++ {
+			w := d.word(new(big.Int).Add(start, big.NewInt(int64(i))))
+			data = append(data, w[:]...)
+		}
+		data = data[:length]
+	}
+	if t.Label == "string" {
+		return strconv.Quote(string(data))
+	}
+	return fmt.Sprintf("0x%X", data)
+}
+
+var mappingKeyRegexp = regexp.MustCompile(`\[([^\]]*)\]`)
+
+// look up a mapping entry like balances[<key>] or allowed[<key>][<key>]
+func (d *decoder) lookup(key string) error {
+	i := strings.Index(key, "[")
+	if i < 0 {
+		return fmt.Errorf("bad key %q, expected <mapping>[<key>]", key)
+	}
+	name := key[:i]
+	var v *StorageVar
+	for j := range d.layout.Storage {
+		if d.layout.Storage[j].Label == name {
+			v = &d.layout.Storage[j]
+		}
+	}
+	if v == nil {
+		return fmt.Errorf("no state variable %s in the layout", name)
+	}
+	slot, ok := new(big.Int).SetString(v.Slot, 10)
+	if !ok {
+		return fmt.Errorf("bad slot %q for %s", v.Slot, v.Label)
+	}
+
+	typeID := v.Type
+	for _, match := range mappingKeyRegexp.FindAllStringSubmatch(key[i:], -1) {
+		t := d.layout.Types[typeID]
+		if t == nil || t.Encoding != "mapping" {
+			return fmt.Errorf("%s is not a mapping", key)
+		}
+		k, err := encodeMappingKey(d.layout.Types[t.Key], match[1])
+		if err != nil {
+			return fmt.Errorf("bad key for %s: %v", key, err)
+		}
+		slot = mappingSlot(k, slot)
+		typeID = t.Value
+	}
+	return d.decode(key, typeID, slot, 0)
+}
+
+// the slot of a mapping entry is keccak(key . slot)
+func mappingSlot(key []byte, slot *big.Int) *big.Int {
+	return keccakSlot(key, slotWord(slot).Bytes())
+}
+
+// value types are padded to 32 bytes, strings and bytes are used as is
+func encodeMappingKey(t *StorageType, key string) ([]byte, error) {
+	if t == nil {
+		return nil, fmt.Errorf("unknown key type")
+	}
+	label := t.Label
+	switch {
+	case label == "string":
+		return []byte(key), nil
+	case label == "bytes":
+		return hex.DecodeString(strings.TrimPrefix(key, "0x"))
+	case label == "bool":
+		b, err := strconv.ParseBool(key)
+		if err != nil {
+			return nil, err
+		}
+		if b {
+			return common.LeftPadWord256([]byte{1}).Bytes(), nil
+		}
+		return common.Zero256.Bytes(), nil
+	case strings.HasPrefix(label, "bytes"):
+		b, err := hex.DecodeString(strings.TrimPrefix(key, "0x"))
+		if err != nil {
+			return nil, err
+		}
+		return common.RightPadWord256(b).Bytes(), nil
+	case strings.HasPrefix(label, "uint"), strings.HasPrefix(label, "int"), strings.HasPrefix(label, "enum"):
+		n, ok := new(big.Int).SetString(key, 0)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a number", key)
+		}
+		return slotWord(n).Bytes(), nil
+	default:
+		// addresses and contracts
+		b, err := hex.DecodeString(strings.TrimPrefix(key, "0x"))
+		if err != nil {
+			return nil, err
+		}
+		return common.LeftPadWord256(b).Bytes(), nil
+	}
+}
+
+func formatStorageValue(label string, b []byte) string {
+	switch {
+	case label == "bool":
+		return strconv.FormatBool(new(big.Int).SetBytes(b).Sign() != 0)
+	case label == "address", strings.HasPrefix(label, "address "), strings.HasPrefix(label, "contract "):
+		return fmt.Sprintf("%X", b)
+	case strings.HasPrefix(label, "uint"), strings.HasPrefix(label, "enum "):
+		return new(big.Int).SetBytes(b).String()
+	case strings.HasPrefix(label, "int"):
+		n := new(big.Int).SetBytes(b)
+		if len(b) > 0 && b[0]&0x80 != 0 {
+			n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+		}
+		return n.String()
+	}
+	return fmt.Sprintf("0x%X", b)
+}
+
+//------------------------------------------------------------------------------
+// diffs
+
+// the decoded values that differ between the two snapshots, followed by any
+// other slots that changed (mapping entries, array elements, etc.)
+func diffStorage(layout *StorageLayout, old, cur storageMap, keys []string) ([]*StorageChange, error) {
+	var changes []*StorageChange
+	oldValues, oldRead, err := decodeStorage(layout, old, keys)
+	if err != nil {
+		return nil, err
+	}
+	curValues, curRead, err := decodeStorage(layout, cur, keys)
+	if err != nil {
+		return nil, err
+	}
+	if layout != nil {
+		for i, v := range curValues {
+			if o := oldValues[i]; o.Value != v.Value {
+				changes = append(changes, &StorageChange{Label: v.Label, Type: v.Type, Slot: v.Slot, Old: o.Value, New: v.Value})
+			}
+		}
+	}
+
+	slots := make(map[common.Word256]bool)
+	for slot := range old {
+		slots[slot] = true
+	}
+	for slot := range cur {
+		slots[slot] = true
+	}
+	var raw []common.Word256
+	for slot := range slots {
+		if layout != nil && (oldRead[slot] || curRead[slot]) {
+			continue
+		}
+		if old[slot] != cur[slot] {
+			raw = append(raw, slot)
+		}
+	}
+	sort.Sort(wordSlice(raw))
+	for _, slot := range raw {
+		o, c := old[slot], cur[slot]
+		changes = append(changes, &StorageChange{
+			Label: fmt.Sprintf("0x%s", trimWord(slot.Bytes())),
+			Slot:  slot.Bytes(),
+			Old:   fmt.Sprintf("0x%X", o.Bytes()),
+			New:   fmt.Sprintf("0x%X", c.Bytes()),
+		})
+	}
+	return changes, nil
+}
+
+type wordSlice []common.Word256
+
+func (s wordSlice) Len() int           { return len(s) }
+func (s wordSlice) Less(i, j int) bool { return s[i].Compare(s[j]) < 0 }
+func (s wordSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+//------------------------------------------------------------------------------
+// output
+
+func printStorageValues(values []*StorageValue) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tVALUE")
+	for _, v := range values {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", v.Label, v.Type, v.Value)
+	}
+	w.Flush()
+}
+
+func printStorageChanges(changes []*StorageChange) {
+	if len(changes) == 0 {
+		fmt.Println("No changes")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tOLD\tNEW")
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Label, c.Type, c.Old, c.New)
+	}
+	w.Flush()
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/common"
+	ctypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core/types"
+)
+
+var testLayout = `{
+	"storage": [
+		{"label": "total", "offset": 0, "slot": "0", "type": "t_uint256"},
+		{"label": "open", "offset": 0, "slot": "1", "type": "t_bool"},
+		{"label": "owner", "offset": 1, "slot": "1", "type": "t_address"},
+		{"label": "delta", "offset": 21, "slot": "1", "type": "t_int8"},
+		{"label": "name", "offset": 0, "slot": "2", "type": "t_string_storage"},
+		{"label": "balances", "offset": 0, "slot": "3", "type": "t_mapping(t_address,t_uint256)"}
+	],
+	"types": {
+		"t_address": {"encoding": "inplace", "label": "address", "numberOfBytes": "20"},
+		"t_bool": {"encoding": "inplace", "label": "bool", "numberOfBytes": "1"},
+		"t_int8": {"encoding": "inplace", "label": "int8", "numberOfBytes": "1"},
+		"t_mapping(t_address,t_uint256)": {"encoding": "mapping", "key": "t_address", "label": "mapping(address => uint256)", "numberOfBytes": "32", "value": "t_uint256"},
+		"t_string_storage": {"encoding": "bytes", "label": "string", "numberOfBytes": "32"},
+		"t_uint256": {"encoding": "inplace", "label": "uint256", "numberOfBytes": "32"}
+	}
+}`
+
+func testStorage() storageMap {
+	owner, _ := hex.DecodeString("00000000000000000000000000000000000000AB")
+	var packed common.Word256
+	packed[31] = 1             // open
+	copy(packed[11:31], owner) // owner
+	packed[10] = 0xfe          // delta
+
+	var name common.Word256
+	copy(name[:], "mint")
+	name[31] = 8
+
+	return storageMap{
+		slotWord(big.NewInt(0)): common.LeftPadWord256([]byte{0x01, 0x00}),
+		slotWord(big.NewInt(1)): packed,
+		slotWord(big.NewInt(2)): name,
+		slotWord(mappingSlot(common.LeftPadWord256([]byte{0xab}).Bytes(), big.NewInt(3))): common.LeftPadWord256([]byte{5}),
+	}
+}
+
+func TestMappingSlot(t *testing.T) {
+	// keccak(uint256(0) . uint256(0)), the entry for key 0 in a mapping at slot 0
+	slot := mappingSlot(make([]byte, 32), big.NewInt(0))
+	if s := hex.EncodeToString(slotWord(slot).Bytes()); s != "ad3228b676f7d3cd4284a5443f17f1962b36e491b30a40b2405849e597ba5fb5" {
+		t.Fatalf("bad mapping slot %s", s)
+	}
+}
+
+func TestDecodeStorage(t *testing.T) {
+	layout := new(StorageLayout)
+	if err := json.Unmarshal([]byte(testLayout), layout); err != nil {
+		t.Fatal(err)
+	}
+	values, _, err := decodeStorage(layout, testStorage(), []string{"balances[AB]", "balances[CD]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct{ label, value string }{
+		{"total", "256"},
+		{"open", "true"},
+		{"owner", "00000000000000000000000000000000000000AB"},
+		{"delta", "-2"},
+		{"name", `"mint"`},
+		{"balances", "(mapping, look up entries with --key)"},
+		{"balances[AB]", "5"},
+		{"balances[CD]", "0"},
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("expected %d values, got %d", len(expected), len(values))
+	}
+	for i, e := range expected {
+		if values[i].Label != e.label || values[i].Value != e.value {
+			t.Fatalf("value %d: expected %s = %s, got %s = %s", i, e.label, e.value, values[i].Label, values[i].Value)
+		}
+	}
+
+	if _, _, err := decodeStorage(layout, testStorage(), []string{"total[AB]"}); err == nil {
+		t.Fatal("expected error looking up a key in a non-mapping")
+	}
+
+	// a short string can't be over 31 bytes, so a stale layout mustn't panic
+	storage := testStorage()
+	storage[slotWord(big.NewInt(2))] = common.LeftPadWord256([]byte{0x42})
+	values, _, err = decodeStorage(layout, storage, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[4].Label != "name" || values[4].Value != "(malformed string)" {
+		t.Fatalf("expected a malformed name, got %s = %s", values[4].Label, values[4].Value)
+	}
+}
+
+func TestDiffStorage(t *testing.T) {
+	layout := new(StorageLayout)
+	if err := json.Unmarshal([]byte(testLayout), layout); err != nil {
+		t.Fatal(err)
+	}
+
+	// round trip the old storage through a snapshot file
+	old := testStorage()
+	dump := new(ctypes.ResultDumpStorage)
+	for k, v := range old {
+		dump.StorageItems = append(dump.StorageItems, ctypes.StorageItem{Key: k.Bytes(), Value: v.Bytes()})
+	}
+	s, err := prettyPrint(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "mintinfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "old.json")
+	if err := ioutil.WriteFile(file, []byte(s), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if old, err = readStorageSnapshot(file); err != nil {
+		t.Fatal(err)
+	}
+
+	cur := testStorage()
+	cur[slotWord(big.NewInt(0))] = common.LeftPadWord256([]byte{0x02, 0x00})
+	cur[slotWord(mappingSlot(common.LeftPadWord256([]byte{0xab}).Bytes(), big.NewInt(3)))] = common.LeftPadWord256([]byte{6})
+
+	changes, err := diffStorage(layout, old, cur, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d", len(changes))
+	}
+	if c := changes[0]; c.Label != "total" || c.Old != "256" || c.New != "512" {
+		t.Fatalf("bad change %v", c)
+	}
+	// the mapping entry is only known by its slot unless it's asked for
+	if c := changes[1]; c.Type != "" || c.New != "0x"+hex.EncodeToString(common.LeftPadWord256([]byte{6}).Bytes()) {
+		t.Fatalf("bad raw change %v", c)
+	}
+
+	changes, err = diffStorage(layout, old, cur, []string{"balances[AB]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[1].Label != "balances[AB]" || changes[1].Old != "5" || changes[1].New != "6" {
+		t.Fatalf("bad changes with key %v", changes)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/codegangsta/cli"
 	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
@@ -51,6 +52,156 @@ func main() {
 			Value: DefaultChainID,
 		}
 
+		//----------------------------------------------------------------
+		// command flags
+
+		fromFlag = cli.IntFlag{
+			Name:  "from",
+			Usage: "first block height to include (defaults to 100 blocks back)",
+		}
+
+		toFlag = cli.IntFlag{
+			Name:  "to",
+			Usage: "last block height to include (defaults to the latest committed block)",
+		}
+
+		jsonFlag = cli.BoolFlag{
+			Name:  "json",
+			Usage: "print the output as json",
+		}
+
+		nodesFlag = cli.StringFlag{
+			Name:  "nodes",
+			Usage: "comma separated list of node rpc addresses (defaults to --node-addr)",
+		}
+
+		intervalFlag = cli.DurationFlag{
+			Name:  "interval",
+			Usage: "how often to poll the nodes",
+			Value: 5 * time.Second,
+		}
+
+		stallFlag = cli.DurationFlag{
+			Name:  "stall",
+			Usage: "alert if a node hasn't made a new block in this long",
+			Value: 30 * time.Second,
+		}
+
+		minPeersFlag = cli.IntFlag{
+			Name:  "min-peers",
+			Usage: "alert if a node has fewer peers than this",
+			Value: 1,
+		}
+
+		mempoolGrowthFlag = cli.IntFlag{
+			Name:  "mempool-growth",
+			Usage: "alert if a node's mempool grows for this many polls in a row (0 to disable)",
+			Value: 10,
+		}
+
+		webhookFlag = cli.StringFlag{
+			Name:  "webhook",
+			Usage: "url to POST alerts to as json",
+		}
+
+		twilioSidFlag = cli.StringFlag{
+			Name:  "twilio-sid",
+			Usage: "twilio account sid for sms alerts",
+		}
+
+		twilioTokenFlag = cli.StringFlag{
+			Name:  "twilio-token",
+			Usage: "twilio auth token for sms alerts",
+		}
+
+		twilioFromFlag = cli.StringFlag{
+			Name:  "twilio-from",
+			Usage: "phone number to send sms alerts from",
+		}
+
+		twilioToFlag = cli.StringFlag{
+			Name:  "twilio-to",
+			Usage: "phone number to send sms alerts to",
+		}
+
+		listenFlag = cli.StringFlag{
+			Name:  "listen",
+			Usage: "address to serve on",
+			Value: ":9100",
+		}
+
+		watchFlag = cli.StringFlag{
+			Name:  "watch",
+			Usage: "comma separated list of account addresses to export balances for",
+		}
+
+		followFlag = cli.BoolFlag{
+			Name:  "follow",
+			Usage: "keep polling and redrawing the output",
+		}
+
+		compareFlag = cli.StringFlag{
+			Name:  "compare",
+			Usage: "check the code against a locally compiled runtime .bin file",
+		}
+
+		numBlocksFlag = cli.IntFlag{
+			Name:  "blocks",
+			Usage: "number of recent blocks to show",
+			Value: 10,
+		}
+
+		layoutFlag = cli.StringFlag{
+			Name:  "layout",
+			Usage: "decode the storage into named, typed state variables using a solc storage layout json",
+		}
+
+		keyFlag = cli.StringFlag{
+			Name:  "key",
+			Usage: "comma separated list of mapping entries to look up with the layout, eg. balances[<addr>],allowed[<addr>][<addr>]",
+		}
+
+		diffFlag = cli.StringFlag{
+			Name:  "diff",
+			Usage: "diff a saved `mintinfo storage <addr>` snapshot against the current storage, or against a second snapshot: --diff <old>[,<new>]",
+		}
+
+		prefixFlag = cli.StringFlag{
+			Name:  "prefix",
+			Usage: "only list names with this prefix",
+		}
+
+		regexFlag = cli.StringFlag{
+			Name:  "regex",
+			Usage: "only list names matching this regular expression",
+		}
+
+		ownerFlag = cli.StringFlag{
+			Name:  "owner",
+			Usage: "only list names owned by this address",
+		}
+
+		expiresWithinFlag = cli.IntFlag{
+			Name:  "expires-within",
+			Usage: "only list names that expire within this many blocks, soonest first",
+		}
+
+		schemaFlag = cli.StringFlag{
+			Name:  "schema",
+			Usage: "print each name's data field by field against a json schema file or a built in schema (dns)",
+		}
+
+		exportZoneFlag = cli.StringFlag{
+			Name:  "export-zone",
+			Usage: "print the dns records under a domain as a bind zone file",
+		}
+
+		searchBlocksFlag = cli.IntFlag{
+			Name:  "blocks",
+			Usage: "number of recent blocks to search for the tx",
+			Value: 100,
+		}
+
 		//----------------------------------------------------------------
 
 		statusCmd = cli.Command{
@@ -95,10 +246,27 @@ func main() {
 			Action: cliAccounts,
 		}
 
+		permsCmd = cli.Command{
+			Name:   "perms",
+			Usage:  "Show the effective value of every permission for an account, whether it's set on the account or inherited from global, and its roles: mintinfo perms <addr>",
+			Action: cliPerms,
+			Flags: []cli.Flag{
+				jsonFlag,
+			},
+		}
+
 		namesCmd = cli.Command{
 			Name:   "names",
-			Usage:  "List all name reg entries on the chain",
+			Usage:  "List all name reg entries on the chain, or those matching the filters, or specify a name",
 			Action: cliNames,
+			Flags: []cli.Flag{
+				prefixFlag,
+				regexFlag,
+				ownerFlag,
+				expiresWithinFlag,
+				schemaFlag,
+				exportZoneFlag,
+			},
 		}
 
 		blocksCmd = cli.Command{
@@ -111,6 +279,12 @@ func main() {
 			Name:   "storage",
 			Usage:  "Get the storage for an account, or for a particular key in that account's storage",
 			Action: cliStorage,
+			Flags: []cli.Flag{
+				layoutFlag,
+				keyFlag,
+				diffFlag,
+				jsonFlag,
+			},
 		}
 
 		callCmd = cli.Command{
@@ -130,6 +304,100 @@ func main() {
 			Usage:  "Broadcast some tx bytes",
 			Action: cliBroadcast,
 		}
+
+		uptimeCmd = cli.Command{
+			Name:   "uptime",
+			Usage:  "Report signed and missed blocks for each validator: mintinfo uptime --from <height> --to <height>",
+			Action: cliUptime,
+			Flags: []cli.Flag{
+				fromFlag,
+				toFlag,
+				jsonFlag,
+			},
+		}
+
+		monitorCmd = cli.Command{
+			Name:   "monitor",
+			Usage:  "Watch a set of nodes for stalls, forks, lost peers and a growing mempool: mintinfo monitor --nodes <addr>,<addr>",
+			Action: cliMonitor,
+			Flags: []cli.Flag{
+				nodesFlag,
+				intervalFlag,
+				stallFlag,
+				minPeersFlag,
+				mempoolGrowthFlag,
+				webhookFlag,
+				twilioSidFlag,
+				twilioTokenFlag,
+				twilioFromFlag,
+				twilioToFlag,
+			},
+		}
+
+		exporterCmd = cli.Command{
+			Name:   "exporter",
+			Usage:  "Serve chain and node metrics for prometheus: mintinfo exporter --listen :9100 --watch <addr>,<addr>",
+			Action: cliExporter,
+			Flags: []cli.Flag{
+				listenFlag,
+				watchFlag,
+			},
+		}
+
+		topCmd = cli.Command{
+			Name:   "top",
+			Usage:  "Live view of a node's height, peers, validators, mempool and recent blocks",
+			Action: cliTop,
+			Flags: []cli.Flag{
+				intervalFlag,
+				numBlocksFlag,
+			},
+		}
+
+		mempoolCmd = cli.Command{
+			Name:   "mempool",
+			Usage:  "Decode the mempool by sender and flag txs that are stuck or will fail",
+			Action: cliMempool,
+			Flags: []cli.Flag{
+				jsonFlag,
+				followFlag,
+				intervalFlag,
+			},
+		}
+
+		codeCmd = cli.Command{
+			Name:   "code",
+			Usage:  "Disassemble a contract's code, or check it against a compiled binary: mintinfo code <addr> [--compare <bin>]",
+			Action: cliCode,
+			Flags: []cli.Flag{
+				compareFlag,
+				jsonFlag,
+			},
+		}
+
+		traceCmd = cli.Command{
+			Name:  "trace",
+			Usage: "Run a call or CallTx through a local vm against the node's state and trace every instruction",
+			Subcommands: []cli.Command{
+				{
+					Name:   "call",
+					Usage:  "Trace a call: mintinfo trace call <from> <to> <data>",
+					Action: cliTraceCall,
+					Flags: []cli.Flag{
+						jsonFlag,
+					},
+				},
+				{
+					Name:   "tx",
+					Usage:  "Trace a CallTx in the mempool against the latest state: mintinfo trace tx <hash>",
+					Action: cliTraceTx,
+					Flags: []cli.Flag{
+						searchBlocksFlag,
+						jsonFlag,
+					},
+				},
+			},
+		}
 	)
 
 	app := cli.NewApp()
@@ -146,12 +414,20 @@ func main() {
 		consensusCmd,
 		unconfirmedCmd,
 		accountsCmd,
+		permsCmd,
 		namesCmd,
 		blocksCmd,
 		storageCmd,
 		callCmd,
 		callCodeCmd,
 		broadcastCmd,
+		uptimeCmd,
+		monitorCmd,
+		exporterCmd,
+		topCmd,
+		mempoolCmd,
+		codeCmd,
+		traceCmd,
 	}
 	app.Flags = []cli.Flag{
 		nodeAddrFlag,
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/codegangsta/cli"
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------
+// mempool analyzer
+
+type MempoolTx struct {
+	ID        []byte    `json:"id"`
+	Tx        types.Tx  `json:"tx"`
+	Sequence  int       `json:"sequence"`
+	Amount    int64     `json:"amount"`
+	FirstSeen time.Time `json:"first_seen"`
+	Issues    []string  `json:"issues"`
+}
+
+type MempoolSender struct {
+	Address  []byte       `json:"address"` // nil for txs without an input
+	Sequence int          `json:"sequence"`
+	Balance  int64        `json:"balance"`
+	Txs      []*MempoolTx `json:"txs"`
+}
+
+func cliMempool(c *cli.Context) {
+	status, err := client.Status()
+	ifExit(err)
+	chainID := status.NodeInfo.ChainID
+
+	firstSeen := make(map[string]time.Time)
+	for {
+		r, err := client.ListUnconfirmedTxs()
+		ifExit(err)
+		senders, err := coreMempool(chainID, r.Txs, getAccount, firstSeen, time.Now())
+		ifExit(err)
+
+		if c.Bool("json") {
+			s, err := prettyPrint(senders)
+			ifExit(err)
+			fmt.Println(s)
+		} else {
+			if c.Bool("follow") {
+				fmt.Print(clearScreen)
+			}
+			printMempool(senders, time.Now())
+		}
+
+		if !c.Bool("follow") {
+			return
+		}
+		time.Sleep(c.Duration("interval"))
+	}
+}
+
+func getAccount(addr []byte) (*acm.Account, error) {
+	r, err := client.GetAccount(addr)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, nil
+	}
+	return r.Account, nil
+}
+
+// group the txs by sender and flag the ones that are stuck or will fail.
+// firstSeen remembers when each tx was first polled, across calls.
+func coreMempool(chainID string, txs []types.Tx, getAccount func([]byte) (*acm.Account, error), firstSeen map[string]time.Time, now time.Time) ([]*MempoolSender, error) {
+	global, err := getAccount(ptypes.GlobalPermissionsAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make(map[string]*acm.Account)
+	fetch := func(addr []byte) (*acm.Account, error) {
+		if acc, ok := accounts[string(addr)]; ok {
+			return acc, nil
+		}
+		acc, err := getAccount(addr)
+		if err != nil {
+			return nil, err
+		}
+		accounts[string(addr)] = acc
+		return acc, nil
+	}
+
+	bySender := make(map[string]*MempoolSender)
+	var senders []*MempoolSender
+	seen := make(map[string]bool)
+	for _, tx := range txs {
+		id := types.TxID(chainID, tx)
+		seen[string(id)] = true
+		if _, ok := firstSeen[string(id)]; !ok {
+			firstSeen[string(id)] = now
+		}
+		mtx := &MempoolTx{ID: id, Tx: tx, FirstSeen: firstSeen[string(id)]}
+
+		var addr []byte
+		if in := txInput(tx); in != nil {
+			addr, mtx.Sequence, mtx.Amount = in.Address, in.Sequence, in.Amount
+		}
+		sender, ok := bySender[string(addr)]
+		if !ok {
+			sender = &MempoolSender{Address: addr}
+			bySender[string(addr)] = sender
+			senders = append(senders, sender)
+		}
+		sender.Txs = append(sender.Txs, mtx)
+	}
+
+	// forget txs that have left the mempool
+	for id := range firstSeen {
+		if !seen[id] {
+			delete(firstSeen, id)
+		}
+	}
+
+	for _, sender := range senders {
+		if sender.Address == nil {
+			continue
+		}
+		acc, err := fetch(sender.Address)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkSender(sender, acc, global, fetch); err != nil {
+			return nil, err
+		}
+	}
+	return senders, nil
+}
+
+func checkSender(sender *MempoolSender, acc, global *acm.Account, fetch func([]byte) (*acm.Account, error)) error {
+	sort.Stable(bySequence(sender.Txs))
+	if acc == nil {
+		for _, mtx := range sender.Txs {
+			mtx.Issues = append(mtx.Issues, "sender account does not exist")
+		}
+		return nil
+	}
+	sender.Sequence, sender.Balance = acc.Sequence, acc.Balance
+
+	next := acc.Sequence + 1
+	prev := -1
+	var spent int64
+	for _, mtx := range sender.Txs {
+		switch {
+		case mtx.Sequence <= acc.Sequence:
+			mtx.Issues = append(mtx.Issues, fmt.Sprintf("sequence %d already used on chain (at %d)", mtx.Sequence, acc.Sequence))
+		case mtx.Sequence == prev:
+			mtx.Issues = append(mtx.Issues, fmt.Sprintf("duplicate sequence %d", mtx.Sequence))
+		case mtx.Sequence > next:
+			mtx.Issues = append(mtx.Issues, fmt.Sprintf("sequence gap: waiting on %d", next))
+			next = mtx.Sequence + 1
+		default:
+			next = mtx.Sequence + 1
+		}
+		prev = mtx.Sequence
+
+		spent += mtx.Amount
+		if spent > acc.Balance {
+			mtx.Issues = append(mtx.Issues, fmt.Sprintf("insufficient balance: %d pending against %d", spent, acc.Balance))
+		}
+
+		perms, err := requiredPermissions(mtx.Tx, fetch)
+		if err != nil {
+			return err
+		}
+		for _, perm := range perms {
+			if v, _ := hasPermission(acc, global, perm); !v {
+				mtx.Issues = append(mtx.Issues, fmt.Sprintf("missing permission %s", ptypes.PermFlagToString(perm)))
+			}
+		}
+	}
+	return nil
+}
+
+// the permissions the sender of a tx must have for it to execute
+func requiredPermissions(tx_ types.Tx, fetch func([]byte) (*acm.Account, error)) ([]ptypes.PermFlag, error) {
+	switch tx := tx_.(type) {
+	case *types.SendTx:
+		perms := []ptypes.PermFlag{ptypes.Send}
+		for _, out := range tx.Outputs {
+			acc, err := fetch(out.Address)
+			if err != nil {
+				return nil, err
+			}
+			if acc == nil {
+				return append(perms, ptypes.CreateAccount), nil
+			}
+		}
+		return perms, nil
+	case *types.CallTx:
+		if len(tx.Address) == 0 {
+			return []ptypes.PermFlag{ptypes.CreateContract}, nil
+		}
+		return []ptypes.PermFlag{ptypes.Call}, nil
+	case *types.NameTx:
+		return []ptypes.PermFlag{ptypes.Name}, nil
+	case *types.BondTx:
+		return []ptypes.PermFlag{ptypes.Bond}, nil
+	case *types.PermissionsTx:
+		return []ptypes.PermFlag{tx.PermArgs.PermFlag()}, nil
+	}
+	return nil, nil
+}
+
+type bySequence []*MempoolTx
+
+func (s bySequence) Len() int           { return len(s) }
+func (s bySequence) Less(i, j int) bool { return s[i].Sequence < s[j].Sequence }
+func (s bySequence) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func printMempool(senders []*MempoolSender, now time.Time) {
+	var n int
+	for _, s := range senders {
+		n += len(s.Txs)
+	}
+	fmt.Printf("%d txs from %d senders\n", n, len(senders))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	for _, sender := range senders {
+		if sender.Address == nil {
+			fmt.Fprintf(w, "\nTxs without an input\n")
+		} else {
+			fmt.Fprintf(w, "\n%X (sequence %d, balance %d)\n", sender.Address, sender.Sequence, sender.Balance)
+		}
+		for _, mtx := range sender.Txs {
+			issues := "ok"
+			if len(mtx.Issues) > 0 {
+				issues = fmt.Sprintf("%q", mtx.Issues)
+			}
+			age := now.Sub(mtx.FirstSeen) / time.Second * time.Second
+			fmt.Fprintf(w, "  %d\t%X\t%v\t%s\t%s\n", mtx.Sequence, mtx.ID, age, txSummary(mtx.Tx), issues)
+		}
+	}
+	w.Flush()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+func testNameTx(addr []byte, seq int, amt int64) types.Tx {
+	return &types.NameTx{Input: &types.TxInput{Address: addr, Sequence: seq, Amount: amt}, Name: fmt.Sprintf("name%d", seq)}
+}
+
+func TestMempool(t *testing.T) {
+	alice, bob := []byte("alice"), []byte("bob")
+	accounts := map[string]*acm.Account{
+		string(ptypes.GlobalPermissionsAddress): {Permissions: ptypes.DefaultAccountPermissions},
+		string(alice):                           {Address: alice, Sequence: 3, Balance: 100},
+		string(bob):                             {Address: bob, Sequence: 0, Balance: 100},
+	}
+	// bob can't register names
+	accounts[string(bob)].Permissions.Base.Set(ptypes.Name, false)
+	getAccount := func(addr []byte) (*acm.Account, error) {
+		return accounts[string(addr)], nil
+	}
+
+	txs := []types.Tx{
+		testNameTx(alice, 6, 10),
+		testNameTx(alice, 4, 10),
+		testNameTx(alice, 3, 10),
+		testNameTx(alice, 4, 90),
+		testNameTx(bob, 1, 10),
+		&types.UnbondTx{Address: bob},
+	}
+	firstSeen := make(map[string]time.Time)
+	senders, err := coreMempool("test", txs, getAccount, firstSeen, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(senders) != 3 {
+		t.Fatalf("expected 3 senders, got %d", len(senders))
+	}
+
+	var issues []string
+	for _, mtx := range senders[0].Txs {
+		issues = append(issues, fmt.Sprintf("%d:%d", mtx.Sequence, len(mtx.Issues)))
+	}
+	// 3 is stale, the second 4 is a duplicate that overspends, 6 is after a gap and overspends
+	if fmt.Sprint(issues) != "[3:1 4:0 4:2 6:2]" {
+		t.Fatalf("unexpected issues for alice: %v", issues)
+	}
+	if bobTx := senders[1].Txs[0]; len(bobTx.Issues) != 1 || bobTx.Issues[0] != "missing permission name" {
+		t.Fatalf("expected bob to be missing the name permission, got %v", bobTx.Issues)
+	}
+	if senders[2].Address != nil || len(senders[2].Txs[0].Issues) != 0 {
+		t.Fatal("expected the unbond tx to be listed without a sender")
+	}
+
+	// txs that are gone are forgotten, new ones are remembered
+	if _, err := coreMempool("test", txs[:1], getAccount, firstSeen, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if len(firstSeen) != 1 {
+		t.Fatalf("expected 1 remembered tx, got %d", len(firstSeen))
+	}
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/codegangsta/cli"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/sfreiberg/gotwilio"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+)
+
+//------------------------------------------------------------------------------
+// multi-node liveness monitor
+
+const (
+	AlertUnreachable = "unreachable"
+	AlertStalled     = "stalled"
+	AlertDivergence  = "divergence"
+	AlertPeers       = "peers"
+	AlertMempool     = "mempool"
+)
+
+type Alert struct {
+	Node     string    `json:"node"`
+	Kind     string    `json:"kind"`
+	Message  string    `json:"message"`
+	Resolved bool      `json:"resolved"`
+	Time     time.Time `json:"time"`
+}
+
+func (a Alert) String() string {
+	status := "ALERT"
+	if a.Resolved {
+		status = "RESOLVED"
+	}
+	return fmt.Sprintf("[%s] %s %s (%s): %s", a.Time.Format(time.RFC3339), status, a.Kind, a.Node, a.Message)
+}
+
+type Alerter interface {
+	Alert(Alert) error
+}
+
+type MonitorConfig struct {
+	StallTimeout  time.Duration
+	MinPeers      int
+	MempoolGrowth int // consecutive polls of mempool growth before alerting
+}
+
+type nodeState struct {
+	addr   string
+	client cclient.Client
+
+	height       int
+	hash         []byte
+	lastProgress time.Time
+	roundState   string
+
+	mempool      int
+	mempoolGrows int
+}
+
+type Monitor struct {
+	config   MonitorConfig
+	nodes    []*nodeState
+	alerters []Alerter
+
+	// alerts currently firing, by kind and node
+	active map[string]Alert
+}
+
+func NewMonitor(config MonitorConfig, alerters []Alerter) *Monitor {
+	return &Monitor{
+		config:   config,
+		alerters: alerters,
+		active:   make(map[string]Alert),
+	}
+}
+
+func (m *Monitor) AddNode(addr string, client cclient.Client) {
+	m.nodes = append(m.nodes, &nodeState{addr: addr, client: client})
+}
+
+func cliMonitor(c *cli.Context) {
+	nodes := strings.Split(c.String("nodes"), ",")
+	if c.String("nodes") == "" {
+		nodes = []string{c.GlobalString("node-addr")}
+	}
+	// alerts that go over the network are sent in the background,
+	// so a slow one can't hold up polling
+	alerters := []Alerter{StdoutAlerter{}}
+	if webhook := c.String("webhook"); webhook != "" {
+		alerters = append(alerters, NewAsyncAlerter(NewWebhookAlerter(webhook, webhookTimeout)))
+	}
+	if sid := c.String("twilio-sid"); sid != "" {
+		alerters = append(alerters, NewAsyncAlerter(&TwilioAlerter{
+			client: gotwilio.NewTwilioClient(sid, c.String("twilio-token")),
+			from:   c.String("twilio-from"),
+			to:     c.String("twilio-to"),
+		}))
+	}
+
+	m := NewMonitor(MonitorConfig{
+		StallTimeout:  c.Duration("stall"),
+		MinPeers:      c.Int("min-peers"),
+		MempoolGrowth: c.Int("mempool-growth"),
+	}, alerters)
+	for _, addr := range nodes {
+		m.AddNode(addr, cclient.NewClient(addr, REQUEST_TYPE))
+	}
+
+	fmt.Printf("Monitoring %d nodes every %v\n", len(nodes), c.Duration("interval"))
+	ticker := time.NewTicker(c.Duration("interval"))
+	for {
+		m.Poll(time.Now())
+		<-ticker.C
+	}
+}
+
+// Poll every node once and fire or resolve alerts
+func (m *Monitor) Poll(now time.Time) {
+	var reachable []*nodeState
+	for _, node := range m.nodes {
+		if err := m.pollNode(node, now); err != nil {
+			m.set(node.addr, AlertUnreachable, true, err.Error(), now)
+			continue
+		}
+		m.set(node.addr, AlertUnreachable, false, "", now)
+		reachable = append(reachable, node)
+	}
+	m.checkDivergence(reachable, now)
+}
+
+func (m *Monitor) pollNode(node *nodeState, now time.Time) error {
+	status, err := node.client.Status()
+	if err != nil {
+		return err
+	}
+	if status.LatestBlockHeight > node.height || node.lastProgress.IsZero() {
+		node.lastProgress = now
+	}
+	node.height, node.hash = status.LatestBlockHeight, status.LatestBlockHash
+
+	if cs, err := node.client.DumpConsensusState(); err == nil {
+		node.roundState = parseRoundState(cs.RoundState).String()
+	}
+
+	stalled := now.Sub(node.lastProgress)
+	m.set(node.addr, AlertStalled, stalled > m.config.StallTimeout,
+		fmt.Sprintf("no new block for %v at height %d (%s)", stalled, node.height, node.roundState), now)
+
+	netInfo, err := node.client.NetInfo()
+	if err != nil {
+		return err
+	}
+	peers := len(netInfo.Peers)
+	m.set(node.addr, AlertPeers, peers < m.config.MinPeers,
+		fmt.Sprintf("%d peers, expected at least %d", peers, m.config.MinPeers), now)
+
+	txs, err := node.client.ListUnconfirmedTxs()
+	if err != nil {
+		return err
+	}
+	if len(txs.Txs) > node.mempool {
+		node.mempoolGrows += 1
+	} else {
+		node.mempoolGrows = 0
+	}
+	node.mempool = len(txs.Txs)
+	m.set(node.addr, AlertMempool, m.config.MempoolGrowth > 0 && node.mempoolGrows >= m.config.MempoolGrowth,
+		fmt.Sprintf("mempool has grown for %d polls to %d txs", node.mempoolGrows, node.mempool), now)
+	return nil
+}
+
+// compare block hashes at the highest height all the nodes have reached
+func (m *Monitor) checkDivergence(nodes []*nodeState, now time.Time) {
+	if len(nodes) < 2 {
+		return
+	}
+	height := nodes[0].height
+	for _, node := range nodes {
+		if node.height < height {
+			height = node.height
+		}
+	}
+	if height < 1 {
+		return
+	}
+
+	var hashes []string
+	var first []byte
+	diverged := false
+	for _, node := range nodes {
+		hash := node.hash
+		if node.height != height {
+			r, err := node.client.BlockchainInfo(height, height)
+			if err != nil || len(r.BlockMetas) == 0 {
+				continue
+			}
+			hash = r.BlockMetas[0].Hash
+		}
+		if first == nil {
+			first = hash
+		} else if !bytes.Equal(first, hash) {
+			diverged = true
+		}
+		hashes = append(hashes, fmt.Sprintf("%s=%X", node.addr, hash))
+	}
+	m.set("all", AlertDivergence, diverged,
+		fmt.Sprintf("block hashes disagree at height %d: %s", height, strings.Join(hashes, ", ")), now)
+}
+
+// fire an alert when a condition starts, and resolve it when it stops
+func (m *Monitor) set(node, kind string, firing bool, msg string, now time.Time) {
+	key := kind + "/" + node
+	_, active := m.active[key]
+	if firing == active {
+		return
+	}
+
+	alert := Alert{Node: node, Kind: kind, Message: msg, Resolved: !firing, Time: now}
+	if firing {
+		m.active[key] = alert
+	} else {
+		alert.Message = fmt.Sprintf("%s is back to normal", kind)
+		delete(m.active, key)
+	}
+	for _, a := range m.alerters {
+		if err := a.Alert(alert); err != nil {
+			fmt.Printf("Error sending alert: %v\n", err)
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+// round state
+
+type RoundState struct {
+	Height int
+	Round  int
+	Step   string
+}
+
+func (rs RoundState) String() string {
+	return fmt.Sprintf("%d/%d/%s", rs.Height, rs.Round, rs.Step)
+}
+
+var roundStateRegexp = regexp.MustCompile(`H:(\d+) R:(\d+) S:(\w+)`)
+
+// pull the height, round and step out of a dumped consensus round state
+func parseRoundState(s string) RoundState {
+	var rs RoundState
+	match := roundStateRegexp.FindStringSubmatch(s)
+	if match == nil {
+		return rs
+	}
+	rs.Height, _ = strconv.Atoi(match[1])
+	rs.Round, _ = strconv.Atoi(match[2])
+	rs.Step = match[3]
+	return rs
+}
+
+//------------------------------------------------------------------------------
+// alerters
+
+type StdoutAlerter struct{}
+
+func (StdoutAlerter) Alert(a Alert) error {
+	fmt.Println(a)
+	return nil
+}
+
+const (
+	webhookTimeout  = 10 * time.Second
+	alertQueueDepth = 100
+)
+
+type WebhookAlerter struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookAlerter(url string, timeout time.Duration) *WebhookAlerter {
+	return &WebhookAlerter{url, &http.Client{Timeout: timeout}}
+}
+
+func (w *WebhookAlerter) Alert(a Alert) error {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewBuffer(b))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+type TwilioAlerter struct {
+	client   *gotwilio.Twilio
+	from, to string
+}
+
+func (t *TwilioAlerter) Alert(a Alert) error {
+	_, exception, err := t.client.SendSMS(t.from, t.to, a.String(), "", "")
+	if err != nil {
+		return err
+	}
+	if exception != nil {
+		return fmt.Errorf("twilio error %d: %s", exception.Code, exception.Message)
+	}
+	return nil
+}
+
+// AsyncAlerter sends alerts from its own goroutine, in order.
+// Alert only fails if the queue is full
+type AsyncAlerter struct {
+	alerter Alerter
+	queue   chan Alert
+}
+
+func NewAsyncAlerter(alerter Alerter) *AsyncAlerter {
+	a := &AsyncAlerter{alerter, make(chan Alert, alertQueueDepth)}
+	go func() {
+		for alert := range a.queue {
+			if err := a.alerter.Alert(alert); err != nil {
+				fmt.Printf("Error sending alert: %v\n", err)
+			}
+		}
+	}()
+	return a
+}
+
+func (a *AsyncAlerter) Alert(alert Alert) error {
+	select {
+	case a.queue <- alert:
+		return nil
+	default:
+		return fmt.Errorf("alert queue is full, dropping %s", alert)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ctypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core/types"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+// fakeNode implements the parts of the rpc client the monitor uses
+type fakeNode struct {
+	cclient.Client
+
+	down    bool
+	height  int
+	hashes  map[int][]byte
+	peers   int
+	mempool int
+}
+
+func (f *fakeNode) Status() (*ctypes.ResultStatus, error) {
+	if f.down {
+		return nil, fmt.Errorf("connection refused")
+	}
+	return &ctypes.ResultStatus{LatestBlockHeight: f.height, LatestBlockHash: f.hashes[f.height]}, nil
+}
+
+func (f *fakeNode) DumpConsensusState() (*ctypes.ResultDumpConsensusState, error) {
+	return &ctypes.ResultDumpConsensusState{RoundState: fmt.Sprintf("RoundState{\n  H:%d R:0 S:RoundStepPropose\n}", f.height+1)}, nil
+}
+
+func (f *fakeNode) NetInfo() (*ctypes.ResultNetInfo, error) {
+	return &ctypes.ResultNetInfo{Peers: make([]ctypes.Peer, f.peers)}, nil
+}
+
+func (f *fakeNode) ListUnconfirmedTxs() (*ctypes.ResultListUnconfirmedTxs, error) {
+	return &ctypes.ResultListUnconfirmedTxs{Txs: make([]types.Tx, f.mempool)}, nil
+}
+
+func (f *fakeNode) BlockchainInfo(min, max int) (*ctypes.ResultBlockchainInfo, error) {
+	return &ctypes.ResultBlockchainInfo{BlockMetas: []*types.BlockMeta{{Hash: f.hashes[min]}}}, nil
+}
+
+type recordAlerter struct {
+	alerts []Alert
+}
+
+func (r *recordAlerter) Alert(a Alert) error {
+	r.alerts = append(r.alerts, a)
+	return nil
+}
+
+func (r *recordAlerter) pop() []string {
+	var s []string
+	for _, a := range r.alerts {
+		s = append(s, fmt.Sprintf("%s/%s/%v", a.Kind, a.Node, a.Resolved))
+	}
+	r.alerts = nil
+	return s
+}
+
+func TestMonitor(t *testing.T) {
+	a := &fakeNode{height: 5, peers: 2, hashes: map[int][]byte{5: {0x1}, 6: {0x2}}}
+	b := &fakeNode{height: 6, peers: 2, hashes: map[int][]byte{5: {0x1}, 6: {0x2}}}
+	rec := new(recordAlerter)
+	m := NewMonitor(MonitorConfig{StallTimeout: 10 * time.Second, MinPeers: 1, MempoolGrowth: 2}, []Alerter{rec})
+	m.AddNode("a", a)
+	m.AddNode("b", b)
+
+	now := time.Now()
+	check := func(expected string) {
+		m.Poll(now)
+		if got := fmt.Sprint(rec.pop()); got != expected {
+			t.Fatalf("got alerts %s, expected %s", got, expected)
+		}
+		now = now.Add(5 * time.Second)
+	}
+
+	check("[]")
+
+	// a stalls, b's mempool grows and it loses its peers
+	b.height, b.mempool, b.peers = 7, 1, 0
+	check("[peers/b/false]")
+	b.height, b.mempool = 8, 2
+	check("[mempool/b/false]")
+	b.height, b.mempool = 9, 3
+	check("[stalled/a/false]")
+
+	// a recovers on a fork
+	a.height, a.hashes[6] = 6, []byte{0x3}
+	b.peers, b.mempool = 3, 0
+	check("[stalled/a/true peers/b/true mempool/b/true divergence/all/false]")
+
+	a.down = true
+	check("[unreachable/a/false]")
+}
+
+// blockAlerter hangs until it's closed
+type blockAlerter chan struct{}
+
+func (b blockAlerter) Alert(a Alert) error {
+	<-b
+	return nil
+}
+
+func TestSlowAlerts(t *testing.T) {
+	hang := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hang
+	}))
+	// Close waits for the handlers
+	defer ts.Close()
+	defer close(hang)
+
+	start := time.Now()
+	if err := NewWebhookAlerter(ts.URL, 100*time.Millisecond).Alert(Alert{Kind: "stalled", Node: "a"}); err == nil {
+		t.Fatal("expected the webhook to time out")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("webhook took %v to time out", d)
+	}
+
+	// queued alerts don't wait on the alerter
+	block := make(blockAlerter)
+	defer close(block)
+	async := NewAsyncAlerter(block)
+	for i := 0; i < alertQueueDepth; i++ {
+		if err := async.Alert(Alert{Kind: "stalled", Node: "a"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// one may be in flight, the rest fill the queue
+	async.Alert(Alert{Kind: "stalled", Node: "a"})
+	if err := async.Alert(Alert{Kind: "stalled", Node: "a"}); err == nil {
+		t.Fatal("expected an error once the queue is full")
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/eris-ltd/mint-client/mintx/core"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/codegangsta/cli"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------
+// filtering name reg entries
+
+type nameFilter struct {
+	prefix string
+	re     *regexp.Regexp
+	owner  []byte
+
+	// only unexpired entries expiring within this many blocks. Unset if negative
+	expiresWithin int
+}
+
+// the filter from the names flags, or nil if none are set
+func newNameFilter(c *cli.Context) (*nameFilter, error) {
+	if !c.IsSet("prefix") && !c.IsSet("regex") && !c.IsSet("owner") && !c.IsSet("expires-within") {
+		return nil, nil
+	}
+	f := &nameFilter{prefix: c.String("prefix"), expiresWithin: -1}
+	if c.IsSet("regex") {
+		re, err := regexp.Compile(c.String("regex"))
+		if err != nil {
+			return nil, fmt.Errorf("bad regex: %v", err)
+		}
+		f.re = re
+	}
+	if c.IsSet("owner") {
+		owner, err := hex.DecodeString(c.String("owner"))
+		if err != nil {
+			return nil, fmt.Errorf("owner is bad hex: %v", err)
+		}
+		f.owner = owner
+	}
+	if c.IsSet("expires-within") {
+		if f.expiresWithin = c.Int("expires-within"); f.expiresWithin < 0 {
+			return nil, fmt.Errorf("expires-within must not be negative")
+		}
+	}
+	return f, nil
+}
+
+// the matching entries. When filtering on expiry they're sorted soonest first
+func (f *nameFilter) filter(entries []*types.NameRegEntry, height int) []*types.NameRegEntry {
+	matched := []*types.NameRegEntry{}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name, f.prefix) {
+			continue
+		}
+		if f.re != nil && !f.re.MatchString(entry.Name) {
+			continue
+		}
+		if f.owner != nil && !bytes.Equal(entry.Owner, f.owner) {
+			continue
+		}
+		if f.expiresWithin >= 0 && (entry.Expires <= height || entry.Expires > height+f.expiresWithin) {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+	if f.expiresWithin >= 0 {
+		sort.Sort(byExpiry(matched))
+	}
+	return matched
+}
+
+type byExpiry []*types.NameRegEntry
+
+func (e byExpiry) Len() int           { return len(e) }
+func (e byExpiry) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e byExpiry) Less(i, j int) bool { return e[i].Expires < e[j].Expires }
+
+//------------------------------------------------------------------------------
+// printing name reg data against a schema
+
+// print each entry's data field by field, with the schema's descriptions,
+// or why it doesn't match the schema
+func printNameData(out io.Writer, entries []*types.NameRegEntry, schema *core.Schema) {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s (expires %d)\n", entry.Name, entry.Expires)
+		var v interface{}
+		if err := json.Unmarshal([]byte(entry.Data), &v); err != nil {
+			fmt.Fprintf(w, "  invalid: data is not valid json: %v\n", err)
+			continue
+		}
+		if err := schema.Validate(v); err != nil {
+			fmt.Fprintf(w, "  invalid: %v\n", err)
+			continue
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			b, _ := json.Marshal(v)
+			fmt.Fprintf(w, "  %s\n", b)
+			continue
+		}
+		for _, key := range schema.PropertyNames(m) {
+			b, _ := json.Marshal(m[key])
+			var desc string
+			if prop, ok := schema.Properties[key]; ok {
+				desc = prop.Description
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", key, strings.Trim(string(b), `"`), desc)
+		}
+	}
+	w.Flush()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/eris-ltd/mint-client/mintx/core"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+func TestNameFilter(t *testing.T) {
+	me, them := []byte{0x01}, []byte{0x02}
+	entries := []*types.NameRegEntry{
+		{Name: "casey", Owner: me, Expires: 150},
+		{Name: "magma.interblock.io", Owner: me, Expires: 120},
+		{Name: "www.interblock.io", Owner: them, Expires: 300},
+		{Name: "old.interblock.io", Owner: me, Expires: 90},
+	}
+	names := func(f *nameFilter) (s []string) {
+		for _, entry := range f.filter(entries, 100) {
+			s = append(s, entry.Name)
+		}
+		return
+	}
+	cases := []struct {
+		filter   *nameFilter
+		expected string
+	}{
+		{&nameFilter{prefix: "magma", expiresWithin: -1}, "[magma.interblock.io]"},
+		{&nameFilter{re: regexp.MustCompile(`\.io$`), expiresWithin: -1}, "[magma.interblock.io www.interblock.io old.interblock.io]"},
+		{&nameFilter{owner: them, expiresWithin: -1}, "[www.interblock.io]"},
+		{&nameFilter{expiresWithin: 50}, "[magma.interblock.io casey]"},
+		{&nameFilter{owner: me, re: regexp.MustCompile("interblock"), expiresWithin: 100}, "[magma.interblock.io]"},
+	}
+	for i, c := range cases {
+		if got := names(c.filter); fmt.Sprint(got) != c.expected {
+			t.Fatalf("case %d: got %v, expected %s", i, got, c.expected)
+		}
+	}
+}
+
+func TestPrintNameData(t *testing.T) {
+	schema, err := core.LoadSchema("dns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := []*types.NameRegEntry{
+		{Name: "magma.interblock.io", Data: `{"fqdn":"magma.interblock.io","address":"1.2.3.4","type":"A"}`, Expires: 120},
+		{Name: "casey", Data: "psh", Expires: 150},
+	}
+	buf := new(bytes.Buffer)
+	printNameData(buf, entries, schema)
+	expected := `magma.interblock.io (expires 120)
+  address  1.2.3.4              ip address, hostname or text
+  fqdn     magma.interblock.io  fully qualified domain name
+  type     A                    record type
+casey (expires 150)
+  invalid: data is not valid json: invalid character 'p' looking for beginning of value
+`
+	if buf.String() != expected {
+		t.Fatalf("got:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
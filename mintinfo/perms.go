@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/codegangsta/cli"
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+)
+
+//------------------------------------------------------------------------------
+// permissions
+
+// hasPermission is the effective value of a permission for an account.
+// Like state.HasPermission, permissions without their set bit fall back
+// to the global permissions account, in which case inherited is true.
+func hasPermission(acc, global *acm.Account, perm ptypes.PermFlag) (value, inherited bool) {
+	v, err := acc.Permissions.Base.Get(perm)
+	if _, ok := err.(ptypes.ErrValueNotSet); ok {
+		if global == nil {
+			return false, true
+		}
+		v, _ = global.Permissions.Base.Get(perm)
+		return v, true
+	}
+	return v, false
+}
+
+// the effective value of one permission for an account
+type EffectivePerm struct {
+	Name      string `json:"name"`
+	Flag      uint64 `json:"flag"`
+	Value     bool   `json:"value"`
+	Inherited bool   `json:"inherited"` // from the global permissions account
+}
+
+type AccountPerms struct {
+	Address []byte          `json:"address"`
+	Exists  bool            `json:"exists"`
+	Perms   uint64          `json:"perms"`
+	SetBit  uint64          `json:"set_bit"`
+	Roles   []string        `json:"roles"`
+	Base    []EffectivePerm `json:"base"`
+}
+
+// every permission for addr, as the chain would see it.
+// An account that doesn't exist yet gets everything from global
+func coreAccountPerms(addr []byte, acc, global *acm.Account) *AccountPerms {
+	ap := &AccountPerms{Address: addr, Exists: acc != nil, Roles: []string{}}
+	if acc == nil {
+		acc = &acm.Account{Address: addr, Permissions: ptypes.ZeroAccountPermissions}
+	}
+	ap.Perms, ap.SetBit = uint64(acc.Permissions.Base.Perms), uint64(acc.Permissions.Base.SetBit)
+	// roles are stored left padded to a word
+	for _, r := range acc.Permissions.Roles {
+		ap.Roles = append(ap.Roles, strings.TrimLeft(r, "\x00"))
+	}
+	for i := uint(0); i < ptypes.NumPermissions; i++ {
+		pf := ptypes.PermFlag(1 << i)
+		v, inherited := hasPermission(acc, global, pf)
+		ap.Base = append(ap.Base, EffectivePerm{
+			Name:      ptypes.PermFlagToString(pf),
+			Flag:      uint64(pf),
+			Value:     v,
+			Inherited: inherited,
+		})
+	}
+	return ap
+}
+
+func cliPerms(c *cli.Context) {
+	args := c.Args()
+	if len(args) == 0 {
+		exit(fmt.Errorf("must specify the address of an account"))
+	}
+	addr := args[0]
+	addrBytes, err := hex.DecodeString(addr)
+	if err != nil {
+		exit(fmt.Errorf("Addr %s is improper hex: %v", addr, err))
+	}
+	acc, err := getAccount(addrBytes)
+	ifExit(err)
+	global, err := getAccount(ptypes.GlobalPermissionsAddress)
+	ifExit(err)
+
+	ap := coreAccountPerms(addrBytes, acc, global)
+	if c.Bool("json") {
+		s, err := prettyPrint(ap)
+		ifExit(err)
+		fmt.Println(s)
+		return
+	}
+	printAccountPerms(os.Stdout, ap)
+}
+
+func printAccountPerms(out io.Writer, ap *AccountPerms) {
+	if ap.Exists {
+		fmt.Fprintf(out, "%X (perms %d, set bit %d)\n", ap.Address, ap.Perms, ap.SetBit)
+	} else {
+		fmt.Fprintf(out, "%X does not exist. It would get the global permissions\n", ap.Address)
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	for _, p := range ap.Base {
+		from := "account"
+		if p.Inherited {
+			from = "global"
+		}
+		fmt.Fprintf(w, "  %s\t%v\t%s\n", p.Name, p.Value, from)
+	}
+	w.Flush()
+	if len(ap.Roles) == 0 {
+		fmt.Fprintln(out, "roles: none")
+	} else {
+		fmt.Fprintf(out, "roles: %s\n", strings.Join(ap.Roles, ", "))
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+)
+
+func TestAccountPerms(t *testing.T) {
+	global := &acm.Account{Permissions: ptypes.DefaultAccountPermissions}
+	acc := &acm.Account{Address: []byte("alice"), Permissions: ptypes.ZeroAccountPermissions}
+	acc.Permissions.Base.Set(ptypes.Call, false)
+	acc.Permissions.Base.Set(ptypes.Root, true)
+	acc.Permissions.AddRole("admin")
+
+	ap := coreAccountPerms(acc.Address, acc, global)
+	if len(ap.Base) != int(ptypes.NumPermissions) || !ap.Exists {
+		t.Fatalf("bad perms %v", ap)
+	}
+	perms := make(map[string]EffectivePerm)
+	for _, p := range ap.Base {
+		perms[p.Name] = p
+	}
+	if p := perms["call"]; p.Value || p.Inherited {
+		t.Fatalf("expected call to be unset on the account, got %v", p)
+	}
+	if p := perms["root"]; !p.Value || p.Inherited {
+		t.Fatalf("expected root on the account, got %v", p)
+	}
+	if p := perms["send"]; !p.Value || !p.Inherited {
+		t.Fatalf("expected send from global, got %v", p)
+	}
+	if p := perms["set_base"]; p.Value || !p.Inherited {
+		t.Fatalf("expected no set_base from global, got %v", p)
+	}
+
+	buf := new(bytes.Buffer)
+	printAccountPerms(buf, ap)
+	if !strings.Contains(buf.String(), "roles: admin") {
+		t.Fatalf("expected roles in output:\n%s", buf)
+	}
+
+	// an account that doesn't exist gets the global permissions
+	ap = coreAccountPerms([]byte("bob"), nil, global)
+	for _, p := range ap.Base {
+		if v, _ := global.Permissions.Base.Get(ptypes.PermFlag(p.Flag)); p.Value != v || !p.Inherited {
+			t.Fatalf("expected %s from global, got %v", p.Name, p)
+		}
+	}
+}
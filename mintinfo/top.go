@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/codegangsta/cli"
+	ctypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core/types"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------
+// live dashboard
+
+const clearScreen = "\033[H\033[2J"
+
+// what we fetched from the node for one refresh of the dashboard
+type topSnapshot struct {
+	status      *ctypes.ResultStatus
+	netInfo     *ctypes.ResultNetInfo
+	peerHeights []int
+	validators  *ctypes.ResultListValidators
+	mempool     []types.Tx
+	blocks      *ctypes.ResultBlockchainInfo // nil before the first block
+
+	selected *types.Block
+	err      error
+}
+
+type TopPeer struct {
+	Moniker string
+	Addr    string
+	Version string
+	Height  int // 0 if we couldn't tell
+}
+
+type TopValidator struct {
+	Address          []byte
+	VotingPower      int64
+	Share            float64 // percent of the bonded voting power
+	LastCommitHeight int
+}
+
+type TopTx struct {
+	ID      []byte
+	Summary string
+}
+
+type TopBlock struct {
+	Height int
+	Time   time.Time
+	NumTxs int
+	Hash   []byte
+}
+
+type TopReport struct {
+	ChainID   string
+	Moniker   string
+	Height    int
+	BlockTime time.Time
+
+	// over the recent blocks
+	BlockInterval time.Duration
+	Throughput    float64 // tx/s
+
+	Peers      []TopPeer
+	Validators []TopValidator
+	Unbonding  int
+	Mempool    []TopTx
+	Blocks     []TopBlock // newest first
+
+	// the block picked to look at, with a summary of each of its txs
+	Selected    *TopBlock
+	SelectedTxs []string
+}
+
+func cliTop(c *cli.Context) {
+	n := c.Int("blocks")
+	interval := c.Duration("interval")
+
+	// read block heights to inspect off stdin
+	selectCh := make(chan int)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "q" {
+				os.Exit(0)
+			}
+			height, _ := strconv.Atoi(line)
+			selectCh <- height
+		}
+	}()
+
+	var selected int
+	ticker := time.NewTicker(interval)
+	for {
+		snap := fetchTop(client, n, selected)
+		fmt.Print(clearScreen)
+		if snap.err != nil {
+			fmt.Printf("Error fetching from node: %v\n", snap.err)
+		} else {
+			renderTop(os.Stdout, coreTop(snap))
+		}
+		fmt.Print("\nEnter a block height to see its txs, an empty line to go back, or q to quit: ")
+		select {
+		case <-ticker.C:
+		case selected = <-selectCh:
+		}
+	}
+}
+
+func fetchTop(client cclient.Client, n, selected int) *topSnapshot {
+	snap := new(topSnapshot)
+	if snap.status, snap.err = client.Status(); snap.err != nil {
+		return snap
+	}
+	if snap.netInfo, snap.err = client.NetInfo(); snap.err != nil {
+		return snap
+	}
+	if cs, err := client.DumpConsensusState(); err == nil {
+		snap.peerHeights = parsePeerHeights(cs.PeerRoundStates)
+	}
+	if snap.validators, snap.err = client.ListValidators(); snap.err != nil {
+		return snap
+	}
+	txs, err := client.ListUnconfirmedTxs()
+	if snap.err = err; err != nil {
+		return snap
+	}
+	snap.mempool = txs.Txs
+
+	last := snap.status.LatestBlockHeight
+	first := last - n + 1
+	if first < 1 {
+		first = 1
+	}
+	if last >= 1 {
+		if snap.blocks, snap.err = client.BlockchainInfo(first, last); snap.err != nil {
+			return snap
+		}
+	}
+
+	if selected > 0 {
+		r, err := client.GetBlock(selected)
+		if snap.err = err; err != nil {
+			return snap
+		}
+		snap.selected = r.Block
+	}
+	return snap
+}
+
+var peerHeightRegexp = regexp.MustCompile(`(?:"height"|Height|H):(\d+)`)
+
+// best effort at pulling peer heights out of the dumped peer round states,
+// which are listed in the same order as the peers in net_info
+func parsePeerHeights(prss []string) []int {
+	heights := make([]int, len(prss))
+	for i, prs := range prss {
+		if match := peerHeightRegexp.FindStringSubmatch(prs); match != nil {
+			heights[i], _ = strconv.Atoi(match[1])
+		}
+	}
+	return heights
+}
+
+// average time between blocks and txs per second over the recent blocks
+func blockRates(blocks []*types.BlockMeta) (interval time.Duration, tps float64) {
+	if len(blocks) < 2 {
+		return
+	}
+	newest, oldest := blocks[0].Header, blocks[len(blocks)-1].Header
+	span := newest.Time.Sub(oldest.Time)
+	if span <= 0 {
+		return
+	}
+	var txs int
+	for _, b := range blocks[:len(blocks)-1] {
+		txs += b.Header.NumTxs
+	}
+	return span / time.Duration(len(blocks)-1), float64(txs) / span.Seconds()
+}
+
+func coreTop(snap *topSnapshot) *TopReport {
+	status := snap.status
+	chainID := status.NodeInfo.ChainID
+	report := &TopReport{
+		ChainID:   chainID,
+		Moniker:   status.NodeInfo.Moniker,
+		Height:    status.LatestBlockHeight,
+		BlockTime: time.Unix(0, status.LatestBlockTime),
+		Unbonding: len(snap.validators.UnbondingValidators),
+	}
+
+	if snap.blocks != nil {
+		report.BlockInterval, report.Throughput = blockRates(snap.blocks.BlockMetas)
+		for _, b := range snap.blocks.BlockMetas {
+			report.Blocks = append(report.Blocks, TopBlock{b.Header.Height, b.Header.Time, b.Header.NumTxs, b.Hash})
+		}
+	}
+
+	for i, peer := range snap.netInfo.Peers {
+		p := TopPeer{Moniker: peer.Moniker, Addr: fmt.Sprintf("%s:%d", peer.Host, peer.P2PPort), Version: peer.Version}
+		if i < len(snap.peerHeights) {
+			p.Height = snap.peerHeights[i]
+		}
+		report.Peers = append(report.Peers, p)
+	}
+
+	total := votingPower(snap.validators.BondedValidators)
+	for _, v := range snap.validators.BondedValidators {
+		val := TopValidator{Address: v.Address, VotingPower: v.VotingPower, LastCommitHeight: v.LastCommitHeight}
+		if total > 0 {
+			val.Share = 100 * float64(v.VotingPower) / float64(total)
+		}
+		report.Validators = append(report.Validators, val)
+	}
+
+	for _, tx := range snap.mempool {
+		report.Mempool = append(report.Mempool, TopTx{types.TxID(chainID, tx), txSummary(tx)})
+	}
+
+	if block := snap.selected; block != nil {
+		report.Selected = &TopBlock{block.Height, block.Time, len(block.Txs), block.Hash()}
+		for _, tx := range block.Txs {
+			report.SelectedTxs = append(report.SelectedTxs, txSummary(tx))
+		}
+	}
+	return report
+}
+
+func renderTop(out io.Writer, report *TopReport) {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "Chain: %s\tNode: %s\n", report.ChainID, report.Moniker)
+	fmt.Fprintf(w, "Height: %d\tBlock time: %v\n", report.Height, report.BlockTime.Format(time.RFC3339))
+	fmt.Fprintf(w, "Block interval: %v\tThroughput: %.2f tx/s\n", report.BlockInterval, report.Throughput)
+
+	if block := report.Selected; block != nil {
+		fmt.Fprintf(w, "\nBLOCK %d (%X) - %d txs\n", block.Height, block.Hash, block.NumTxs)
+		for i, tx := range report.SelectedTxs {
+			fmt.Fprintf(w, "%d\t%s\n", i, tx)
+		}
+		return
+	}
+
+	fmt.Fprintf(w, "\nPEERS (%d)\n", len(report.Peers))
+	fmt.Fprintln(w, "MONIKER\tHOST\tVERSION\tHEIGHT")
+	for _, peer := range report.Peers {
+		height := "-"
+		if peer.Height > 0 {
+			height = strconv.Itoa(peer.Height)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", peer.Moniker, peer.Addr, peer.Version, height)
+	}
+
+	fmt.Fprintf(w, "\nVALIDATORS (%d bonded, %d unbonding)\n", len(report.Validators), report.Unbonding)
+	fmt.Fprintln(w, "ADDRESS\tVOTING POWER\tSHARE\tLAST COMMIT")
+	for _, v := range report.Validators {
+		fmt.Fprintf(w, "%X\t%d\t%.2f%%\t%d\n", v.Address, v.VotingPower, v.Share, v.LastCommitHeight)
+	}
+
+	fmt.Fprintf(w, "\nMEMPOOL (%d txs)\n", len(report.Mempool))
+	for _, tx := range report.Mempool {
+		fmt.Fprintf(w, "%X\t%s\n", tx.ID, tx.Summary)
+	}
+
+	fmt.Fprintf(w, "\nRECENT BLOCKS\n")
+	fmt.Fprintln(w, "HEIGHT\tTIME\tTXS\tHASH")
+	for _, b := range report.Blocks {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%X\n", b.Height, b.Time.Format("15:04:05"), b.NumTxs, b.Hash)
+	}
+}
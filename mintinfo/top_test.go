@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	ctypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+func testTopSnapshot() *topSnapshot {
+	start := time.Date(2015, 10, 1, 0, 0, 0, 0, time.UTC)
+	meta := func(height, txs int, t time.Duration) *types.BlockMeta {
+		return &types.BlockMeta{Hash: []byte{byte(height)}, Header: &types.Header{Height: height, Time: start.Add(t), NumTxs: txs}}
+	}
+	from := []byte{0xaa}
+	send := types.NewSendTx()
+	send.Inputs = []*types.TxInput{{Address: from, Amount: 5}}
+	send.Outputs = []*types.TxOutput{{Address: []byte{0xbb}, Amount: 5}}
+	call := &types.CallTx{Input: &types.TxInput{Address: from, Amount: 1}, Data: []byte{1, 2, 3}, GasLimit: 100, Fee: 2}
+
+	return &topSnapshot{
+		status: &ctypes.ResultStatus{
+			NodeInfo:          &types.NodeInfo{ChainID: "top_chain", Moniker: "node0"},
+			LatestBlockHeight: 12,
+			LatestBlockTime:   start.Add(20 * time.Second).UnixNano(),
+		},
+		netInfo: &ctypes.ResultNetInfo{Peers: []ctypes.Peer{
+			{NodeInfo: types.NodeInfo{Moniker: "peer0", Host: "1.2.3.4", P2PPort: 46656, Version: "0.1"}},
+			{NodeInfo: types.NodeInfo{Moniker: "peer1", Host: "5.6.7.8", P2PPort: 46656, Version: "0.1"}},
+		}},
+		peerHeights: parsePeerHeights([]string{`PeerRoundState{H:11 R:0}`, `garbage`}),
+		validators: &ctypes.ResultListValidators{
+			BondedValidators: []*types.Validator{
+				{Address: []byte{1}, VotingPower: 30, LastCommitHeight: 11},
+				{Address: []byte{2}, VotingPower: 10, LastCommitHeight: 10},
+			},
+			UnbondingValidators: []*types.Validator{{Address: []byte{3}}},
+		},
+		mempool: []types.Tx{send},
+		// newest first. The oldest block's txs came before the span
+		blocks: &ctypes.ResultBlockchainInfo{LastHeight: 12, BlockMetas: []*types.BlockMeta{
+			meta(12, 4, 20*time.Second),
+			meta(11, 6, 10*time.Second),
+			meta(10, 100, 0),
+		}},
+		selected: &types.Block{Header: &types.Header{Height: 11}, Data: &types.Data{Txs: []types.Tx{call}}},
+	}
+}
+
+func TestTop(t *testing.T) {
+	report := coreTop(testTopSnapshot())
+
+	if report.ChainID != "top_chain" || report.Height != 12 || report.BlockTime.Unix() != time.Date(2015, 10, 1, 0, 0, 20, 0, time.UTC).Unix() {
+		t.Fatalf("bad status %s %d %v", report.ChainID, report.Height, report.BlockTime)
+	}
+	if report.BlockInterval != 10*time.Second || report.Throughput != 0.5 {
+		t.Fatalf("expected a 10s interval at 0.5 tx/s, got %v at %v", report.BlockInterval, report.Throughput)
+	}
+	if len(report.Blocks) != 3 || report.Blocks[0].Height != 12 || report.Blocks[2].NumTxs != 100 {
+		t.Fatalf("bad blocks %v", report.Blocks)
+	}
+	if len(report.Peers) != 2 || report.Peers[0].Addr != "1.2.3.4:46656" || report.Peers[0].Height != 11 || report.Peers[1].Height != 0 {
+		t.Fatalf("bad peers %v", report.Peers)
+	}
+	if len(report.Validators) != 2 || report.Validators[0].Share != 75 || report.Validators[1].Share != 25 || report.Unbonding != 1 {
+		t.Fatalf("bad validators %v", report.Validators)
+	}
+	if len(report.Mempool) != 1 || len(report.Mempool[0].ID) == 0 || report.Mempool[0].Summary != "SendTx from AA to BB amt 5" {
+		t.Fatalf("bad mempool %v", report.Mempool)
+	}
+	if report.Selected == nil || report.Selected.Height != 11 || report.Selected.NumTxs != 1 ||
+		report.SelectedTxs[0] != "CallTx from AA creating contract (3 bytes) amt 1 gas 100 fee 2" {
+		t.Fatalf("bad selected block %v %v", report.Selected, report.SelectedTxs)
+	}
+
+	buf := new(bytes.Buffer)
+	renderTop(buf, report)
+	if out := buf.String(); !strings.Contains(out, "BLOCK 11") || !strings.Contains(out, "CallTx from AA") {
+		t.Fatalf("bad render of the selected block:\n%s", out)
+	}
+
+	// no voting power, no blocks yet
+	snap := testTopSnapshot()
+	snap.validators.BondedValidators = []*types.Validator{{Address: []byte{1}}}
+	snap.blocks = nil
+	snap.selected = nil
+	report = coreTop(snap)
+	if report.Validators[0].Share != 0 || report.BlockInterval != 0 || report.Throughput != 0 || len(report.Blocks) != 0 {
+		t.Fatalf("bad empty report %v", report)
+	}
+	buf.Reset()
+	renderTop(buf, report)
+	if out := buf.String(); !strings.Contains(out, "0.00%") || !strings.Contains(out, "PEERS (2)") || strings.Contains(out, "NaN") {
+		t.Fatalf("bad render:\n%s", out)
+	}
+}
+
+func TestTxSummary(t *testing.T) {
+	in := &types.TxInput{Address: []byte{0xaa}, Amount: 7}
+	summaries := []struct {
+		tx      types.Tx
+		summary string
+	}{
+		{&types.CallTx{Input: in, Address: []byte{0xcc}, Data: []byte{0xde}, GasLimit: 10, Fee: 1}, "CallTx from AA to CC data DE amt 7 gas 10 fee 1"},
+		{&types.NameTx{Input: in, Name: "mint", Fee: 1}, "NameTx from AA name mint amt 7 fee 1"},
+		{&types.PermissionsTx{Input: in, PermArgs: &ptypes.SetBaseArgs{Address: []byte{0xbb}, Permission: ptypes.Bond, Value: true}}, "PermissionsTx from AA set_base BB bond true"},
+		{&types.PermissionsTx{Input: in, PermArgs: &ptypes.AddRoleArgs{Address: []byte{0xbb}, Role: "ops"}}, "PermissionsTx from AA add_role BB ops"},
+		{&types.UnbondTx{Address: []byte{0xdd}, Height: 3}, "UnbondTx validator DD height 3"},
+		// txs without inputs are invalid, but mustn't crash the dashboard
+		{&types.SendTx{}, "SendTx from - to - amt 0"},
+		{&types.BondTx{}, "BondTx pubkey " + strings.Repeat("00", 32) + " amt -"},
+	}
+	for _, s := range summaries {
+		if summary := txSummary(s.tx); summary != s.summary {
+			t.Fatalf("expected %q, got %q", s.summary, summary)
+		}
+	}
+	if txInput(&types.SendTx{}) != nil || txInput(&types.NameTx{Input: in}) != in {
+		t.Fatal("bad tx inputs")
+	}
+}
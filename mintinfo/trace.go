@@ -0,0 +1,504 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/codegangsta/cli"
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/common"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/vm"
+)
+
+//------------------------------------------------------------------------------
+// local evm execution tracer
+
+// gas limits used by the node's rpc call
+const (
+	callGas      = 1000000000
+	callGasLimit = 10000000
+)
+
+type StorageAccess struct {
+	Address []byte `json:"address"`
+	Key     []byte `json:"key"`
+	Value   []byte `json:"value"`
+	Write   bool   `json:"write"`
+}
+
+type MemoryDelta struct {
+	Offset int    `json:"offset"`
+	Data   []byte `json:"data"`
+}
+
+type TraceStep struct {
+	Depth   int             `json:"depth"`
+	PC      int64           `json:"pc"`
+	Op      string          `json:"op"`
+	Gas     int64           `json:"gas"`
+	Stack   [][]byte        `json:"stack"`   // top of the stack last
+	Memory  []MemoryDelta   `json:"memory"`  // changes made by this instruction
+	Storage []StorageAccess `json:"storage"` // reads and writes made by this instruction
+}
+
+type Trace struct {
+	Steps   []*TraceStep `json:"steps"`
+	Return  []byte       `json:"return"`
+	GasUsed int64        `json:"gas_used"`
+	Error   string       `json:"error"`
+}
+
+func cliTraceCall(c *cli.Context) {
+	args := c.Args()
+	if len(args) < 3 {
+		exit(fmt.Errorf("must specify a from address, to address and data to send"))
+	}
+	from, to, data := args[0], args[1], args[2]
+	fromAddrBytes, err := hex.DecodeString(from)
+	ifExit(err)
+	toAddrBytes, err := hex.DecodeString(to)
+	ifExit(err)
+	dataBytes, err := hex.DecodeString(data)
+	ifExit(err)
+
+	params, err := vmParams()
+	ifExit(err)
+	appState := newNodeAppState(getAccount, getStorage)
+	callee := appState.GetAccount(common.LeftPadWord256(toAddrBytes))
+	ifExit(appState.err)
+	if callee == nil {
+		exit(fmt.Errorf("Account %X does not exist", toAddrBytes))
+	}
+	caller := &vm.Account{Address: common.LeftPadWord256(fromAddrBytes)}
+
+	trace, err := coreTrace(appState, params, caller, callee, callee.Code, dataBytes, 0, callGas)
+	ifExit(err)
+	printTrace(c, trace)
+}
+
+func cliTraceTx(c *cli.Context) {
+	args := c.Args()
+	if len(args) == 0 {
+		exit(fmt.Errorf("must specify the hash of a tx"))
+	}
+	hash, err := hex.DecodeString(args[0])
+	ifExit(err)
+
+	status, err := client.Status()
+	ifExit(err)
+	tx, height, err := findTx(status.NodeInfo.ChainID, hash, status.LatestBlockHeight, c.Int("blocks"))
+	ifExit(err)
+	// the node only serves the latest state, which already has the tx's changes
+	if height > 0 {
+		exit(fmt.Errorf("Tx %X was committed in block %d, and only txs still in the mempool can be traced. Use trace call to run the call against the latest state", hash, height))
+	}
+	callTx, ok := tx.(*types.CallTx)
+	if !ok {
+		exit(fmt.Errorf("Tx %X is not a CallTx: %s", hash, txSummary(tx)))
+	}
+
+	params, err := vmParams()
+	ifExit(err)
+	appState := newNodeAppState(getAccount, getStorage)
+	caller, callee, code, err := callTxAccounts(appState, callTx)
+	ifExit(err)
+
+	trace, err := coreTrace(appState, params, caller, callee, code, callTx.Data, callTx.Input.Amount-callTx.Fee, callTx.GasLimit)
+	ifExit(err)
+	printTrace(c, trace)
+}
+
+func getStorage(addr, key []byte) ([]byte, error) {
+	r, err := client.GetStorage(addr, key)
+	if err != nil {
+		return nil, err
+	}
+	return r.Value, nil
+}
+
+// params for a call on top of the latest block
+func vmParams() (vm.Params, error) {
+	status, err := client.Status()
+	if err != nil {
+		return vm.Params{}, err
+	}
+	return vm.Params{
+		BlockHeight: int64(status.LatestBlockHeight),
+		BlockHash:   common.LeftPadWord256(status.LatestBlockHash),
+		BlockTime:   time.Unix(0, status.LatestBlockTime).Unix(),
+		GasLimit:    callGasLimit,
+	}, nil
+}
+
+// look for a tx in the mempool and then in the most recent blocks.
+// The height is 0 for txs in the mempool
+func findTx(chainID string, hash []byte, height, blocks int) (types.Tx, int, error) {
+	r, err := client.ListUnconfirmedTxs()
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, tx := range r.Txs {
+		if bytes.Equal(types.TxID(chainID, tx), hash) {
+			return tx, 0, nil
+		}
+	}
+	for h := height; h > 0 && h > height-blocks; h-- {
+		b, err := client.GetBlock(h)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, tx := range b.Block.Txs {
+			if bytes.Equal(types.TxID(chainID, tx), hash) {
+				return tx, h, nil
+			}
+		}
+	}
+	return nil, 0, fmt.Errorf("Tx %X not found in the mempool or the last %d blocks", hash, blocks)
+}
+
+// set up the caller and callee the way the state does when executing a CallTx
+func callTxAccounts(appState *nodeAppState, tx *types.CallTx) (caller, callee *vm.Account, code []byte, err error) {
+	caller = appState.GetAccount(common.LeftPadWord256(tx.Input.Address))
+	if appState.err != nil {
+		return nil, nil, nil, appState.err
+	}
+	if caller == nil {
+		return nil, nil, nil, fmt.Errorf("Input account %X does not exist", tx.Input.Address)
+	}
+	// eg. an earlier tx from the account is still in the mempool
+	if int64(tx.Input.Sequence) != caller.Nonce+1 {
+		return nil, nil, nil, fmt.Errorf("Tx has nonce %d, but input account %X is at nonce %d, so it wouldn't run against the latest state", tx.Input.Sequence, tx.Input.Address, caller.Nonce)
+	}
+	caller.Nonce += 1
+	caller.Balance -= tx.Fee
+
+	if len(tx.Address) == 0 {
+		callee = appState.CreateAccount(caller)
+		return caller, callee, tx.Data, appState.err
+	}
+	callee = appState.GetAccount(common.LeftPadWord256(tx.Address))
+	if appState.err != nil {
+		return nil, nil, nil, appState.err
+	}
+	if callee == nil || len(callee.Code) == 0 {
+		return nil, nil, nil, fmt.Errorf("Account %X has no code", tx.Address)
+	}
+	return caller, callee, callee.Code, nil
+}
+
+// run the call through the vm, recording every instruction
+func coreTrace(appState *nodeAppState, params vm.Params, caller, callee *vm.Account, code, data []byte, value, gas int64) (*Trace, error) {
+	trace := new(Trace)
+	t := &tracer{
+		trace:    trace,
+		memory:   make(map[int]*frameMemory),
+		lastStep: make(map[int]*TraceStep),
+	}
+	appState.onStorage = t.storage
+
+	appState.UpdateAccount(caller)
+	appState.UpdateAccount(callee)
+	vmach := vm.NewVM(appState, params, caller.Address, nil)
+	vmach.SetTracer(t)
+
+	startGas := gas
+	ret, err := vmach.Call(caller, callee, code, data, value, &gas)
+	if appState.err != nil {
+		return nil, appState.err
+	}
+	trace.Return, trace.GasUsed = ret, startGas-gas
+	if err != nil {
+		trace.Error = err.Error()
+	}
+	return trace, nil
+}
+
+// the vm prints its own debug output, which would garble ours
+func init() {
+	vm.SetDebug(false)
+}
+
+//------------------------------------------------------------------------------
+// tracer
+
+type tracer struct {
+	trace *Trace
+	depth int
+
+	// each call frame gets fresh memory, so we track the last
+	// instruction and the memory we last saw at each depth
+	memory   map[int]*frameMemory
+	lastStep map[int]*TraceStep
+}
+
+// the vm gives every frame 1MB of memory, so we only
+// compare it up to the furthest any instruction has written
+type frameMemory struct {
+	seen []byte // the memory up to the mark when we last looked
+	mark int
+}
+
+func (t *tracer) Step(depth int, pc int64, op vm.OpCode, gas int64, stack []common.Word256, memory []byte) {
+	if depth > t.depth {
+		delete(t.memory, depth)
+		delete(t.lastStep, depth)
+	}
+	t.depth = depth
+
+	// attribute changes to memory to the last instruction in this frame
+	if deltas := t.memoryDeltas(depth, op, stack, memory); len(deltas) > 0 {
+		if last := t.lastStep[depth]; last != nil {
+			last.Memory = append(last.Memory, deltas...)
+		}
+	}
+
+	step := &TraceStep{
+		Depth: depth,
+		PC:    pc,
+		Op:    op.String(),
+		Gas:   gas,
+		Stack: make([][]byte, len(stack)),
+	}
+	for i, w := range stack {
+		step.Stack[i] = w.Bytes()
+	}
+	t.trace.Steps = append(t.trace.Steps, step)
+	t.lastStep[depth] = step
+}
+
+func (t *tracer) storage(access StorageAccess) {
+	if n := len(t.trace.Steps); n > 0 {
+		step := t.trace.Steps[n-1]
+		step.Storage = append(step.Storage, access)
+	}
+}
+
+// the changes since we last looked, then moves the mark past
+// where op, which is about to run, will write
+func (t *tracer) memoryDeltas(depth int, op vm.OpCode, stack []common.Word256, memory []byte) []MemoryDelta {
+	frame, ok := t.memory[depth]
+	if !ok {
+		frame = new(frameMemory)
+		t.memory[depth] = frame
+	}
+	var deltas []MemoryDelta
+	if cur := memory[:frame.mark]; !bytes.Equal(frame.seen, cur) {
+		deltas = diffMemory(frame.seen, cur)
+		copy(frame.seen, cur)
+	}
+	if end := memoryWriteEnd(op, stack, len(memory)); end > frame.mark {
+		// nothing has written past the old mark, so it's still zero there
+		frame.seen = append(frame.seen, make([]byte, end-frame.mark)...)
+		frame.mark = end
+	}
+	return deltas
+}
+
+// the end of the memory op writes to, or 0 if it doesn't.
+// The top of the stack is last
+func memoryWriteEnd(op vm.OpCode, stack []common.Word256, size int) int {
+	arg := func(i int) int64 {
+		if i >= len(stack) {
+			return -1 // the vm will fail on the underflow
+		}
+		return common.Int64FromWord256(stack[len(stack)-1-i])
+	}
+	var offset, length int64
+	switch op {
+	case vm.MSTORE:
+		offset, length = arg(0), 32
+	case vm.MSTORE8:
+		offset, length = arg(0), 1
+	case vm.CALLDATACOPY, vm.CODECOPY:
+		offset, length = arg(0), arg(2)
+	case vm.EXTCODECOPY:
+		offset, length = arg(1), arg(3)
+	case vm.CALL, vm.CALLCODE:
+		offset, length = arg(5), arg(6)
+	default:
+		return 0
+	}
+	if offset < 0 || length <= 0 {
+		return 0
+	}
+	// writes out of bounds fail in the vm
+	if offset >= int64(size) || length > int64(size)-offset {
+		return size
+	}
+	return int(offset + length)
+}
+
+// the contiguous ranges of cur that differ from prev
+func diffMemory(prev, cur []byte) (deltas []MemoryDelta) {
+	for i := 0; i < len(cur); i++ {
+		if cur[i] == prev[i] {
+			continue
+		}
+		j := i
+		for j < len(cur) && cur[j] != prev[j] {
+			j++
+		}
+		deltas = append(deltas, MemoryDelta{Offset: i, Data: append([]byte{}, cur[i:j]...)})
+		i = j
+	}
+	return
+}
+
+//------------------------------------------------------------------------------
+// vm.AppState backed by the node
+
+// Accounts and storage are fetched from the node as the vm asks for them.
+// Updates are kept locally and never leave the process.
+// The vm can't handle errors from the app state, so the first one is saved
+// in err and must be checked once the call is done.
+type nodeAppState struct {
+	getAccount func([]byte) (*acm.Account, error)
+	getStorage func(addr, key []byte) ([]byte, error)
+
+	accounts map[common.Word256]*vm.Account
+	storage  map[common.Tuple256]common.Word256
+
+	onStorage func(StorageAccess)
+	err       error
+}
+
+func newNodeAppState(getAccount func([]byte) (*acm.Account, error), getStorage func(addr, key []byte) ([]byte, error)) *nodeAppState {
+	return &nodeAppState{
+		getAccount: getAccount,
+		getStorage: getStorage,
+		accounts:   make(map[common.Word256]*vm.Account),
+		storage:    make(map[common.Tuple256]common.Word256),
+	}
+}
+
+func (st *nodeAppState) setErr(err error) {
+	if st.err == nil {
+		st.err = err
+	}
+}
+
+func (st *nodeAppState) GetAccount(addr common.Word256) *vm.Account {
+	if acc, ok := st.accounts[addr]; ok {
+		return acc
+	}
+	acc, err := st.getAccount(addr.Postfix(20))
+	if err != nil {
+		st.setErr(err)
+		return nil
+	}
+	var vmAcc *vm.Account
+	if acc != nil {
+		vmAcc = &vm.Account{
+			Address:     addr,
+			Balance:     acc.Balance,
+			Code:        acc.Code,
+			Nonce:       int64(acc.Sequence),
+			Permissions: acc.Permissions,
+		}
+	}
+	st.accounts[addr] = vmAcc
+	return vmAcc
+}
+
+func (st *nodeAppState) UpdateAccount(acc *vm.Account) {
+	st.accounts[acc.Address] = acc
+}
+
+func (st *nodeAppState) RemoveAccount(acc *vm.Account) {
+	st.accounts[acc.Address] = nil
+}
+
+func (st *nodeAppState) CreateAccount(creator *vm.Account) *vm.Account {
+	nonce := creator.Nonce
+	creator.Nonce += 1
+	addr := common.LeftPadWord256(types.NewContractAddress(creator.Address.Postfix(20), int(nonce)))
+
+	var perms ptypes.AccountPermissions
+	if global := st.GetAccount(ptypes.GlobalPermissionsAddress256); global != nil {
+		perms = global.Permissions
+	}
+	acc := &vm.Account{
+		Address:     addr,
+		Permissions: perms,
+	}
+	st.accounts[addr] = acc
+	return acc
+}
+
+func (st *nodeAppState) GetStorage(addr, key common.Word256) common.Word256 {
+	value, ok := st.storage[common.Tuple256{First: addr, Second: key}]
+	if !ok {
+		v, err := st.getStorage(addr.Postfix(20), key.Bytes())
+		if err != nil {
+			st.setErr(err)
+			return common.Zero256
+		}
+		value = common.LeftPadWord256(v)
+		st.storage[common.Tuple256{First: addr, Second: key}] = value
+	}
+	if st.onStorage != nil {
+		st.onStorage(StorageAccess{Address: addr.Postfix(20), Key: key.Bytes(), Value: value.Bytes()})
+	}
+	return value
+}
+
+func (st *nodeAppState) SetStorage(addr, key, value common.Word256) {
+	st.storage[common.Tuple256{First: addr, Second: key}] = value
+	if st.onStorage != nil {
+		st.onStorage(StorageAccess{Address: addr.Postfix(20), Key: key.Bytes(), Value: value.Bytes(), Write: true})
+	}
+}
+
+//------------------------------------------------------------------------------
+// output
+
+func printTrace(c *cli.Context, trace *Trace) {
+	if c.Bool("json") {
+		s, err := prettyPrint(trace)
+		ifExit(err)
+		fmt.Println(s)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	for _, step := range trace.Steps {
+		indent := strings.Repeat("  ", step.Depth-1)
+		stack := make([]string, len(step.Stack))
+		for i, word := range step.Stack {
+			stack[i] = trimWord(word)
+		}
+		fmt.Fprintf(w, "%s%04x\t%s\tgas %d\t[%s]\n", indent, step.PC, step.Op, step.Gas, strings.Join(stack, " "))
+		for _, m := range step.Memory {
+			fmt.Fprintf(w, "%s\tmem\t0x%x\t%X\n", indent, m.Offset, m.Data)
+		}
+		for _, s := range step.Storage {
+			op := "sload"
+			if s.Write {
+				op = "sstore"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s (%X)\n", indent, op, trimWord(s.Key), trimWord(s.Value), s.Address)
+		}
+	}
+	w.Flush()
+
+	fmt.Printf("\nReturn: %X\nGas used: %d\n", trace.Return, trace.GasUsed)
+	if trace.Error != "" {
+		fmt.Printf("Error: %s\n", trace.Error)
+	}
+}
+
+// hex without the leading zeros
+func trimWord(b []byte) string {
+	s := strings.TrimLeft(fmt.Sprintf("%X", b), "0")
+	if s == "" {
+		return "0"
+	}
+	return s
+}
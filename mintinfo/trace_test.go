@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/common"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/vm"
+)
+
+// store 0x2a at 0, load 1 and return it
+var traceCode = "602a" + // PUSH1 0x2a
+	"6000" + // PUSH1 0x00
+	"55" + // SSTORE
+	"6001" + // PUSH1 0x01
+	"54" + // SLOAD
+	"6000" + // PUSH1 0x00
+	"52" + // MSTORE
+	"6020" + // PUSH1 0x20
+	"6000" + // PUSH1 0x00
+	"f3" // RETURN
+
+func TestTrace(t *testing.T) {
+	code, _ := hex.DecodeString(traceCode)
+	contract := []byte("contract............")
+	accounts := map[string]*acm.Account{
+		string(ptypes.GlobalPermissionsAddress): {Permissions: ptypes.DefaultAccountPermissions},
+		string(contract):                        {Address: contract, Code: code},
+	}
+	getAccount := func(addr []byte) (*acm.Account, error) {
+		return accounts[string(addr)], nil
+	}
+	getStorage := func(addr, key []byte) ([]byte, error) {
+		if string(addr) == string(contract) && common.LeftPadWord256(key) == common.LeftPadWord256([]byte{1}) {
+			return []byte{7}, nil
+		}
+		return nil, nil
+	}
+
+	appState := newNodeAppState(getAccount, getStorage)
+	callee := appState.GetAccount(common.LeftPadWord256(contract))
+	caller := &vm.Account{Address: common.LeftPadWord256([]byte("caller"))}
+	trace, err := coreTrace(appState, vm.Params{GasLimit: callGasLimit}, caller, callee, callee.Code, nil, 0, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if trace.Error != "" {
+		t.Fatalf("unexpected error %s", trace.Error)
+	}
+	if fmt.Sprintf("%X", trace.Return) != fmt.Sprintf("%X", common.LeftPadWord256([]byte{7}).Bytes()) {
+		t.Fatalf("bad return %X", trace.Return)
+	}
+	if len(trace.Steps) != 10 {
+		t.Fatalf("expected 10 steps, got %d", len(trace.Steps))
+	}
+	if trace.GasUsed <= 0 || trace.Steps[0].Gas <= trace.Steps[9].Gas {
+		t.Fatalf("bad gas: used %d, first step %d, last step %d", trace.GasUsed, trace.Steps[0].Gas, trace.Steps[9].Gas)
+	}
+
+	sstore := trace.Steps[2]
+	if sstore.Op != "SSTORE" || len(sstore.Stack) != 2 || len(sstore.Storage) != 1 || !sstore.Storage[0].Write || trimWord(sstore.Storage[0].Value) != "2A" {
+		t.Fatalf("bad sstore step %v", sstore)
+	}
+	sload := trace.Steps[4]
+	if sload.Op != "SLOAD" || len(sload.Storage) != 1 || sload.Storage[0].Write || trimWord(sload.Storage[0].Value) != "7" {
+		t.Fatalf("bad sload step %v", sload)
+	}
+	mstore := trace.Steps[6]
+	if mstore.Op != "MSTORE" || len(mstore.Memory) != 1 || mstore.Memory[0].Offset != 31 || fmt.Sprintf("%X", mstore.Memory[0].Data) != "07" {
+		t.Fatalf("bad mstore step %v", mstore)
+	}
+
+	// writes stay local
+	if v := appState.GetStorage(common.LeftPadWord256(contract), common.Zero256); trimWord(v.Bytes()) != "2A" {
+		t.Fatalf("bad local storage %X", v)
+	}
+}
+
+func TestMemoryDeltas(t *testing.T) {
+	tr := &tracer{
+		trace:    new(Trace),
+		memory:   make(map[int]*frameMemory),
+		lastStep: make(map[int]*TraceStep),
+	}
+	memory := make([]byte, 1024*1024)
+	word := common.Int64ToWord256
+
+	// CALLDATACOPY to 0x100, 4 bytes. The stack top is last
+	tr.Step(0, 0, vm.CALLDATACOPY, 100, []common.Word256{word(4), word(0), word(0x100)}, memory)
+	if m := tr.memory[0].mark; m != 0x104 {
+		t.Fatalf("expected the mark at 0x104, got 0x%x", m)
+	}
+	copy(memory[0x100:], []byte{1, 2, 3, 4})
+	tr.Step(0, 1, vm.STOP, 99, nil, memory)
+	if m := tr.trace.Steps[0].Memory; len(m) != 1 || m[0].Offset != 0x100 || fmt.Sprintf("%X", m[0].Data) != "01020304" {
+		t.Fatalf("bad calldatacopy deltas %v", m)
+	}
+
+	// out of bounds writes fail in the vm, but mustn't go past the memory here
+	if end := memoryWriteEnd(vm.MSTORE, []common.Word256{word(int64(len(memory)) - 1)}, len(memory)); end != len(memory) {
+		t.Fatalf("expected the end of memory, got %d", end)
+	}
+	if end := memoryWriteEnd(vm.ADD, []common.Word256{word(1), word(2)}, len(memory)); end != 0 {
+		t.Fatalf("expected no write for ADD, got %d", end)
+	}
+}
+
+func TestCallTxAccounts(t *testing.T) {
+	from := []byte("from................")
+	accounts := map[string]*acm.Account{
+		string(ptypes.GlobalPermissionsAddress): {Permissions: ptypes.DefaultAccountPermissions},
+		string(from):                            {Address: from, Balance: 100, Sequence: 3},
+	}
+	getAccount := func(addr []byte) (*acm.Account, error) {
+		return accounts[string(addr)], nil
+	}
+	create := func(sequence int) (*vm.Account, *vm.Account, error) {
+		appState := newNodeAppState(getAccount, nil)
+		tx := &types.CallTx{Input: &types.TxInput{Address: from, Amount: 10, Sequence: sequence}, Fee: 1}
+		caller, callee, _, err := callTxAccounts(appState, tx)
+		return caller, callee, err
+	}
+
+	// a tx that's already run, or that comes after another in the mempool
+	for _, sequence := range []int{3, 5} {
+		if _, _, err := create(sequence); err == nil {
+			t.Fatalf("expected an error for nonce %d", sequence)
+		}
+	}
+	caller, callee, err := create(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caller.Nonce != 5 || caller.Balance != 99 {
+		t.Fatalf("bad caller nonce %d balance %d", caller.Nonce, caller.Balance)
+	}
+	if expected := types.NewContractAddress(from, 4); callee.Address != common.LeftPadWord256(expected) {
+		t.Fatalf("expected contract %X, got %X", expected, callee.Address.Postfix(20))
+	}
+}
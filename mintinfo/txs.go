@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------
+// tx summaries
+
+// the first input of a tx, if it has one
+func txInput(tx_ types.Tx) *types.TxInput {
+	switch tx := tx_.(type) {
+	case *types.SendTx:
+		if len(tx.Inputs) > 0 {
+			return tx.Inputs[0]
+		}
+	case *types.CallTx:
+		return tx.Input
+	case *types.NameTx:
+		return tx.Input
+	case *types.PermissionsTx:
+		return tx.Input
+	case *types.BondTx:
+		if len(tx.Inputs) > 0 {
+			return tx.Inputs[0]
+		}
+	}
+	return nil
+}
+
+// the address of the first input, or - if there isn't one
+func inputAddress(tx types.Tx) string {
+	if in := txInput(tx); in != nil {
+		return fmt.Sprintf("%X", in.Address)
+	}
+	return "-"
+}
+
+// one line description of a tx
+func txSummary(tx_ types.Tx) string {
+	switch tx := tx_.(type) {
+	case *types.SendTx:
+		var amt int64
+		for _, in := range tx.Inputs {
+			amt += in.Amount
+		}
+		to := "-"
+		if len(tx.Outputs) > 0 {
+			to = fmt.Sprintf("%X", tx.Outputs[0].Address)
+		}
+		return fmt.Sprintf("SendTx from %s to %s amt %d", inputAddress(tx), to, amt)
+	case *types.CallTx:
+		if len(tx.Address) == 0 {
+			return fmt.Sprintf("CallTx from %X creating contract (%d bytes) amt %d gas %d fee %d", tx.Input.Address, len(tx.Data), tx.Input.Amount, tx.GasLimit, tx.Fee)
+		}
+		return fmt.Sprintf("CallTx from %X to %X data %X amt %d gas %d fee %d", tx.Input.Address, tx.Address, tx.Data, tx.Input.Amount, tx.GasLimit, tx.Fee)
+	case *types.NameTx:
+		return fmt.Sprintf("NameTx from %X name %s amt %d fee %d", tx.Input.Address, tx.Name, tx.Input.Amount, tx.Fee)
+	case *types.PermissionsTx:
+		return fmt.Sprintf("PermissionsTx from %X %s", tx.Input.Address, permArgsSummary(tx.PermArgs))
+	case *types.BondTx:
+		amt := "-"
+		if in := txInput(tx); in != nil {
+			amt = fmt.Sprint(in.Amount)
+		}
+		return fmt.Sprintf("BondTx pubkey %X amt %s", tx.PubKey[:], amt)
+	case *types.UnbondTx:
+		return fmt.Sprintf("UnbondTx validator %X height %d", tx.Address, tx.Height)
+	case *types.RebondTx:
+		return fmt.Sprintf("RebondTx validator %X height %d", tx.Address, tx.Height)
+	case *types.DupeoutTx:
+		return fmt.Sprintf("DupeoutTx validator %X", tx.Address)
+	}
+	return fmt.Sprintf("%v", tx_)
+}
+
+func permArgsSummary(args_ ptypes.PermArgs) string {
+	switch args := args_.(type) {
+	case *ptypes.HasBaseArgs:
+		return fmt.Sprintf("has_base %X %s", args.Address, ptypes.PermFlagToString(args.Permission))
+	case *ptypes.SetBaseArgs:
+		return fmt.Sprintf("set_base %X %s %v", args.Address, ptypes.PermFlagToString(args.Permission), args.Value)
+	case *ptypes.UnsetBaseArgs:
+		return fmt.Sprintf("unset_base %X %s", args.Address, ptypes.PermFlagToString(args.Permission))
+	case *ptypes.SetGlobalArgs:
+		return fmt.Sprintf("set_global %s %v", ptypes.PermFlagToString(args.Permission), args.Value)
+	case *ptypes.HasRoleArgs:
+		return fmt.Sprintf("has_role %X %s", args.Address, args.Role)
+	case *ptypes.AddRoleArgs:
+		return fmt.Sprintf("add_role %X %s", args.Address, args.Role)
+	case *ptypes.RmRoleArgs:
+		return fmt.Sprintf("rm_role %X %s", args.Address, args.Role)
+	}
+	return fmt.Sprintf("%v", args_)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/codegangsta/cli"
+	stypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/state/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------
+// validator uptime
+
+type ValidatorUptime struct {
+	Address           []byte `json:"address"`
+	VotingPower       int64  `json:"voting_power"`
+	Signed            int    `json:"signed"`
+	Missed            int    `json:"missed"`
+	LongestMissStreak int    `json:"longest_miss_streak"`
+	Proposed          int    `json:"proposed"`
+
+	missStreak int
+}
+
+type UptimeReport struct {
+	From       int                `json:"from"`
+	To         int                `json:"to"`
+	Validators []*ValidatorUptime `json:"validators"`
+
+	// heights whose precommits didn't line up with the validator set
+	Unattributed []int `json:"unattributed"`
+
+	// proposers can only be recomputed if the validator set
+	// hasn't changed since genesis
+	ProposersKnown bool `json:"proposers_known"`
+}
+
+func cliUptime(c *cli.Context) {
+	from, to := c.Int("from"), c.Int("to")
+
+	r, err := client.Status()
+	ifExit(err)
+	// the precommits for height H are in block H+1,
+	// so the last block we can report on is one behind the latest
+	lastHeight := r.LatestBlockHeight - 1
+	if to == 0 || to > lastHeight {
+		to = lastHeight
+	}
+	if from == 0 {
+		from = to - 99
+	}
+	if from < 1 {
+		from = 1
+	}
+	if to < from {
+		exit(fmt.Errorf("--to (%d) must not be less than --from (%d)", to, from))
+	}
+
+	vals, err := client.ListValidators()
+	ifExit(err)
+	gen, err := client.Genesis()
+	ifExit(err)
+
+	report, err := coreUptime(vals.BondedValidators, genesisValidators(gen.Genesis.Validators), from, to, getBlock)
+	ifExit(err)
+
+	if c.Bool("json") {
+		s, err := prettyPrint(report)
+		ifExit(err)
+		fmt.Println(s)
+		return
+	}
+	printUptime(report)
+}
+
+func getBlock(height int) (*types.Block, error) {
+	r, err := client.GetBlock(height)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil || r.Block == nil {
+		return nil, fmt.Errorf("block %d does not exist", height)
+	}
+	return r.Block, nil
+}
+
+// the validator set as it was at genesis, before any accum increments
+func genesisValidators(genVals []stypes.GenesisValidator) []*types.Validator {
+	vals := make([]*types.Validator, len(genVals))
+	for i, v := range genVals {
+		vals[i] = &types.Validator{
+			Address:     v.PubKey.Address(),
+			PubKey:      v.PubKey,
+			VotingPower: v.Amount,
+		}
+	}
+	return vals
+}
+
+// walk the LastValidation of blocks from+1 to to+1 and tally who signed.
+// Precommits are ordered by validator address, so we can only attribute them
+// while the size of the validator set matches the current one.
+func coreUptime(bonded, genVals []*types.Validator, from, to int, getBlock func(int) (*types.Block, error)) (*UptimeReport, error) {
+	valSet := types.NewValidatorSet(bonded)
+	report := &UptimeReport{
+		From:           from,
+		To:             to,
+		Validators:     make([]*ValidatorUptime, valSet.Size()),
+		ProposersKnown: valSet.Size() > 0 && sameValidators(valSet, types.NewValidatorSet(genVals)),
+	}
+	for i, v := range valSet.Validators {
+		report.Validators[i] = &ValidatorUptime{
+			Address:     v.Address,
+			VotingPower: v.VotingPower,
+		}
+	}
+
+	// replay the accums from genesis so we know who proposed each block
+	propSet := types.NewValidatorSet(genVals)
+	for h := 1; h < from && report.ProposersKnown; h++ {
+		propSet.IncrementAccum(1)
+	}
+
+	for h := from; h <= to; h++ {
+		block, err := getBlock(h + 1)
+		if err != nil {
+			return nil, err
+		}
+		precommits := block.LastValidation.Precommits
+		if len(precommits) != valSet.Size() {
+			report.Unattributed = append(report.Unattributed, h)
+		} else {
+			for i, precommit := range precommits {
+				report.Validators[i].tally(precommit != nil)
+			}
+		}
+
+		if report.ProposersKnown {
+			proposers := propSet
+			if round := block.LastValidation.Round(); round > 0 {
+				proposers = propSet.Copy()
+				proposers.IncrementAccum(round)
+			}
+			if i, val := valSet.GetByAddress(proposers.Proposer().Address); val != nil {
+				report.Validators[i].Proposed += 1
+			}
+			propSet.IncrementAccum(1)
+		}
+	}
+	return report, nil
+}
+
+func (v *ValidatorUptime) tally(signed bool) {
+	if signed {
+		v.Signed += 1
+		v.missStreak = 0
+		return
+	}
+	v.Missed += 1
+	v.missStreak += 1
+	if v.missStreak > v.LongestMissStreak {
+		v.LongestMissStreak = v.missStreak
+	}
+}
+
+func sameValidators(a, b *types.ValidatorSet) bool {
+	if a.Size() != b.Size() {
+		return false
+	}
+	for i, v := range a.Validators {
+		w := b.Validators[i]
+		if !bytes.Equal(v.Address, w.Address) || v.VotingPower != w.VotingPower {
+			return false
+		}
+	}
+	return true
+}
+
+func printUptime(report *UptimeReport) {
+	fmt.Printf("Blocks %d to %d\n\n", report.From, report.To)
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ADDRESS\tPOWER\tSIGNED\tMISSED\tUPTIME\tLONGEST MISS\tPROPOSED")
+	for _, v := range report.Validators {
+		proposed := "-"
+		if report.ProposersKnown {
+			proposed = fmt.Sprintf("%d", v.Proposed)
+		}
+		uptime := "-"
+		if total := v.Signed + v.Missed; total > 0 {
+			uptime = fmt.Sprintf("%.2f%%", 100*float64(v.Signed)/float64(total))
+		}
+		fmt.Fprintf(w, "%X\t%d\t%d\t%d\t%s\t%d\t%s\n", v.Address, v.VotingPower, v.Signed, v.Missed, uptime, v.LongestMissStreak, proposed)
+	}
+	w.Flush()
+
+	if !report.ProposersKnown {
+		fmt.Println("\nThe validator set has changed since genesis, so proposers could not be determined")
+	}
+	if len(report.Unattributed) > 0 {
+		fmt.Printf("\n%d blocks were signed by a different validator set and were not counted: %v\n", len(report.Unattributed), report.Unattributed)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+func testValidators(n int) []*types.Validator {
+	vals := make([]*types.Validator, n)
+	for i := range vals {
+		_, val, _ := types.RandValidator(false, 10)
+		vals[i] = val
+	}
+	return types.NewValidatorSet(vals).Validators
+}
+
+// signed[h] is the list of validator indices that signed height h
+func testChain(n int, signed map[int][]int) func(int) (*types.Block, error) {
+	return func(height int) (*types.Block, error) {
+		precommits := make([]*types.Vote, n)
+		for _, i := range signed[height-1] {
+			precommits[i] = &types.Vote{Height: height - 1, Type: types.VoteTypePrecommit}
+		}
+		return &types.Block{LastValidation: &types.Validation{Precommits: precommits}}, nil
+	}
+}
+
+func TestUptime(t *testing.T) {
+	vals := testValidators(3)
+	signed := map[int][]int{
+		1: {0, 1, 2},
+		2: {0, 1},
+		3: {0, 1},
+		4: {0, 2},
+		5: {0, 1},
+	}
+
+	report, err := coreUptime(vals, vals, 1, 5, testChain(3, signed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct{ signed, missed, streak int }{
+		{5, 0, 0},
+		{4, 1, 1},
+		{2, 3, 2},
+	}
+	for i, e := range expected {
+		v := report.Validators[i]
+		if v.Signed != e.signed || v.Missed != e.missed || v.LongestMissStreak != e.streak {
+			t.Fatalf("validator %d: got signed %d, missed %d, streak %d. Expected %v", i, v.Signed, v.Missed, v.LongestMissStreak, e)
+		}
+	}
+
+	if !report.ProposersKnown {
+		t.Fatal("expected proposers to be known for an unchanged validator set")
+	}
+	var proposed int
+	for _, v := range report.Validators {
+		proposed += v.Proposed
+	}
+	if proposed != 5 {
+		t.Fatalf("expected 5 proposers, got %d", proposed)
+	}
+}
+
+func TestUptimeChangedSet(t *testing.T) {
+	vals := testValidators(3)
+	report, err := coreUptime(vals, vals[:2], 2, 3, testChain(2, map[int][]int{2: {0, 1}, 3: {1}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ProposersKnown {
+		t.Fatal("expected proposers to be unknown for a changed validator set")
+	}
+	if fmt.Sprint(report.Unattributed) != "[2 3]" {
+		t.Fatalf("expected heights 2 and 3 to be unattributed, got %v", report.Unattributed)
+	}
+}
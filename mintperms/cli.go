@@ -2,95 +2,164 @@ package main
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/eris-ltd/mint-client/mintx/core"
 
 	. "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/eris-ltd/common/go/common"
 	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/spf13/cobra"
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
 	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
 )
 
 func cliStringsToInts(cmd *cobra.Command, args []string) {
 	cmd.ParseFlags(args)
 	if len(args) == 0 {
-		Exit(fmt.Errorf("Please enter at least one `<permission>:<value>` pair like `send:0 call:1 create_account:1`"))
+		Exit(fmt.Errorf("Please enter at least one `<permission>:<value>` pair like `send:0 call:1 create_account:1`, a json object, or a .json or .toml file"))
 	}
-
-	bp := types.ZeroBasePermissions
-
-	for _, a := range args {
-		spl := strings.Split(a, ":")
-		if len(spl) != 2 {
-			Exit(fmt.Errorf("arguments must be like `send:1`, not %s", a))
-		}
-		name, v := spl[0], spl[1]
-		vi := v[0] - '0'
-		pf, err := types.PermStringToFlag(name)
-		IfExit(err)
-		bp.Set(pf, vi > 0)
+	bp, err := parsePerms(args)
+	IfExit(err)
+	if format == FormatText {
+		printInts(os.Stdout, bp)
+		return
 	}
-	fmt.Println("Perms and SetBit (As Integers)")
-	fmt.Printf("%d\t%d\n", bp.Perms, bp.SetBit)
-	fmt.Println("\nPerms and SetBit (As Bitmasks)")
-	fmt.Printf("%b\t%b\n", bp.Perms, bp.SetBit)
+	printPerms(bp, format)
 }
 
-func coreIntsToStrings(perms, setbits types.PermFlag) map[string]bool {
-	m := make(map[string]bool)
-
-	for i := uint(0); i < types.NumPermissions; i++ {
-		pf := types.PermFlag(1 << i)
-		if pf&setbits > 0 {
-			name := types.PermFlagToString(pf)
-			m[name] = pf&perms > 0
-		}
+func cliIntsToStrings(cmd *cobra.Command, args []string) {
+	cmd.ParseFlags(args)
+	if len(args) != 2 {
+		Exit(fmt.Errorf("Please enter PermFlag and SetBit integers"))
 	}
-	return m
+	bp, err := parsePermInts(args[0], args[1])
+	IfExit(err)
+	printPerms(bp, format)
 }
 
-func cliIntsToStrings(cmd *cobra.Command, args []string) {
+func cliExplain(cmd *cobra.Command, args []string) {
 	cmd.ParseFlags(args)
 	if len(args) != 2 {
 		Exit(fmt.Errorf("Please enter PermFlag and SetBit integers"))
 	}
-
-	pf, sb := args[0], args[1]
-	perms, err := strconv.Atoi(pf)
-	IfExit(err)
-	setbits, err := strconv.Atoi(sb)
+	bp, err := parsePermInts(args[0], args[1])
 	IfExit(err)
+	printExplain(os.Stdout, bp)
+}
 
-	m := coreIntsToStrings(types.PermFlag(perms), types.PermFlag(setbits))
-	for name, v := range m {
-		fmt.Printf("%s: %v\n", name, v)
+func cliDiff(cmd *cobra.Command, args []string) {
+	cmd.ParseFlags(args)
+	if len(args) != 4 {
+		Exit(fmt.Errorf("Please enter two PermFlag and SetBit pairs: <perms> <setbit> <perms> <setbit>"))
 	}
+	from, err := parsePermInts(args[0], args[1])
+	IfExit(err)
+	to, err := parsePermInts(args[2], args[3])
+	IfExit(err)
+	printDiff(os.Stdout, diffPerms(from, to))
 }
 
 func cliBBPB(cmd *cobra.Command, args []string) {
-	pf := types.DefaultPermFlags
-	fmt.Println("Perms and SetBit (As Integers)")
-	fmt.Printf("%d\t%d\n", pf, pf)
-	fmt.Println("\nPerms and SetBit (As Bitmasks)")
-	fmt.Printf("%b\t%b\n", pf, pf)
+	printAllOn(types.DefaultPermFlags)
+}
 
-	m := coreIntsToStrings(pf, pf)
+func cliAll(cmd *cobra.Command, args []string) {
+	printAllOn(types.AllPermFlags)
+}
 
-	for name, v := range m {
-		fmt.Printf("%s: %v\n", name, v)
+// perms that are all set and on
+func printAllOn(pf types.PermFlag) {
+	bp := types.BasePermissions{Perms: pf, SetBit: pf}
+	if format == FormatText {
+		printInts(os.Stdout, bp)
+		fmt.Println()
 	}
+	printPerms(bp, format)
 }
 
-func cliAll(cmd *cobra.Command, args []string) {
-	pf := types.AllPermFlags
-	fmt.Println("Perms and SetBit (As Integers)")
-	fmt.Printf("%d\t%d\n", pf, pf)
-	fmt.Println("\nPerms and SetBit (As Bitmasks)")
-	fmt.Printf("%b\t%b\n", pf, pf)
+func printPerms(bp types.BasePermissions, format string) {
+	s, err := formatPerms(bp, format)
+	IfExit(err)
+	if s != "" {
+		fmt.Println(s)
+	}
+}
 
-	m := coreIntsToStrings(pf, pf)
+//------------------------------------------------------------------------------------
+// plan and apply
 
-	for name, v := range m {
-		fmt.Printf("%s: %v\n", name, v)
+func cliPlan(cmd *cobra.Command, args []string) {
+	changes := planFromArgs(args)
+	printPlan(changes)
+}
+
+func cliApply(cmd *cobra.Command, args []string) {
+	changes := planFromArgs(args)
+	printPlan(changes)
+	if len(changes) == 0 {
+		return
+	}
+	if chainID == "" {
+		status, err := cclient.NewClient(nodeAddr, "JSONRPC").Status()
+		IfExit(err)
+		chainID = status.NodeInfo.ChainID
 	}
 
+	IfExit(coreApply(chainID, nodeAddr, signAddr, pubkey, addr, changes, wait, os.Stdout))
+}
+
+// send the changes as sequential txs, stopping at the first that fails
+func coreApply(chainID, nodeAddr, signAddr, pubkey, addr string, changes []Change, wait bool, out io.Writer) error {
+	var permArgs []string
+	for _, c := range changes {
+		permArgs = append(permArgs, c.Func)
+		permArgs = append(permArgs, c.Args...)
+	}
+	txs, err := core.PermissionsBatch(nodeAddr, signAddr, pubkey, addr, "", permArgs)
+	if err != nil {
+		return err
+	}
+	for i, tx := range txs {
+		// only wait on the last, they're committed in order
+		result, err := core.SignAndBroadcast(chainID, nodeAddr, signAddr, tx, true, true, wait && i == len(txs)-1)
+		if err != nil {
+			return fmt.Errorf("Error applying %s: %v. %d of %d changes were sent", changes[i], err, i, len(changes))
+		}
+		fmt.Fprintf(out, "%s\t%X\n", changes[i], result.Hash)
+	}
+	fmt.Fprintf(out, "Sent %d changes\n", len(txs))
+	return nil
+}
+
+func planFromArgs(args []string) []Change {
+	if len(args) != 1 {
+		Exit(fmt.Errorf("Please enter a policy file"))
+	}
+	policy, err := LoadPolicy(args[0])
+	IfExit(err)
+	rpc := cclient.NewClient(nodeAddr, "JSONRPC")
+	changes, err := corePlan(policy, func(addr []byte) (*acm.Account, error) {
+		r, err := rpc.GetAccount(addr)
+		if err != nil || r == nil {
+			return nil, err
+		}
+		return r.Account, nil
+	})
+	IfExit(err)
+	return changes
+}
+
+func printPlan(changes []Change) {
+	if len(changes) == 0 {
+		fmt.Println("No changes. The chain matches the policy")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	for _, c := range changes {
+		fmt.Fprintln(w, c)
+	}
+	w.Flush()
+	fmt.Printf("%d changes\n", len(changes))
 }
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/naoina/toml"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+)
+
+//------------------------------------------------------------------------------------
+// converting between {perm: value} objects and (Perms, SetBit) pairs.
+// Perms that aren't set are left out of the objects
+
+// the output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatTOML = "toml"
+	FormatCSV  = "csv" // perms,setbit as in mintgen's csv
+)
+
+// perms from `<perm>:<value>` pairs, a json object, or a .json or .toml file of one
+func parsePerms(args []string) (types.BasePermissions, error) {
+	if len(args) == 1 {
+		arg := strings.TrimSpace(args[0])
+		if strings.HasPrefix(arg, "{") {
+			return parsePermsObject([]byte(arg), FormatJSON)
+		}
+		switch ext := filepath.Ext(arg); ext {
+		case ".json", ".toml":
+			b, err := ioutil.ReadFile(arg)
+			if err != nil {
+				return types.ZeroBasePermissions, err
+			}
+			return parsePermsObject(b, ext[1:])
+		}
+	}
+
+	m := make(map[string]bool)
+	for _, a := range args {
+		spl := strings.Split(a, ":")
+		if len(spl) != 2 {
+			return types.ZeroBasePermissions, fmt.Errorf("arguments must be like `send:1`, not %s", a)
+		}
+		name, v := spl[0], spl[1]
+		if _, ok := m[name]; ok {
+			return types.ZeroBasePermissions, fmt.Errorf("%s is given twice", name)
+		}
+		value, err := parsePermValue(v)
+		if err != nil {
+			return types.ZeroBasePermissions, fmt.Errorf("%s: %v", name, err)
+		}
+		m[name] = value
+	}
+	return permsFromMap(m)
+}
+
+func parsePermsObject(b []byte, format string) (types.BasePermissions, error) {
+	m := make(map[string]bool)
+	var err error
+	if format == FormatTOML {
+		err = toml.Unmarshal(b, &m)
+	} else {
+		err = json.Unmarshal(b, &m)
+	}
+	if err != nil {
+		return types.ZeroBasePermissions, fmt.Errorf("Error parsing %s permissions: %v", format, err)
+	}
+	return permsFromMap(m)
+}
+
+func parsePermValue(v string) (bool, error) {
+	switch strings.ToLower(v) {
+	case "true", "1":
+		return true, nil
+	case "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("value must be true, false, 1 or 0, not %s", v)
+}
+
+func parsePermInts(permsS, setBitS string) (types.BasePermissions, error) {
+	perms, err := strconv.ParseUint(permsS, 0, 64)
+	if err != nil {
+		return types.ZeroBasePermissions, fmt.Errorf("PermFlag must be an integer: %v", err)
+	}
+	setBit, err := strconv.ParseUint(setBitS, 0, 64)
+	if err != nil {
+		return types.ZeroBasePermissions, fmt.Errorf("SetBit must be an integer: %v", err)
+	}
+	bp := types.BasePermissions{Perms: types.PermFlag(perms), SetBit: types.PermFlag(setBit)}
+	if bp.SetBit > types.AllPermFlags {
+		return bp, fmt.Errorf("SetBit %d has bits above the top permission (%d)", bp.SetBit, types.TopPermFlag)
+	}
+	if bp.Perms&^bp.SetBit != 0 {
+		return bp, fmt.Errorf("PermFlag %d has bits that aren't set in SetBit %d: %b", bp.Perms, bp.SetBit, bp.Perms&^bp.SetBit)
+	}
+	return bp, nil
+}
+
+// a set perm and its value, in NumPermissions order
+type permValue struct {
+	Name  string
+	Value bool
+}
+
+func setPerms(bp types.BasePermissions) []permValue {
+	var pvs []permValue
+	for i := uint(0); i < types.NumPermissions; i++ {
+		pf := types.PermFlag(1 << i)
+		if v, err := bp.Get(pf); err == nil {
+			pvs = append(pvs, permValue{types.PermFlagToString(pf), v})
+		}
+	}
+	return pvs
+}
+
+// the set perms as an object, in NumPermissions order
+func formatPerms(bp types.BasePermissions, format string) (string, error) {
+	pvs := setPerms(bp)
+	switch format {
+	case FormatJSON:
+		parts := make([]string, len(pvs))
+		for i, pv := range pvs {
+			parts[i] = fmt.Sprintf("%q: %v", pv.Name, pv.Value)
+		}
+		return "{" + strings.Join(parts, ", ") + "}", nil
+	case FormatTOML:
+		lines := make([]string, len(pvs))
+		for i, pv := range pvs {
+			lines[i] = fmt.Sprintf("%s = %v", pv.Name, pv.Value)
+		}
+		return strings.Join(lines, "\n"), nil
+	case FormatCSV:
+		return fmt.Sprintf("%d,%d", bp.Perms, bp.SetBit), nil
+	case FormatText, "":
+		lines := make([]string, len(pvs))
+		for i, pv := range pvs {
+			lines[i] = fmt.Sprintf("%s: %v", pv.Name, pv.Value)
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+	return "", fmt.Errorf("Unknown format %s. Options are text, json, toml and csv", format)
+}
+
+func printInts(out io.Writer, bp types.BasePermissions) {
+	fmt.Fprintln(out, "Perms and SetBit (As Integers)")
+	fmt.Fprintf(out, "%d\t%d\n", bp.Perms, bp.SetBit)
+	fmt.Fprintln(out, "\nPerms and SetBit (As Bitmasks)")
+	fmt.Fprintf(out, "%b\t%b\n", bp.Perms, bp.SetBit)
+}
+
+// every bit, what it's for and what it's set to
+func printExplain(out io.Writer, bp types.BasePermissions) {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "bit\tflag\tpermission\tset\tvalue")
+	for i := uint(0); i < types.NumPermissions; i++ {
+		pf := types.PermFlag(1 << i)
+		value := "-"
+		if v, err := bp.Get(pf); err == nil {
+			value = fmt.Sprint(v)
+		}
+		fmt.Fprintf(w, "%d\t%d\t%s\t%v\t%s\n", i, pf, types.PermFlagToString(pf), bp.SetBit&pf > 0, value)
+	}
+	w.Flush()
+}
+
+// a change to one perm between two pairs. Values are true, false or unset
+type permDiff struct {
+	Name     string
+	From, To string
+}
+
+func diffPerms(from, to types.BasePermissions) []permDiff {
+	value := func(bp types.BasePermissions, pf types.PermFlag) string {
+		if v, err := bp.Get(pf); err == nil {
+			return fmt.Sprint(v)
+		}
+		return "unset"
+	}
+	var diffs []permDiff
+	for i := uint(0); i < types.NumPermissions; i++ {
+		pf := types.PermFlag(1 << i)
+		if f, t := value(from, pf), value(to, pf); f != t {
+			diffs = append(diffs, permDiff{types.PermFlagToString(pf), f, t})
+		}
+	}
+	return diffs
+}
+
+func printDiff(out io.Writer, diffs []permDiff) {
+	if len(diffs) == 0 {
+		fmt.Fprintln(out, "No differences")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	for _, d := range diffs {
+		fmt.Fprintf(w, "%s\t%s -> %s\n", d.Name, d.From, d.To)
+	}
+	w.Flush()
+}
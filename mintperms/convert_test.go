@@ -0,0 +1,104 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+)
+
+func TestParsePerms(t *testing.T) {
+	want := types.BasePermissions{Perms: types.Send | types.Name, SetBit: types.Send | types.Call | types.Name}
+	dir, err := ioutil.TempDir("", "mintperms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tomlFile := filepath.Join(dir, "perms.toml")
+	ioutil.WriteFile(tomlFile, []byte("send = true\ncall = false\nname = true\n"), 0600)
+
+	for _, args := range [][]string{
+		{"call:0", "send:1", "name:true"},
+		{"call:FALSE", "send:true", "name:1"},
+		{`{"send": true, "call": false, "name": true}`},
+		{tomlFile},
+	} {
+		bp, err := parsePerms(args)
+		if err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		if bp != want {
+			t.Fatalf("%v: expected %v, got %v", args, want, bp)
+		}
+	}
+
+	// used to be read as set
+	for _, args := range [][]string{{"send:yes"}, {"send:2"}, {"send"}, {"sned:1"}, {"send:1", "send:0"}, {`{"send": 1}`}} {
+		if _, err := parsePerms(args); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestFormatPerms(t *testing.T) {
+	bp, err := parsePermInts("66", "70")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		FormatText: "send: true\ncall: false\nname: true",
+		FormatJSON: `{"send": true, "call": false, "name": true}`,
+		FormatTOML: "send = true\ncall = false\nname = true",
+		FormatCSV:  "66,70",
+	}
+	for format, expected := range cases {
+		s, err := formatPerms(bp, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != expected {
+			t.Fatalf("%s: expected %q, got %q", format, expected, s)
+		}
+	}
+
+	// round trip
+	for _, format := range []string{FormatJSON, FormatTOML} {
+		s, _ := formatPerms(bp, format)
+		back, err := parsePermsObject([]byte(s), format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if back != bp {
+			t.Fatalf("%s: expected %v, got %v", format, bp, back)
+		}
+	}
+
+	if _, err := formatPerms(bp, "xml"); err == nil {
+		t.Fatal("expected error for an unknown format")
+	}
+	for _, pair := range [][2]string{{"8", "2"}, {"x", "2"}, {"0", "32768"}} {
+		if _, err := parsePermInts(pair[0], pair[1]); err == nil {
+			t.Fatalf("expected error for %v", pair)
+		}
+	}
+}
+
+func TestDiffPerms(t *testing.T) {
+	from, _ := parsePermInts("66", "70")
+	to, _ := parsePermInts("6", "7")
+	diffs := diffPerms(from, to)
+	expected := []permDiff{{"root", "unset", "false"}, {"call", "false", "true"}, {"name", "true", "unset"}}
+	if len(diffs) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, diffs)
+	}
+	for i := range diffs {
+		if diffs[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, diffs)
+		}
+	}
+	if len(diffPerms(from, from)) != 0 {
+		t.Fatal("expected no differences")
+	}
+}
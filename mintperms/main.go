@@ -1,22 +1,65 @@
 package main
 
 import (
+	"os"
+
 	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/spf13/cobra"
 )
 
+var (
+	DefaultNodeRPCAddr = "http://pinkpenguin.chaintest.net:46657"
+	DefaultSignAddr    = "http://localhost:4767"
+
+	nodeAddr string
+	signAddr string
+	chainID  string
+	pubkey   string
+	addr     string
+	wait     bool
+
+	role       string
+	jsonOutput bool
+
+	format string
+)
+
+// the same env variables as mintx
+func init() {
+	if v := os.Getenv("MINTX_NODE_ADDR"); v != "" {
+		DefaultNodeRPCAddr = v
+	}
+	if v := os.Getenv("MINTX_SIGN_ADDR"); v != "" {
+		DefaultSignAddr = v
+	}
+}
+
 func main() {
 	var stringsToIntsCmd = &cobra.Command{
 		Use:   "int",
 		Short: "Convert list of permissions to PermFlag and SetBit",
-		Long:  "Example: mintperms int call:0 send:1 name:1",
-		Run:   cliStringsToInts,
+		Long: `Example: mintperms int call:0 send:1 name:true
+         mintperms int '{"call": false, "send": true}'
+         mintperms int perms.toml --format=csv`,
+		Run: cliStringsToInts,
 	}
 	var intsToStringsCmd = &cobra.Command{
 		Use:   "string",
 		Short: "Convert PermFlag and SetBit integers to strings",
-		Long:  "Example: mintperms string 2 6",
+		Long:  "Example: mintperms string 2 6 --format=toml",
 		Run:   cliIntsToStrings,
 	}
+	var explainCmd = &cobra.Command{
+		Use:   "explain",
+		Short: "Explain PermFlag and SetBit integers bit by bit",
+		Long:  "Example: mintperms explain 66 70",
+		Run:   cliExplain,
+	}
+	var diffCmd = &cobra.Command{
+		Use:   "diff",
+		Short: "Show the permissions that differ between two PermFlag and SetBit pairs",
+		Long:  "Example: mintperms diff 66 70 2 6",
+		Run:   cliDiff,
+	}
 	var bbpbCmd = &cobra.Command{
 		Use:   "bbpb",
 		Short: "Print the permissions for a BBPB",
@@ -27,8 +70,42 @@ func main() {
 		Short: "Print the PermFlag and SetBit for all permissions on and set",
 		Run:   cliAll,
 	}
+	var planCmd = &cobra.Command{
+		Use:   "plan",
+		Short: "Print the PermissionsTxs that would make the chain match a policy file",
+		Long:  "Example: mintperms plan policy.toml --node-addr=http://localhost:46657",
+		Run:   cliPlan,
+	}
+	var applyCmd = &cobra.Command{
+		Use:   "apply",
+		Short: "Sign and broadcast the PermissionsTxs that make the chain match a policy file",
+		Long:  "Example: mintperms apply policy.toml --pubkey=<pubkey> --wait",
+		Run:   cliApply,
+	}
+
+	var rolesCmd = &cobra.Command{
+		Use:   "roles",
+		Short: "List every role and its members, and the accounts granted dangerous permissions directly",
+		Long:  "Example: mintperms roles --role=admin --node-addr=http://localhost:46657",
+		Run:   cliRoles,
+	}
+
+	for _, cmd := range []*cobra.Command{planCmd, applyCmd, rolesCmd} {
+		cmd.Flags().StringVarP(&nodeAddr, "node-addr", "", DefaultNodeRPCAddr, "set the address of the tendermint rpc server")
+	}
+	applyCmd.Flags().StringVarP(&signAddr, "sign-addr", "", DefaultSignAddr, "set the address of the eris-keys daemon")
+	applyCmd.Flags().StringVarP(&chainID, "chainID", "", os.Getenv("MINTX_CHAINID"), "specify the chainID. Fetched from the node if not given")
+	applyCmd.Flags().StringVarP(&pubkey, "pubkey", "", os.Getenv("MINTX_PUBKEY"), "specify the pubkey to sign with")
+	applyCmd.Flags().StringVarP(&addr, "addr", "", "", "specify the address to sign with (the pubkey is fetched from eris-keys)")
+	applyCmd.Flags().BoolVarP(&wait, "wait", "", false, "wait for the changes to be committed")
+	rolesCmd.Flags().StringVarP(&role, "role", "", "", "only list the members of this role")
+	rolesCmd.Flags().BoolVarP(&jsonOutput, "json", "", false, "print the report as json")
+
+	for _, cmd := range []*cobra.Command{stringsToIntsCmd, intsToStringsCmd, bbpbCmd, allCmd} {
+		cmd.Flags().StringVarP(&format, "format", "", FormatText, "output format: text, json, toml or csv (perms,setbit as in mintgen's csv)")
+	}
 
 	var rootCmd = &cobra.Command{Use: "mintperms"}
-	rootCmd.AddCommand(stringsToIntsCmd, intsToStringsCmd, bbpbCmd, allCmd)
+	rootCmd.AddCommand(stringsToIntsCmd, intsToStringsCmd, explainCmd, diffCmd, bbpbCmd, allCmd, planCmd, applyCmd, rolesCmd)
 	rootCmd.Execute()
 }
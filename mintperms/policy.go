@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/naoina/toml"
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+)
+
+//------------------------------------------------------------------------------------
+// a policy is the permissions we want on chain.
+// Each account listed owns its base permissions and roles outright:
+// permissions it doesn't list are unset, so they fall back to global,
+// and roles it doesn't list are removed. Accounts not listed are left alone
+
+type Policy struct {
+	Global   map[string]bool          `toml:"global"`
+	Accounts map[string]PolicyAccount `toml:"accounts"`
+}
+
+type PolicyAccount struct {
+	Address string          `toml:"address"`
+	Perms   map[string]bool `toml:"perms"`
+	Roles   []string        `toml:"roles"`
+}
+
+func LoadPolicy(file string) (*Policy, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	policy := new(Policy)
+	if err := toml.Unmarshal(b, policy); err != nil {
+		return nil, fmt.Errorf("Error parsing policy %s: %v", file, err)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("Invalid policy %s: %v", file, err)
+	}
+	return policy, nil
+}
+
+func (p *Policy) validate() error {
+	if _, err := permsFromMap(p.Global); err != nil {
+		return fmt.Errorf("global: %v", err)
+	}
+	seen := make(map[string]string)
+	for _, name := range p.accountNames() {
+		acc := p.Accounts[name]
+		addr, err := acc.address()
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		if other, ok := seen[string(addr)]; ok {
+			return fmt.Errorf("%s and %s have the same address %X", other, name, addr)
+		}
+		seen[string(addr)] = name
+		if _, err := permsFromMap(acc.Perms); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		roles := make(map[string]bool)
+		for _, role := range acc.Roles {
+			if role == "" || len(role) > 32 {
+				return fmt.Errorf("%s: role %q must be 1 to 32 bytes", name, role)
+			}
+			if roles[role] {
+				return fmt.Errorf("%s: role %s is listed twice", name, role)
+			}
+			roles[role] = true
+		}
+	}
+	return nil
+}
+
+func (p *Policy) accountNames() []string {
+	var names []string
+	for name := range p.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (a PolicyAccount) address() ([]byte, error) {
+	addr, err := hex.DecodeString(a.Address)
+	if err != nil {
+		return nil, fmt.Errorf("address is bad hex: %v", err)
+	}
+	if len(addr) != 20 {
+		return nil, fmt.Errorf("address must be 20 bytes, got %d", len(addr))
+	}
+	return addr, nil
+}
+
+// {perm: value} to base permissions, with the perms that aren't listed unset
+func permsFromMap(m map[string]bool) (types.BasePermissions, error) {
+	bp := types.ZeroBasePermissions
+	for name, v := range m {
+		pf, err := types.PermStringToFlag(name)
+		if err != nil {
+			return bp, err
+		}
+		bp.Set(pf, v)
+	}
+	return bp, nil
+}
+
+//------------------------------------------------------------------------------------
+// plans
+
+// a PermissionsTx to send. Args are as for `mintx perm`
+type Change struct {
+	Account string   // the name in the policy, or global
+	Func    string   // the snative function
+	Args    []string // the function's args
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s\t%s %s", c.Account, c.Func, strings.Join(c.Args, " "))
+}
+
+// the fewest txs that take the chain's permissions to the policy.
+// getAccount returns nil for accounts that don't exist
+func corePlan(policy *Policy, getAccount func([]byte) (*acm.Account, error)) ([]Change, error) {
+	var changes []Change
+
+	global, err := getAccount(types.GlobalPermissionsAddress)
+	if err != nil {
+		return nil, err
+	}
+	if global == nil {
+		return nil, fmt.Errorf("The global permissions account does not exist")
+	}
+	for i := uint(0); i < types.NumPermissions; i++ {
+		pf := types.PermFlag(1 << i)
+		name := types.PermFlagToString(pf)
+		want, ok := policy.Global[name]
+		if !ok {
+			continue
+		}
+		if v, err := global.Permissions.Base.Get(pf); err != nil || v != want {
+			changes = append(changes, Change{"global", "set_global", []string{name, fmt.Sprint(want)}})
+		}
+	}
+
+	for _, accName := range policy.accountNames() {
+		pa := policy.Accounts[accName]
+		addr, _ := pa.address()
+		addrS := fmt.Sprintf("%X", addr)
+		acc, err := getAccount(addr)
+		if err != nil {
+			return nil, err
+		}
+		if acc == nil {
+			return nil, fmt.Errorf("Account %s (%s) does not exist", accName, addrS)
+		}
+
+		for i := uint(0); i < types.NumPermissions; i++ {
+			pf := types.PermFlag(1 << i)
+			name := types.PermFlagToString(pf)
+			want, listed := pa.Perms[name]
+			v, err := acc.Permissions.Base.Get(pf)
+			set := err == nil
+			switch {
+			case listed && (!set || v != want):
+				changes = append(changes, Change{accName, "set_base", []string{addrS, name, fmt.Sprint(want)}})
+			case !listed && set:
+				changes = append(changes, Change{accName, "unset_base", []string{addrS, name}})
+			}
+		}
+
+		have := make(map[string]bool)
+		for _, role := range acc.Permissions.Roles {
+			// roles are stored left padded to a word
+			have[strings.TrimLeft(role, "\x00")] = true
+		}
+		want := make(map[string]bool)
+		for _, role := range pa.Roles {
+			want[role] = true
+		}
+		// adds go first: accounts are copied shallowly, so a node's mempool
+		// can write into the roles of its committed state when an add follows a removal
+		for _, role := range pa.Roles {
+			if !have[role] {
+				changes = append(changes, Change{accName, "add_role", []string{addrS, role}})
+			}
+		}
+		for _, role := range sortedKeys(have) {
+			if !want[role] {
+				changes = append(changes, Change{accName, "rm_role", []string{addrS, role}})
+			}
+		}
+	}
+	return changes, nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
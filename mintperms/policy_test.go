@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/eris-ltd/mint-client/fakenode"
+
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+)
+
+func writePolicy(t *testing.T, policy string) string {
+	f, err := ioutil.TempFile("", "mintperms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(policy)
+	f.Close()
+	return f.Name()
+}
+
+func TestLoadPolicy(t *testing.T) {
+	bad := []string{
+		"[global]\nsned = true\n",
+		"[accounts.alice]\naddress = \"0102\"\n",
+		"[accounts.alice]\naddress = \"0102030405060708090A0B0C0D0E0F1011121314\"\nperms = { cal = true }\n",
+		"[accounts.alice]\naddress = \"0102030405060708090A0B0C0D0E0F1011121314\"\nroles = [\"ops\", \"ops\"]\n",
+		"[accounts.alice]\naddress = \"0102030405060708090A0B0C0D0E0F1011121314\"\n[accounts.bob]\naddress = \"0102030405060708090A0B0C0D0E0F1011121314\"\n",
+		"[acounts.alice]\n",
+	}
+	for _, b := range bad {
+		file := writePolicy(t, b)
+		_, err := LoadPolicy(file)
+		os.Remove(file)
+		if err == nil {
+			t.Fatalf("expected error for %q", b)
+		}
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	node := fakenode.New(fakenode.Config{Accounts: 2})
+	defer node.Close()
+	root, alice := node.Accounts[0], node.Accounts[1]
+	pub := root.PubKey.(acm.PubKeyEd25519)
+	rpc := cclient.NewClient(node.URL, "JSONRPC")
+	getAccount := func(addr []byte) (*acm.Account, error) {
+		r, err := rpc.GetAccount(addr)
+		if err != nil || r == nil {
+			return nil, err
+		}
+		return r.Account, nil
+	}
+	plan := func(policy string) []Change {
+		file := writePolicy(t, policy)
+		defer os.Remove(file)
+		p, err := LoadPolicy(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		changes, err := corePlan(p, getAccount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return changes
+	}
+	apply := func(changes []Change, pubkey, addr string) {
+		err := coreApply(node.ChainID, node.URL, node.KeysURL, pubkey, addr, changes, true, ioutil.Discard)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// alice starts with the defaults, every set bit on
+	policy := fmt.Sprintf(`
+[global]
+name = false
+send = true
+
+[accounts.alice]
+address = "%X"
+roles = ["ops"]
+
+[accounts.alice.perms]
+call = false
+bond = true
+`, alice.Address)
+	changes := plan(policy)
+	counts := make(map[string]int)
+	for _, c := range changes {
+		counts[c.Func]++
+	}
+	if len(changes) != 15 || counts["set_global"] != 1 || counts["set_base"] != 1 || counts["unset_base"] != 12 || counts["add_role"] != 1 {
+		t.Fatalf("bad plan %v", changes)
+	}
+	apply(changes, fmt.Sprintf("%X", pub[:]), "")
+	if changes := plan(policy); len(changes) != 0 {
+		t.Fatalf("expected no changes after apply, got %v", changes)
+	}
+
+	// swap a role and give call back
+	policy = fmt.Sprintf(`
+[accounts.alice]
+address = "%X"
+perms = { bond = true }
+roles = ["audit"]
+`, alice.Address)
+	changes = plan(policy)
+	if len(changes) != 3 || changes[0].Func != "unset_base" || changes[1].Func != "add_role" || changes[2].String() != fmt.Sprintf("alice\trm_role %X ops", alice.Address) {
+		t.Fatalf("bad plan %v", changes)
+	}
+	// with just --addr, the pubkey comes from the keys daemon
+	apply(changes, "", fmt.Sprintf("%X", root.Address))
+	if changes := plan(policy); len(changes) != 0 {
+		t.Fatalf("expected no changes after apply, got %v", changes)
+	}
+
+	// accounts must exist
+	file := writePolicy(t, "[accounts.nobody]\naddress = \"0102030405060708090A0B0C0D0E0F1011121314\"\n")
+	defer os.Remove(file)
+	p, err := LoadPolicy(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := corePlan(p, getAccount); err == nil {
+		t.Fatal("expected error for an account that doesn't exist")
+	}
+}
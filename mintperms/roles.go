@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	. "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/eris-ltd/common/go/common"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/spf13/cobra"
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------------
+// who has which roles, and who holds permissions worth reviewing
+
+// permissions an access review should know about when they're granted on an account
+var DangerousPerms = []ptypes.PermFlag{
+	ptypes.Root,
+	ptypes.CreateContract,
+	ptypes.Bond,
+	ptypes.SetBase,
+	ptypes.UnsetBase,
+	ptypes.SetGlobal,
+	ptypes.AddRole,
+	ptypes.RmRole,
+}
+
+type RoleMember struct {
+	Address []byte   `json:"address"`
+	Names   []string `json:"names,omitempty"` // name reg entries it owns
+}
+
+type Role struct {
+	Role    string       `json:"role"`
+	Members []RoleMember `json:"members"`
+}
+
+type DangerousGrant struct {
+	Address []byte   `json:"address"`
+	Global  bool     `json:"global,omitempty"` // the global permissions account
+	Names   []string `json:"names,omitempty"`
+	Perms   []string `json:"perms"`
+}
+
+type RolesReport struct {
+	BlockHeight int              `json:"block_height"`
+	Roles       []Role           `json:"roles"`
+	Dangerous   []DangerousGrant `json:"dangerous"`
+}
+
+// the roles, sorted, with their members, or just role if it's given.
+// Dangerous permissions only count if they're set on the account, not inherited
+func coreRoles(accounts []*acm.Account, names []*types.NameRegEntry, role string) *RolesReport {
+	owned := make(map[string][]string)
+	for _, entry := range names {
+		owned[string(entry.Owner)] = append(owned[string(entry.Owner)], entry.Name)
+	}
+	for _, n := range owned {
+		sort.Strings(n)
+	}
+	sort.Sort(accountsByAddress(accounts))
+
+	report := &RolesReport{Roles: []Role{}, Dangerous: []DangerousGrant{}}
+	members := make(map[string][]RoleMember)
+	for _, acc := range accounts {
+		member := RoleMember{Address: acc.Address, Names: owned[string(acc.Address)]}
+		for _, r := range acc.Permissions.Roles {
+			// roles are stored left padded to a word
+			r = strings.TrimLeft(r, "\x00")
+			if role == "" || r == role {
+				members[r] = append(members[r], member)
+			}
+		}
+
+		var perms []string
+		for _, pf := range DangerousPerms {
+			if v, err := acc.Permissions.Base.Get(pf); err == nil && v {
+				perms = append(perms, ptypes.PermFlagToString(pf))
+			}
+		}
+		if len(perms) > 0 {
+			report.Dangerous = append(report.Dangerous, DangerousGrant{
+				Address: acc.Address,
+				Global:  bytes.Equal(acc.Address, ptypes.GlobalPermissionsAddress),
+				Names:   member.Names,
+				Perms:   perms,
+			})
+		}
+	}
+
+	var roles []string
+	for r := range members {
+		roles = append(roles, r)
+	}
+	sort.Strings(roles)
+	for _, r := range roles {
+		report.Roles = append(report.Roles, Role{Role: r, Members: members[r]})
+	}
+	if role != "" && len(report.Roles) == 0 {
+		report.Roles = append(report.Roles, Role{Role: role, Members: []RoleMember{}})
+	}
+	return report
+}
+
+type accountsByAddress []*acm.Account
+
+func (a accountsByAddress) Len() int           { return len(a) }
+func (a accountsByAddress) Less(i, j int) bool { return bytes.Compare(a[i].Address, a[j].Address) < 0 }
+func (a accountsByAddress) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+func cliRoles(cmd *cobra.Command, args []string) {
+	rpc := cclient.NewClient(nodeAddr, "JSONRPC")
+	accounts, err := rpc.ListAccounts()
+	IfExit(err)
+	names, err := rpc.ListNames()
+	IfExit(err)
+
+	report := coreRoles(accounts.Accounts, names.Names, role)
+	report.BlockHeight = accounts.BlockHeight
+	if jsonOutput {
+		b, err := json.MarshalIndent(report, "", "\t")
+		IfExit(err)
+		fmt.Println(string(b))
+		return
+	}
+	printRoles(os.Stdout, report)
+}
+
+func printRoles(out io.Writer, report *RolesReport) {
+	fmt.Fprintf(out, "Roles at height %d\n", report.BlockHeight)
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	for _, r := range report.Roles {
+		fmt.Fprintf(w, "\n%s (%d members)\n", r.Role, len(r.Members))
+		for _, m := range r.Members {
+			fmt.Fprintf(w, "  %X\t%s\n", m.Address, strings.Join(m.Names, ", "))
+		}
+	}
+	if len(report.Roles) == 0 {
+		fmt.Fprintln(w, "\nNo accounts have roles")
+	}
+	w.Flush()
+
+	fmt.Fprintf(out, "\nDangerous permissions granted on the account (%s)\n", permNames(DangerousPerms))
+	w = tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	for _, d := range report.Dangerous {
+		who := strings.Join(d.Names, ", ")
+		if d.Global {
+			who = "global"
+		}
+		fmt.Fprintf(w, "  %X\t%s\t%s\n", d.Address, strings.Join(d.Perms, ", "), who)
+	}
+	if len(report.Dangerous) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	w.Flush()
+}
+
+func permNames(perms []ptypes.PermFlag) string {
+	names := make([]string, len(perms))
+	for i, pf := range perms {
+		names[i] = ptypes.PermFlagToString(pf)
+	}
+	return strings.Join(names, ", ")
+}
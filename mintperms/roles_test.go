@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	acm "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+func TestRoles(t *testing.T) {
+	account := func(addr string, roles ...string) *acm.Account {
+		acc := &acm.Account{Address: []byte(addr), Permissions: ptypes.ZeroAccountPermissions}
+		for _, r := range roles {
+			acc.Permissions.AddRole(r)
+		}
+		return acc
+	}
+	global := account(string(ptypes.GlobalPermissionsAddress))
+	global.Permissions.Base = ptypes.DefaultAccountPermissions.Base
+	bob := account("bob", "admin")
+	bob.Permissions.Base.Set(ptypes.Root, true)
+	bob.Permissions.Base.Set(ptypes.Bond, false)
+	carol := account("carol", "ops")
+	carol.Permissions.Base.Set(ptypes.Bond, true)
+	accounts := []*acm.Account{carol, bob, global, account("alice", "ops", "admin")}
+	names := []*types.NameRegEntry{{Name: "bob.io", Owner: []byte("bob")}, {Name: "b", Owner: []byte("bob")}}
+
+	report := coreRoles(accounts, names, "")
+	if len(report.Roles) != 2 || report.Roles[0].Role != "admin" || report.Roles[1].Role != "ops" {
+		t.Fatalf("bad roles %v", report.Roles)
+	}
+	admins := report.Roles[0].Members
+	if len(admins) != 2 || string(admins[0].Address) != "alice" || strings.Join(admins[1].Names, ",") != "b,bob.io" {
+		t.Fatalf("bad admins %v", admins)
+	}
+
+	// global's bits count too, but bob's bond is off and carol's isn't
+	if len(report.Dangerous) != 3 {
+		t.Fatalf("bad dangerous %v", report.Dangerous)
+	}
+	for _, d := range report.Dangerous {
+		switch string(d.Address) {
+		case "bob":
+			if strings.Join(d.Perms, ",") != "root" {
+				t.Fatalf("bad perms for bob %v", d.Perms)
+			}
+		case "carol":
+			if strings.Join(d.Perms, ",") != "bond" {
+				t.Fatalf("bad perms for carol %v", d.Perms)
+			}
+		default:
+			if !d.Global || strings.Join(d.Perms, ",") != "create_contract,bond" {
+				t.Fatalf("bad global grant %v", d)
+			}
+		}
+	}
+
+	report = coreRoles(accounts, names, "ops")
+	if len(report.Roles) != 1 || len(report.Roles[0].Members) != 2 {
+		t.Fatalf("bad ops role %v", report.Roles)
+	}
+	report = coreRoles(accounts, names, "nobody")
+	if len(report.Roles) != 1 || len(report.Roles[0].Members) != 0 {
+		t.Fatalf("expected an empty role, got %v", report.Roles)
+	}
+
+	buf := new(bytes.Buffer)
+	printRoles(buf, coreRoles(accounts, names, ""))
+	if !strings.Contains(buf.String(), "admin (2 members)") {
+		t.Fatalf("bad output:\n%s", buf)
+	}
+}
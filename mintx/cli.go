@@ -3,6 +3,11 @@ package main
 import (
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/eris-ltd/mint-client/mintx/core"
 
@@ -31,7 +36,7 @@ func cliSend(c *cli.Context) {
 	chainID, nodeAddr, signAddr := c.String("chainID"), c.String("node-addr"), c.String("sign-addr")
 	sign, broadcast, wait := c.Bool("sign"), c.Bool("broadcast"), c.Bool("wait")
 	pubkey, amtS, nonceS, addr, toAddr := c.String("pubkey"), c.String("amt"), c.String("nonce"), c.String("addr"), c.String("to")
-	tx, err := core.Send(nodeAddr, pubkey, addr, toAddr, amtS, nonceS)
+	tx, err := core.Send(nodeAddr, signAddr, pubkey, addr, toAddr, amtS, nonceS)
 	common.IfExit(err)
 	logger.Debugf("%v\n", tx)
 	unpackSignAndBroadcast(core.SignAndBroadcast(chainID, nodeAddr, signAddr, tx, sign, broadcast, wait))
@@ -51,18 +56,175 @@ func cliName(c *cli.Context) {
 		common.IfExit(err)
 		data = string(b)
 	}
-	tx, err := core.Name(nodeAddr, pubkey, addr, amtS, nonceS, feeS, name, data)
+	common.IfExit(core.CheckNameData(name, data, c.String("schema")))
+	tx, err := core.Name(nodeAddr, signAddr, pubkey, addr, amtS, nonceS, feeS, name, data)
 	common.IfExit(err)
 	logger.Debugf("%v\n", tx)
 	unpackSignAndBroadcast(core.SignAndBroadcast(chainID, nodeAddr, signAddr, tx, sign, broadcast, wait))
 }
 
+func cliDNSSet(c *cli.Context) {
+	chainID, nodeAddr, signAddr := c.String("chainID"), c.String("node-addr"), c.String("sign-addr")
+	sign, broadcast, wait := c.Bool("sign"), c.Bool("broadcast"), c.Bool("wait")
+	pubkey, nonceS, feeS, addr := c.String("pubkey"), c.String("nonce"), c.String("fee"), c.String("addr")
+	fqdn, typ, value, ttlBlocksS := c.String("fqdn"), c.String("type"), c.String("value"), c.String("ttl-blocks")
+	tx, err := core.DNSSet(nodeAddr, signAddr, pubkey, addr, nonceS, feeS, fqdn, typ, value, ttlBlocksS)
+	common.IfExit(err)
+	fmt.Printf("Record: %s\nAmount: %d (fee %d)\n", tx.Data, tx.Input.Amount, tx.Fee)
+	logger.Debugf("%v\n", tx)
+	unpackSignAndBroadcast(core.SignAndBroadcast(chainID, nodeAddr, signAddr, tx, sign, broadcast, wait))
+}
+
+func cliDNSRm(c *cli.Context) {
+	chainID, nodeAddr, signAddr := c.String("chainID"), c.String("node-addr"), c.String("sign-addr")
+	sign, broadcast, wait := c.Bool("sign"), c.Bool("broadcast"), c.Bool("wait")
+	pubkey, nonceS, feeS, addr := c.String("pubkey"), c.String("nonce"), c.String("fee"), c.String("addr")
+	tx, err := core.DNSRm(nodeAddr, signAddr, pubkey, addr, nonceS, feeS, c.String("fqdn"))
+	common.IfExit(err)
+	logger.Debugf("%v\n", tx)
+	unpackSignAndBroadcast(core.SignAndBroadcast(chainID, nodeAddr, signAddr, tx, sign, broadcast, wait))
+}
+
+func cliDNSRenew(c *cli.Context) {
+	chainID, nodeAddr, signAddr := c.String("chainID"), c.String("node-addr"), c.String("sign-addr")
+	sign, broadcast, wait := c.Bool("sign"), c.Bool("broadcast"), c.Bool("wait")
+	pubkey, nonceS, feeS, addr := c.String("pubkey"), c.String("nonce"), c.String("fee"), c.String("addr")
+	tx, err := core.DNSRenew(nodeAddr, signAddr, pubkey, addr, nonceS, feeS, c.String("fqdn"), c.String("ttl-blocks"))
+	common.IfExit(err)
+	fmt.Printf("Record: %s\nAmount: %d (fee %d)\n", tx.Data, tx.Input.Amount, tx.Fee)
+	logger.Debugf("%v\n", tx)
+	unpackSignAndBroadcast(core.SignAndBroadcast(chainID, nodeAddr, signAddr, tx, sign, broadcast, wait))
+}
+
+func cliImportZone(c *cli.Context) {
+	chainID, nodeAddr, signAddr := c.String("chainID"), c.String("node-addr"), c.String("sign-addr")
+	sign, broadcast, wait := c.Bool("sign"), c.Bool("broadcast"), c.Bool("wait")
+	pubkey, nonceS, feeS, addr := c.String("pubkey"), c.String("nonce"), c.String("fee"), c.String("addr")
+
+	if len(c.Args()) != 1 {
+		common.Exit(fmt.Errorf("Please specify the zone file to import"))
+	}
+	f, err := os.Open(c.Args()[0])
+	common.IfExit(err)
+	zone, err := core.ParseZone(f, c.String("origin"))
+	f.Close()
+	common.IfExit(err)
+	if zone.Origin == "" {
+		common.Exit(fmt.Errorf("The zone file has no $ORIGIN. Please specify one with --origin"))
+	}
+	for _, s := range zone.Skipped {
+		fmt.Printf("Skipping %v\n", s)
+	}
+
+	imp, err := core.ImportZone(nodeAddr, signAddr, pubkey, addr, nonceS, feeS, c.String("ttl-blocks"), zone)
+	common.IfExit(err)
+	for _, change := range imp.Changes {
+		fmt.Println(change)
+	}
+	fmt.Printf("%d txs, total amount %d (fees included)\n", len(imp.Txs), imp.Total)
+
+	for _, tx := range imp.Txs {
+		logger.Debugf("%v\n", tx)
+		unpackSignAndBroadcast(core.SignAndBroadcast(chainID, nodeAddr, signAddr, tx, sign, broadcast, wait))
+	}
+}
+
+// sign and broadcast renewals for our names as they get close to expiring
+func cliRenewDaemon(c *cli.Context) {
+	chainID, nodeAddr, signAddr := c.String("chainID"), c.String("node-addr"), c.String("sign-addr")
+	wait := c.Bool("wait")
+	pubkey, feeS, addr := c.String("pubkey"), c.String("fee"), c.String("addr")
+
+	var names []string
+	if c.String("names") != "" {
+		names = strings.Split(c.String("names"), ",")
+	}
+	renewer, err := core.NewRenewer(nodeAddr, signAddr, pubkey, addr, feeS, c.String("ttl-blocks"), c.Int("renew-within"), names)
+	common.IfExit(err)
+
+	for {
+		txs, err := renewer.Check()
+		if err != nil {
+			logger.Errorln(err)
+		}
+		for _, tx := range txs {
+			fmt.Printf("Renewing %s for %s blocks (amount %d)\n", tx.Name, c.String("ttl-blocks"), tx.Input.Amount)
+			result, err := core.SignAndBroadcast(chainID, nodeAddr, signAddr, tx, true, true, wait)
+			if result == nil {
+				// it wasn't broadcast, and the rest have the nonces after this one so they'd fail too.
+				// The next check builds them again with the node's nonce
+				logger.Errorf("Error renewing %s: %v\n", tx.Name, err)
+				break
+			}
+			renewer.Sent(tx)
+			fmt.Printf("Transaction Hash: %X\n", result.Hash)
+			if err != nil {
+				logger.Errorf("Error waiting for the renewal of %s: %v\n", tx.Name, err)
+			}
+		}
+		time.Sleep(c.Duration("interval"))
+	}
+}
+
+func cliFaucet(c *cli.Context) {
+	chainID, nodeAddr, signAddr := c.String("chainID"), c.String("node-addr"), c.String("sign-addr")
+	if c.String("from") == "" {
+		common.Exit(fmt.Errorf("Please specify the address of the key to send from with --from"))
+	}
+	amount, err := strconv.ParseInt(c.String("amount"), 10, 64)
+	if err != nil {
+		common.Exit(fmt.Errorf("amount is misformatted: %v", err))
+	}
+	var budget int64
+	if c.String("daily-budget") != "" {
+		budget, err = strconv.ParseInt(c.String("daily-budget"), 10, 64)
+		if err != nil {
+			common.Exit(fmt.Errorf("daily-budget is misformatted: %v", err))
+		}
+	}
+	pub, err := core.Pub(c.String("from"), signAddr)
+	common.IfExit(err)
+
+	faucet, err := core.NewFaucet(core.FaucetConfig{
+		ChainID:      chainID,
+		NodeAddr:     nodeAddr,
+		SignAddr:     signAddr,
+		Pub:          pub,
+		Amount:       amount,
+		AddrInterval: c.Duration("addr-limit"),
+		IPInterval:   c.Duration("ip-limit"),
+		DailyBudget:  budget,
+		CheckPerms:   c.Bool("check-perms"),
+	})
+	common.IfExit(err)
+	fmt.Printf("Faucet %X sending %d per request on %s\n", faucet.Address(), amount, c.String("listen"))
+	common.IfExit(http.ListenAndServe(c.String("listen"), faucet))
+}
+
+func cliServe(c *cli.Context) {
+	chainID, nodeAddr, signAddr := c.String("chainID"), c.String("node-addr"), c.String("sign-addr")
+	if c.String("tokens") == "" {
+		common.Exit(fmt.Errorf("Please specify a file of api tokens with --tokens"))
+	}
+	tokens, err := core.LoadGatewayTokens(c.String("tokens"))
+	common.IfExit(err)
+	gateway, err := core.NewGateway(core.GatewayConfig{
+		ChainID:  chainID,
+		NodeAddr: nodeAddr,
+		SignAddr: signAddr,
+		Tokens:   tokens,
+	})
+	common.IfExit(err)
+	fmt.Printf("Serving txs for %d tokens on %s\n", len(tokens), c.String("listen"))
+	common.IfExit(http.ListenAndServe(c.String("listen"), gateway))
+}
+
 func cliCall(c *cli.Context) {
 	chainID, nodeAddr, signAddr := c.String("chainID"), c.String("node-addr"), c.String("sign-addr")
 	sign, broadcast, wait := c.Bool("sign"), c.Bool("broadcast"), c.Bool("wait")
 	pubkey, amtS, nonceS, feeS, addr := c.String("pubkey"), c.String("amt"), c.String("nonce"), c.String("fee"), c.String("addr")
 	toAddr, gasS, data := c.String("to"), c.String("gas"), c.String("data")
-	tx, err := core.Call(nodeAddr, pubkey, addr, toAddr, amtS, nonceS, gasS, feeS, data)
+	tx, err := core.Call(nodeAddr, signAddr, pubkey, addr, toAddr, amtS, nonceS, gasS, feeS, data)
 	common.IfExit(err)
 	logger.Debugf("%v\n", tx)
 	unpackSignAndBroadcast(core.SignAndBroadcast(chainID, nodeAddr, signAddr, tx, sign, broadcast, wait))
@@ -73,15 +235,13 @@ func cliPermissions(c *cli.Context) {
 	sign, broadcast, wait := c.Bool("sign"), c.Bool("broadcast"), c.Bool("wait")
 	pubkey, nonceS, addr := c.String("pubkey"), c.String("nonce"), c.String("addr")
 
-	// all functions take at least 2 args (+ name)
-	if len(c.Args()) < 3 {
-		common.Exit(fmt.Errorf("Please enter the permission function you'd like to call, followed by it's arguments"))
-	}
-	permFunc := c.Args()[0]
-	tx, err := core.Permissions(nodeAddr, pubkey, addr, nonceS, permFunc, c.Args()[1:])
+	// each change is checked before anything is signed, and sent as its own tx
+	txs, err := core.PermissionsBatch(nodeAddr, signAddr, pubkey, addr, nonceS, c.Args())
 	common.IfExit(err)
-	logger.Debugf("%v\n", tx)
-	unpackSignAndBroadcast(core.SignAndBroadcast(chainID, nodeAddr, signAddr, tx, sign, broadcast, wait))
+	for _, tx := range txs {
+		logger.Debugf("%v\n", tx)
+		unpackSignAndBroadcast(core.SignAndBroadcast(chainID, nodeAddr, signAddr, tx, sign, broadcast, wait))
+	}
 }
 
 func cliNewAccount(c *cli.Context) {
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	rtypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core/types"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------------
+// sign and broadcast
+
+// the address a tx is from. For SendTx and BondTx that's the first input's
+// TODO: better support for multisig
+func InputAddress(tx types.Tx) []byte {
+	switch tx := tx.(type) {
+	case *types.SendTx:
+		if len(tx.Inputs) > 0 {
+			return tx.Inputs[0].Address
+		}
+	case *types.NameTx:
+		return tx.Input.Address
+	case *types.CallTx:
+		return tx.Input.Address
+	case *types.PermissionsTx:
+		return tx.Input.Address
+	case *types.BondTx:
+		if len(tx.Inputs) > 0 {
+			return tx.Inputs[0].Address
+		}
+	case *types.UnbondTx:
+		return tx.Address
+	case *types.RebondTx:
+		return tx.Address
+	}
+	return nil
+}
+
+// sign tx in place with the key for its input address
+func (c *Client) Sign(ctx context.Context, tx types.Tx) error {
+	if c.Signer == nil {
+		return ErrNoSigner
+	}
+	inputAddr := InputAddress(tx)
+	if len(inputAddr) == 0 {
+		return ErrNoInputs
+	}
+	sig, err := c.Signer.Sign(ctx, inputAddr, account.SignBytes(c.ChainID, tx))
+	if err != nil {
+		return err
+	}
+	logger.Debugf("SIG: %X\n", sig)
+	switch tx := tx.(type) {
+	case *types.SendTx:
+		tx.Inputs[0].Signature = sig
+	case *types.NameTx:
+		tx.Input.Signature = sig
+	case *types.CallTx:
+		tx.Input.Signature = sig
+	case *types.PermissionsTx:
+		tx.Input.Signature = sig
+	case *types.BondTx:
+		tx.Signature = sig
+		tx.Inputs[0].Signature = sig
+	case *types.UnbondTx:
+		tx.Signature = sig
+	case *types.RebondTx:
+		tx.Signature = sig
+	}
+	return nil
+}
+
+func (c *Client) Broadcast(ctx context.Context, tx types.Tx) (*rtypes.Receipt, error) {
+	var receipt rtypes.Receipt
+	err := c.rpc(ctx, func(rpc cclient.Client) error {
+		rec, err := rpc.BroadcastTx(tx)
+		if err == nil {
+			receipt = rec.Receipt
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+type TxResult struct {
+	BlockHash []byte // all txs get in a block
+	Hash      []byte // all txs get a hash
+
+	// only CallTx
+	Address   []byte // only for new contracts
+	Return    []byte
+	Exception string
+
+	//TODO: make Broadcast() errors more responsive so we
+	// can differentiate mempool errors from other
+}
+
+// sign, broadcast and wait for the tx to be committed, as asked.
+// The result is nil if the tx isn't broadcast. If waiting fails,
+// the result from broadcasting is returned with the error
+func (c *Client) SignAndBroadcast(ctx context.Context, tx types.Tx, sign, broadcast, wait bool) (*TxResult, error) {
+	if sign {
+		if err := c.Sign(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+	if !broadcast {
+		return nil, nil
+	}
+
+	// subscribe before broadcasting so we can't miss the event
+	var events <-chan Msg
+	if wait {
+		if c.Events == nil {
+			return nil, ErrNoEvents
+		}
+		timeout := c.WaitTimeout
+		if timeout == 0 {
+			timeout = DefaultWaitTimeout
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		var err error
+		if events, err = c.Events.Subscribe(ctx, c.ChainID, tx, InputAddress(tx)); err != nil {
+			return nil, err
+		}
+	}
+
+	receipt, err := c.Broadcast(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	result := &TxResult{
+		Hash: receipt.TxHash,
+	}
+	if callTx, ok := tx.(*types.CallTx); ok && len(callTx.Address) == 0 {
+		result.Address = types.NewContractAddress(callTx.Input.Address, callTx.Input.Sequence)
+	}
+	if !wait {
+		return result, nil
+	}
+
+	logger.Debugln("Waiting for tx to be committed ...")
+	select {
+	case msg := <-events:
+		if msg.Error != nil {
+			logger.Infof("Encountered error waiting for event: %v\n", msg.Error)
+			return result, msg.Error
+		}
+		result.BlockHash = msg.BlockHash
+		result.Return = msg.Value
+		result.Exception = msg.Exception
+		return result, nil
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return result, ErrTxTimeout
+		}
+		return result, ctx.Err()
+	}
+}
@@ -0,0 +1,209 @@
+package client
+
+import (
+	"context"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	ctypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core/types"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------------
+// building txs
+
+// Input is who a tx is from. Only one of PubKey and Address is needed:
+// without a PubKey, it's fetched from the Signer.
+// If Nonce is 0, the next one is fetched from the node
+type Input struct {
+	PubKey  account.PubKey
+	Address []byte
+	Nonce   int
+}
+
+type SendOpts struct {
+	Input
+	To     []byte
+	Amount int64
+}
+
+type CallOpts struct {
+	Input
+	To       []byte // empty to create a contract
+	Amount   int64
+	GasLimit int64
+	Fee      int64
+	Data     []byte
+}
+
+type NameOpts struct {
+	Input
+	Amount int64 // pays for the entry's lease
+	Fee    int64
+	Name   string
+	Data   string
+}
+
+type PermissionsOpts struct {
+	Input
+	Args ptypes.PermArgs
+}
+
+type BondOpts struct {
+	Input
+	Amount   int64
+	UnbondTo []byte // defaults to the bonder's address
+}
+
+type UnbondOpts struct {
+	Address []byte // the validator's
+	Height  int
+}
+
+type RebondOpts struct {
+	Address []byte // the validator's
+	Height  int
+}
+
+// the pubkey and nonce for an input, fetching whichever weren't given
+func (c *Client) ResolveInput(ctx context.Context, in Input) (account.PubKey, int, error) {
+	pub := in.PubKey
+	if pub == nil {
+		if len(in.Address) == 0 || c.Signer == nil {
+			return nil, 0, ErrNoPubKey
+		}
+		var err error
+		if pub, err = c.Signer.PubKey(ctx, in.Address); err != nil {
+			return nil, 0, err
+		}
+	}
+	if in.Nonce > 0 {
+		return pub, in.Nonce, nil
+	}
+	if in.Nonce < 0 {
+		return nil, 0, argErrorf("nonce", "must not be negative")
+	}
+	nonce, err := c.NextNonce(ctx, pub.Address())
+	return pub, nonce, err
+}
+
+// the nonce the next tx from addr should have
+func (c *Client) NextNonce(ctx context.Context, addr []byte) (int, error) {
+	var ac *ctypes.ResultGetAccount
+	err := c.rpc(ctx, func(rpc cclient.Client) (err error) {
+		ac, err = rpc.GetAccount(addr)
+		return
+	})
+	if err != nil {
+		return 0, err
+	}
+	if ac == nil || ac.Account == nil {
+		return 0, &UnknownAccountError{addr}
+	}
+	return ac.Account.Sequence + 1, nil
+}
+
+func checkAmount(arg string, amt int64) error {
+	if amt < 0 {
+		return argErrorf(arg, "must not be negative")
+	}
+	return nil
+}
+
+func (c *Client) Send(ctx context.Context, opts SendOpts) (*types.SendTx, error) {
+	if len(opts.To) == 0 {
+		return nil, argErrorf("to", "must be given")
+	}
+	if err := checkAmount("amount", opts.Amount); err != nil {
+		return nil, err
+	}
+	pub, nonce, err := c.ResolveInput(ctx, opts.Input)
+	if err != nil {
+		return nil, err
+	}
+	tx := types.NewSendTx()
+	tx.AddInputWithNonce(pub, opts.Amount, nonce)
+	tx.AddOutput(opts.To, opts.Amount)
+	return tx, nil
+}
+
+func (c *Client) Call(ctx context.Context, opts CallOpts) (*types.CallTx, error) {
+	if err := checkAmount("amount", opts.Amount); err != nil {
+		return nil, err
+	}
+	if err := checkAmount("gas", opts.GasLimit); err != nil {
+		return nil, err
+	}
+	if err := checkAmount("fee", opts.Fee); err != nil {
+		return nil, err
+	}
+	pub, nonce, err := c.ResolveInput(ctx, opts.Input)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewCallTxWithNonce(pub, opts.To, opts.Data, opts.Amount, opts.GasLimit, opts.Fee, nonce), nil
+}
+
+func (c *Client) Name(ctx context.Context, opts NameOpts) (*types.NameTx, error) {
+	if opts.Name == "" {
+		return nil, argErrorf("name", "must be given")
+	}
+	if err := checkAmount("amount", opts.Amount); err != nil {
+		return nil, err
+	}
+	if err := checkAmount("fee", opts.Fee); err != nil {
+		return nil, err
+	}
+	pub, nonce, err := c.ResolveInput(ctx, opts.Input)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewNameTxWithNonce(pub, opts.Name, opts.Data, opts.Amount, opts.Fee, nonce), nil
+}
+
+func (c *Client) Permissions(ctx context.Context, opts PermissionsOpts) (*types.PermissionsTx, error) {
+	if opts.Args == nil {
+		return nil, argErrorf("args", "must be given")
+	}
+	pub, nonce, err := c.ResolveInput(ctx, opts.Input)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewPermissionsTxWithNonce(pub, opts.Args, nonce), nil
+}
+
+func (c *Client) Bond(ctx context.Context, opts BondOpts) (*types.BondTx, error) {
+	if err := checkAmount("amount", opts.Amount); err != nil {
+		return nil, err
+	}
+	pub, nonce, err := c.ResolveInput(ctx, opts.Input)
+	if err != nil {
+		return nil, err
+	}
+	unbondTo := opts.UnbondTo
+	if len(unbondTo) == 0 {
+		unbondTo = pub.Address()
+	}
+	tx, err := types.NewBondTx(pub)
+	if err != nil {
+		return nil, argErrorf("pubkey", "%v", err)
+	}
+	tx.AddInputWithNonce(pub, opts.Amount, nonce)
+	tx.AddOutput(unbondTo, opts.Amount)
+	return tx, nil
+}
+
+func (c *Client) Unbond(opts UnbondOpts) (*types.UnbondTx, error) {
+	if len(opts.Address) == 0 {
+		return nil, argErrorf("validator address", "must be given")
+	}
+	return types.NewUnbondTx(opts.Address, opts.Height), nil
+}
+
+func (c *Client) Rebond(opts RebondOpts) (*types.RebondTx, error) {
+	if len(opts.Address) == 0 {
+		return nil, argErrorf("validator address", "must be given")
+	}
+	return types.NewRebondTx(opts.Address, opts.Height), nil
+}
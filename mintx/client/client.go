@@ -0,0 +1,60 @@
+// Package client builds, signs, broadcasts and waits on transactions.
+//
+// It's the typed api behind mintx: mintx/core parses the cli's string arguments
+// and calls in here. Everything that does io takes a context, and the node,
+// the signer and the event subscriber can all be swapped out.
+package client
+
+import (
+	"context"
+	"time"
+
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+)
+
+// how long SignAndBroadcast waits for a tx to be committed, if the context doesn't say
+const DefaultWaitTimeout = 10 * time.Second
+
+type Client struct {
+	ChainID string
+
+	RPC    cclient.Client // the node. Needed to fetch nonces and broadcast
+	Signer Signer         // needed to sign, and to build txs from an address without a pubkey
+	Events Subscriber     // needed to wait on txs
+
+	WaitTimeout time.Duration
+}
+
+// a client for the node at nodeAddr, signing with signer.
+// Either may be empty, for a client that only builds txs
+func New(chainID, nodeAddr string, signer Signer) *Client {
+	c := &Client{
+		ChainID:     chainID,
+		Signer:      signer,
+		WaitTimeout: DefaultWaitTimeout,
+	}
+	if nodeAddr != "" {
+		c.RPC = cclient.NewClient(nodeAddr, "JSONRPC")
+		c.Events = NewWSSubscriber(nodeAddr)
+	}
+	return c
+}
+
+// make an rpc call, giving up if ctx is done first.
+// The call itself can't be cancelled, so it's left to finish in the background
+func (c *Client) rpc(ctx context.Context, call func(cclient.Client) error) error {
+	if c.RPC == nil {
+		return ErrNoNode
+	}
+	done := make(chan error, 1)
+	go func() { done <- call(c.RPC) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			return &NodeError{err}
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,198 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	ctypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core/types"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+type fakeNode struct {
+	cclient.Client
+	accounts map[string]*account.Account
+	txs      []types.Tx
+	block    chan struct{} // if set, calls hang until it's closed
+}
+
+func (f *fakeNode) GetAccount(addr []byte) (*ctypes.ResultGetAccount, error) {
+	if f.block != nil {
+		<-f.block
+	}
+	return &ctypes.ResultGetAccount{Account: f.accounts[string(addr)]}, nil
+}
+
+func (f *fakeNode) BroadcastTx(tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
+	f.txs = append(f.txs, tx)
+	return &ctypes.ResultBroadcastTx{Receipt: ctypes.Receipt{TxHash: []byte{0xAB}}}, nil
+}
+
+type fakeSigner struct {
+	key *account.PrivAccount
+}
+
+func (f *fakeSigner) Sign(ctx context.Context, addr, signBytes []byte) (account.SignatureEd25519, error) {
+	if !bytes.Equal(addr, f.key.Address) {
+		return account.SignatureEd25519{}, &SignerError{fmt.Errorf("unknown key %X", addr)}
+	}
+	return f.key.PrivKey.Sign(signBytes).(account.SignatureEd25519), nil
+}
+
+func (f *fakeSigner) PubKey(ctx context.Context, addr []byte) (account.PubKey, error) {
+	if !bytes.Equal(addr, f.key.Address) {
+		return nil, &SignerError{fmt.Errorf("unknown key %X", addr)}
+	}
+	return f.key.PubKey, nil
+}
+
+type fakeEvents chan Msg
+
+func (f fakeEvents) Subscribe(ctx context.Context, chainID string, tx types.Tx, inputAddr []byte) (<-chan Msg, error) {
+	return f, nil
+}
+
+func testClient() (*Client, *fakeNode, *account.PrivAccount) {
+	key := account.GenPrivAccountFromSecret("client")
+	node := &fakeNode{accounts: map[string]*account.Account{
+		string(key.Address): {Address: key.Address, Sequence: 4},
+	}}
+	c := New("test_chain", "", &fakeSigner{key})
+	c.RPC = node
+	return c, node, key
+}
+
+func TestBuild(t *testing.T) {
+	c, _, key := testClient()
+	ctx := context.Background()
+
+	// the pubkey comes from the signer, and the nonce from the node
+	tx, err := c.Name(ctx, NameOpts{Input: Input{Address: key.Address}, Amount: 10, Name: "x", Data: "y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Input.Sequence != 5 || tx.Input.PubKey == nil || tx.Name != "x" {
+		t.Fatalf("bad tx %v", tx)
+	}
+	send, err := c.Send(ctx, SendOpts{Input: Input{PubKey: key.PubKey, Nonce: 9}, To: key.Address, Amount: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if send.Inputs[0].Sequence != 9 {
+		t.Fatalf("bad nonce %d", send.Inputs[0].Sequence)
+	}
+
+	if _, err := c.Send(ctx, SendOpts{Input: Input{PubKey: key.PubKey}, Amount: 1}); err == nil {
+		t.Fatal("expected error for missing to")
+	} else if aerr, ok := err.(*ArgError); !ok || aerr.Arg != "to" {
+		t.Fatalf("expected ArgError for to, got %v", err)
+	}
+	if _, err := c.Call(ctx, CallOpts{Input: Input{PubKey: key.PubKey}, Fee: -1}); err == nil {
+		t.Fatal("expected error for negative fee")
+	}
+	other := account.GenPrivAccountFromSecret("other")
+	if _, err := c.Name(ctx, NameOpts{Input: Input{PubKey: other.PubKey}, Name: "x"}); err == nil {
+		t.Fatal("expected error for unknown account")
+	} else if _, ok := err.(*UnknownAccountError); !ok {
+		t.Fatalf("expected UnknownAccountError, got %v", err)
+	}
+	if _, err := c.Name(ctx, NameOpts{Input: Input{Address: other.Address}, Name: "x"}); err == nil {
+		t.Fatal("expected error for unknown key")
+	} else if _, ok := err.(*SignerError); !ok {
+		t.Fatalf("expected SignerError, got %v", err)
+	}
+
+	c.Signer, c.RPC = nil, nil
+	if _, err := c.Name(ctx, NameOpts{Input: Input{Address: key.Address}, Name: "x"}); err != ErrNoPubKey {
+		t.Fatalf("expected ErrNoPubKey, got %v", err)
+	}
+	if _, err := c.Name(ctx, NameOpts{Input: Input{PubKey: key.PubKey}, Name: "x"}); err != ErrNoNode {
+		t.Fatalf("expected ErrNoNode, got %v", err)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	c, node, key := testClient()
+	node.block = make(chan struct{})
+	defer close(node.block)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.NextNonce(ctx, key.Address); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestSignAndBroadcast(t *testing.T) {
+	c, node, key := testClient()
+	events := make(fakeEvents, 1)
+	c.Events = events
+	c.WaitTimeout = 10 * time.Millisecond
+	ctx := context.Background()
+
+	tx, err := c.Call(ctx, CallOpts{Input: Input{Address: key.Address}, GasLimit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events <- Msg{BlockHash: []byte{0x01}, Value: []byte{0x02}}
+	result, err := c.SignAndBroadcast(ctx, tx, true, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.PubKey.VerifyBytes(account.SignBytes("test_chain", tx), tx.Input.Signature) {
+		t.Fatal("bad signature")
+	}
+	if len(node.txs) != 1 || !bytes.Equal(result.Hash, []byte{0xAB}) || !bytes.Equal(result.Return, []byte{0x02}) {
+		t.Fatalf("bad result %v", result)
+	}
+	if !bytes.Equal(result.Address, types.NewContractAddress(key.Address, 5)) {
+		t.Fatalf("bad contract address %X", result.Address)
+	}
+
+	// nothing comes, so we time out but still get the hash
+	result, err = c.SignAndBroadcast(ctx, tx, false, true, true)
+	if err != ErrTxTimeout || result == nil || result.Hash == nil {
+		t.Fatalf("expected timeout with a result, got %v %v", result, err)
+	}
+}
+
+func TestKeysDaemon(t *testing.T) {
+	key := account.GenPrivAccountFromSecret("client")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var args map[string]string
+		json.NewDecoder(r.Body).Decode(&args)
+		resp := keysResponse{}
+		switch {
+		case args["addr"] != fmt.Sprintf("%X", key.Address):
+			resp.Error = "unknown key"
+		case r.URL.Path == "/pub":
+			pub := key.PubKey.(account.PubKeyEd25519)
+			resp.Response = fmt.Sprintf("%X", pub[:])
+		case r.URL.Path == "/sign":
+			resp.Response = fmt.Sprintf("%X", make([]byte, 64))
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	k := NewKeysDaemon(server.URL)
+	pub, err := k.PubKey(context.Background(), key.Address)
+	if err != nil || !bytes.Equal(pub.Address(), key.Address) {
+		t.Fatalf("bad pubkey %v %v", pub, err)
+	}
+	if _, err := k.Sign(context.Background(), key.Address, []byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.PubKey(context.Background(), []byte{1}); err == nil {
+		t.Fatal("expected error for unknown key")
+	} else if _, ok := err.(*SignerError); !ok {
+		t.Fatalf("expected SignerError, got %v", err)
+	}
+}
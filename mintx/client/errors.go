@@ -0,0 +1,56 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoNode    = errors.New("no node to talk to. Set one with --node-addr (or MINTX_NODE_ADDR)")
+	ErrNoSigner  = errors.New("no signer to sign with")
+	ErrNoEvents  = errors.New("no event subscriber to wait for txs with")
+	ErrNoPubKey  = errors.New("a pubkey, or an address and a signer to fetch its pubkey from, must be given")
+	ErrNoInputs  = errors.New("tx has no inputs")
+	ErrTxTimeout = errors.New("timed out waiting for tx to be committed")
+)
+
+// ArgError is a missing or invalid argument
+type ArgError struct {
+	Arg string
+	Msg string
+}
+
+func (e *ArgError) Error() string {
+	return fmt.Sprintf("%s %s", e.Arg, e.Msg)
+}
+
+func argErrorf(arg, format string, args ...interface{}) *ArgError {
+	return &ArgError{arg, fmt.Sprintf(format, args...)}
+}
+
+// UnknownAccountError is returned when fetching the nonce of an account the chain doesn't have
+type UnknownAccountError struct {
+	Address []byte
+}
+
+func (e *UnknownAccountError) Error() string {
+	return fmt.Sprintf("unknown account %X", e.Address)
+}
+
+// NodeError is an error talking to the node
+type NodeError struct {
+	Err error
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("Error talking to node: %v", e.Err)
+}
+
+// SignerError is an error from the signer
+type SignerError struct {
+	Err error
+}
+
+func (e *SignerError) Error() string {
+	return fmt.Sprintf("Error calling signing daemon: %v", e.Err)
+}
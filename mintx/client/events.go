@@ -0,0 +1,109 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	ctypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core/types"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------------
+// wait for events
+
+type Msg struct {
+	BlockHash []byte
+	Value     []byte
+	Exception string
+	Error     error
+}
+
+// Subscriber watches for a tx to be committed.
+// Subscribe is called before the tx is broadcast, and should stop watching when ctx is done
+type Subscriber interface {
+	Subscribe(ctx context.Context, chainID string, tx types.Tx, inputAddr []byte) (<-chan Msg, error)
+}
+
+// WSSubscriber watches a node's websocket for the events from a tx's input
+type WSSubscriber struct {
+	Addr string
+}
+
+func NewWSSubscriber(nodeAddr string) *WSSubscriber {
+	return &WSSubscriber{WebsocketAddr(nodeAddr)}
+}
+
+// the websocket endpoint of a node's rpc address, with or without a trailing /
+func WebsocketAddr(nodeAddr string) string {
+	wsAddr := strings.TrimPrefix(nodeAddr, "http://")
+	if !strings.HasSuffix(wsAddr, "/") {
+		wsAddr += "/"
+	}
+	return "ws://" + wsAddr + "websocket"
+}
+
+func (s *WSSubscriber) Subscribe(ctx context.Context, chainID string, tx types.Tx, inputAddr []byte) (<-chan Msg, error) {
+	logger.Debugln(s.Addr)
+	wsClient := cclient.NewWSClient(s.Addr)
+	if _, err := wsClient.Start(); err != nil {
+		return nil, &NodeError{fmt.Errorf("Error connecting to websocket: %v", err)}
+	}
+	eid := types.EventStringAccInput(inputAddr)
+	if err := wsClient.Subscribe(eid); err != nil {
+		wsClient.Stop()
+		return nil, &NodeError{fmt.Errorf("Error subscribing to AccInput event: %v", err)}
+	}
+	if err := wsClient.Subscribe(types.EventStringNewBlock()); err != nil {
+		wsClient.Stop()
+		return nil, &NodeError{fmt.Errorf("Error subscribing to NewBlock event: %v", err)}
+	}
+
+	resultChan := make(chan Msg, 1)
+	go func() {
+		defer wsClient.Stop()
+		var latestBlockHash []byte
+		for {
+			var result ctypes.ResultEvent
+			select {
+			case result = <-wsClient.EventsCh:
+			case <-ctx.Done():
+				return
+			}
+
+			// if its a block, remember the block hash.
+			// Block.Hash needs the chain id in the global config, so hash the header
+			if blockData, ok := result.Data.(types.EventDataNewBlock); ok {
+				latestBlockHash = blockData.Block.Header.Hash()
+				continue
+			}
+
+			// we don't accept events unless they came after a new block (ie. in)
+			if latestBlockHash == nil {
+				continue
+			}
+
+			if result.Event != eid {
+				logger.Debugf("received unsolicited event! Got %s, expected %s\n", result.Event, eid)
+				continue
+			}
+
+			data, ok := result.Data.(types.EventDataTx)
+			if !ok {
+				resultChan <- Msg{Error: fmt.Errorf("response error: expected result.Data to be *types.EventDataTx")}
+				return
+			}
+
+			if !bytes.Equal(types.TxID(chainID, data.Tx), types.TxID(chainID, tx)) {
+				logger.Debugf("Received event for same input from another transaction: %X\n", types.TxID(chainID, data.Tx))
+				continue
+			}
+
+			resultChan <- Msg{BlockHash: latestBlockHash, Value: data.Return, Exception: data.Exception}
+			return
+		}
+	}()
+	return resultChan, nil
+}
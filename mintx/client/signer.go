@@ -0,0 +1,100 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+)
+
+// Signer holds keys and signs with them
+type Signer interface {
+	Sign(ctx context.Context, addr, signBytes []byte) (account.SignatureEd25519, error)
+	PubKey(ctx context.Context, addr []byte) (account.PubKey, error)
+}
+
+// KeysDaemon signs with an eris-keys daemon
+type KeysDaemon struct {
+	Addr string
+	HTTP *http.Client
+}
+
+func NewKeysDaemon(addr string) *KeysDaemon {
+	return &KeysDaemon{Addr: addr, HTTP: new(http.Client)}
+}
+
+func (k *KeysDaemon) Sign(ctx context.Context, addr, signBytes []byte) (sig account.SignatureEd25519, err error) {
+	sigS, err := k.call(ctx, "sign", map[string]string{
+		"hash": fmt.Sprintf("%X", signBytes),
+		"addr": fmt.Sprintf("%X", addr),
+	})
+	if err != nil {
+		return
+	}
+	sigBytes, err := hex.DecodeString(sigS)
+	if err != nil || len(sigBytes) != len(sig) {
+		return sig, &SignerError{fmt.Errorf("bad signature %q", sigS)}
+	}
+	copy(sig[:], sigBytes)
+	return sig, nil
+}
+
+func (k *KeysDaemon) PubKey(ctx context.Context, addr []byte) (account.PubKey, error) {
+	pubS, err := k.call(ctx, "pub", map[string]string{"addr": fmt.Sprintf("%X", addr)})
+	if err != nil {
+		return nil, err
+	}
+	var pub account.PubKeyEd25519
+	pubBytes, err := hex.DecodeString(pubS)
+	if err != nil || len(pubBytes) != len(pub) {
+		return nil, &SignerError{fmt.Errorf("bad pubkey %q", pubS)}
+	}
+	copy(pub[:], pubBytes)
+	return pub, nil
+}
+
+type keysResponse struct {
+	Response string
+	Error    string
+}
+
+func (k *KeysDaemon) call(ctx context.Context, method string, args map[string]string) (string, error) {
+	b, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	logger.Debugln("Sending request body:", string(b))
+	req, err := http.NewRequest("POST", k.Addr+"/"+method, bytes.NewBuffer(b))
+	if err != nil {
+		return "", &SignerError{err}
+	}
+	req.Header.Add("Content-Type", "application/json")
+	resp, err := k.HTTP.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", &SignerError{err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "", &SignerError{fmt.Errorf("%s", resp.Status)}
+	}
+	b, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", &SignerError{err}
+	}
+	r := new(keysResponse)
+	if err := json.Unmarshal(b, r); err != nil {
+		return "", &SignerError{err}
+	}
+	if r.Error != "" {
+		return "", &SignerError{fmt.Errorf("%s", r.Error)}
+	}
+	return r.Response, nil
+}
@@ -2,17 +2,14 @@ package core
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"strconv"
-	"strings"
-	"time"
+
+	"github.com/eris-ltd/mint-client/mintx/client"
 
 	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
-	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
 	rtypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core/types"
 	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
 	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
@@ -56,16 +53,20 @@ func Output(addr, amtS string) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-func Input(nodeAddr, pubkey, amtS, nonceS, addr string) ([]byte, error) {
-	pub, addrBytes, amt, nonce, err := checkCommon(nodeAddr, pubkey, addr, amtS, nonceS)
+func Input(nodeAddr, signAddr, pubkey, amtS, nonceS, addr string) ([]byte, error) {
+	in, amt, err := parseInput(pubkey, addr, amtS, nonceS)
+	if err != nil {
+		return nil, err
+	}
+	pub, nonce, err := newClient(nodeAddr, signAddr).ResolveInput(context.Background(), in)
 	if err != nil {
 		return nil, err
 	}
 
 	txInput := types.TxInput{
-		Address:  addrBytes,
+		Address:  pub.Address(),
 		Amount:   amt,
-		Sequence: int(nonce),
+		Sequence: nonce,
 		PubKey:   pub,
 	}
 
@@ -78,8 +79,8 @@ func Input(nodeAddr, pubkey, amtS, nonceS, addr string) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-func Send(nodeAddr, pubkey, addr, toAddr, amtS, nonceS string) (*types.SendTx, error) {
-	pub, addrBytes, amt, nonce, err := checkCommon(nodeAddr, pubkey, addr, amtS, nonceS)
+func Send(nodeAddr, signAddr, pubkey, addr, toAddr, amtS, nonceS string) (*types.SendTx, error) {
+	in, amt, err := parseInput(pubkey, addr, amtS, nonceS)
 	if err != nil {
 		return nil, err
 	}
@@ -93,16 +94,15 @@ func Send(nodeAddr, pubkey, addr, toAddr, amtS, nonceS string) (*types.SendTx, e
 		return nil, fmt.Errorf("toAddr is bad hex: %v", err)
 	}
 
-	tx := types.NewSendTx()
-	_ = addrBytes // TODO!
-	tx.AddInputWithNonce(pub, amt, int(nonce))
-	tx.AddOutput(toAddrBytes, amt)
-
-	return tx, nil
+	return newClient(nodeAddr, signAddr).Send(context.Background(), client.SendOpts{
+		Input:  in,
+		To:     toAddrBytes,
+		Amount: amt,
+	})
 }
 
-func Call(nodeAddr, pubkey, addr, toAddr, amtS, nonceS, gasS, feeS, data string) (*types.CallTx, error) {
-	pub, _, amt, nonce, err := checkCommon(nodeAddr, pubkey, addr, amtS, nonceS)
+func Call(nodeAddr, signAddr, pubkey, addr, toAddr, amtS, nonceS, gasS, feeS, data string) (*types.CallTx, error) {
+	in, amt, err := parseInput(pubkey, addr, amtS, nonceS)
 	if err != nil {
 		return nil, err
 	}
@@ -127,12 +127,18 @@ func Call(nodeAddr, pubkey, addr, toAddr, amtS, nonceS, gasS, feeS, data string)
 		return nil, fmt.Errorf("data is bad hex: %v", err)
 	}
 
-	tx := types.NewCallTxWithNonce(pub, toAddrBytes, dataBytes, amt, gas, fee, int(nonce))
-	return tx, nil
+	return newClient(nodeAddr, signAddr).Call(context.Background(), client.CallOpts{
+		Input:    in,
+		To:       toAddrBytes,
+		Amount:   amt,
+		GasLimit: gas,
+		Fee:      fee,
+		Data:     dataBytes,
+	})
 }
 
-func Name(nodeAddr, pubkey, addr, amtS, nonceS, feeS, name, data string) (*types.NameTx, error) {
-	pub, _, amt, nonce, err := checkCommon(nodeAddr, pubkey, addr, amtS, nonceS)
+func Name(nodeAddr, signAddr, pubkey, addr, amtS, nonceS, feeS, name, data string) (*types.NameTx, error) {
+	in, amt, err := parseInput(pubkey, addr, amtS, nonceS)
 	if err != nil {
 		return nil, err
 	}
@@ -142,92 +148,40 @@ func Name(nodeAddr, pubkey, addr, amtS, nonceS, feeS, name, data string) (*types
 		return nil, fmt.Errorf("fee is misformatted: %v", err)
 	}
 
-	tx := types.NewNameTxWithNonce(pub, name, data, amt, fee, int(nonce))
-	return tx, nil
+	return newClient(nodeAddr, signAddr).Name(context.Background(), client.NameOpts{
+		Input:  in,
+		Amount: amt,
+		Fee:    fee,
+		Name:   name,
+		Data:   data,
+	})
 }
 
-func Permissions(nodeAddr, pubkey, addrS, nonceS, permFunc string, argsS []string) (*types.PermissionsTx, error) {
-	pub, _, _, nonce, err := checkCommon(nodeAddr, pubkey, addrS, "0", "0")
+func Permissions(nodeAddr, signAddr, pubkey, addrS, nonceS, permFunc string, argsS []string) (*types.PermissionsTx, error) {
+	args, err := ParsePermArgs(permFunc, argsS)
 	if err != nil {
 		return nil, err
 	}
-	var args ptypes.PermArgs
-	switch permFunc {
-	case "set_base":
-		addr, pF, err := decodeAddressPermFlag(argsS[0], argsS[1])
-		if err != nil {
-			return nil, err
-		}
-		if len(argsS) != 3 {
-			return nil, fmt.Errorf("set_base also takes a value (true or false)")
-		}
-		var value bool
-		if argsS[2] == "true" {
-			value = true
-		} else if argsS[2] == "false" {
-			value = false
-		} else {
-			return nil, fmt.Errorf("Unknown value %s", argsS[2])
-		}
-		args = &ptypes.SetBaseArgs{addr, pF, value}
-	case "unset_base":
-		addr, pF, err := decodeAddressPermFlag(argsS[0], argsS[1])
-		if err != nil {
-			return nil, err
-		}
-		args = &ptypes.UnsetBaseArgs{addr, pF}
-	case "set_global":
-		pF, err := ptypes.PermStringToFlag(argsS[0])
-		if err != nil {
-			return nil, err
-		}
-		var value bool
-		if argsS[1] == "true" {
-			value = true
-		} else if argsS[1] == "false" {
-			value = false
-		} else {
-			return nil, fmt.Errorf("Unknown value %s", argsS[1])
-		}
-		args = &ptypes.SetGlobalArgs{pF, value}
-	case "add_role":
-		addr, err := hex.DecodeString(argsS[0])
-		if err != nil {
-			return nil, err
-		}
-		args = &ptypes.AddRoleArgs{addr, argsS[1]}
-	case "rm_role":
-		addr, err := hex.DecodeString(argsS[0])
-		if err != nil {
-			return nil, err
-		}
-		args = &ptypes.RmRoleArgs{addr, argsS[1]}
-	default:
-		return nil, fmt.Errorf("Invalid permission function for use in PermissionsTx: %s", permFunc)
-	}
-	// args := snativeArgs(
-	tx := types.NewPermissionsTxWithNonce(pub, args, int(nonce))
-	return tx, nil
-}
-
-func decodeAddressPermFlag(addrS, permFlagS string) (addr []byte, pFlag ptypes.PermFlag, err error) {
-	if addr, err = hex.DecodeString(addrS); err != nil {
-		return
-	}
-	if pFlag, err = ptypes.PermStringToFlag(permFlagS); err != nil {
-		return
+	in, _, err := parseInput(pubkey, addrS, "0", nonceS)
+	if err != nil {
+		return nil, err
 	}
-	return
+	return newClient(nodeAddr, signAddr).Permissions(context.Background(), client.PermissionsOpts{
+		Input: in,
+		Args:  args,
+	})
 }
 
 type NameGetter struct {
 	client cclient.Client
 }
 
+// types.NameGetter can't return an error, so a failed lookup is treated as no entry
 func (n NameGetter) GetNameRegEntry(name string) *types.NameRegEntry {
 	entry, err := n.client.GetName(name)
 	if err != nil {
-		panic(err)
+		logger.Errorf("Error fetching name %s: %v\n", name, err)
+		return nil
 	}
 	return entry.Entry
 }
@@ -245,348 +199,120 @@ func coreNewAccount(nodeAddr, pubkey, chainID string) (*types.NewAccountTx, erro
 */
 
 func Bond(nodeAddr, pubkey, unbondAddr, amtS, nonceS string) (*types.BondTx, error) {
-	pub, addrBytes, amt, nonce, err := checkCommon(nodeAddr, pubkey, "", amtS, nonceS)
+	in, amt, err := parseInput(pubkey, "", amtS, nonceS)
 	if err != nil {
 		return nil, err
 	}
-	var pubKey account.PubKeyEd25519
-	var unbondAddrBytes []byte
 
-	if unbondAddr == "" {
-		pkb, _ := hex.DecodeString(pubkey)
-		copy(pubKey[:], pkb)
-		unbondAddrBytes = pubKey.Address()
-	} else {
+	var unbondAddrBytes []byte
+	if unbondAddr != "" {
 		unbondAddrBytes, err = hex.DecodeString(unbondAddr)
 		if err != nil {
 			return nil, fmt.Errorf("unbondAddr is bad hex: %v", err)
 		}
-
-	}
-
-	tx, err := types.NewBondTx(pub)
-	if err != nil {
-		return nil, err
 	}
-	_ = addrBytes
-	tx.AddInputWithNonce(pub, amt, int(nonce))
-	tx.AddOutput(unbondAddrBytes, amt)
 
-	return tx, nil
+	return newClient(nodeAddr, "").Bond(context.Background(), client.BondOpts{
+		Input:    in,
+		Amount:   amt,
+		UnbondTo: unbondAddrBytes,
+	})
 }
 
 func Unbond(addrS, heightS string) (*types.UnbondTx, error) {
-	if addrS == "" {
-		return nil, fmt.Errorf("Validator address must be given with --addr flag")
-	}
-
-	addrBytes, err := hex.DecodeString(addrS)
+	addrBytes, height, err := parseValidatorHeight(addrS, heightS)
 	if err != nil {
-		return nil, fmt.Errorf("addr is bad hex: %v", err)
+		return nil, err
 	}
+	return newClient("", "").Unbond(client.UnbondOpts{
+		Address: addrBytes,
+		Height:  height,
+	})
+}
 
-	height, err := strconv.ParseInt(heightS, 10, 32)
+func Rebond(addrS, heightS string) (*types.RebondTx, error) {
+	addrBytes, height, err := parseValidatorHeight(addrS, heightS)
 	if err != nil {
-		return nil, fmt.Errorf("height is misformatted: %v", err)
+		return nil, err
 	}
-
-	return &types.UnbondTx{
+	return newClient("", "").Rebond(client.RebondOpts{
 		Address: addrBytes,
-		Height:  int(height),
-	}, nil
+		Height:  height,
+	})
 }
 
-func Rebond(addrS, heightS string) (*types.RebondTx, error) {
+func parseValidatorHeight(addrS, heightS string) ([]byte, int, error) {
 	if addrS == "" {
-		return nil, fmt.Errorf("Validator address must be given with --addr flag")
+		return nil, 0, fmt.Errorf("Validator address must be given with --addr flag")
 	}
 
 	addrBytes, err := hex.DecodeString(addrS)
 	if err != nil {
-		return nil, fmt.Errorf("addr is bad hex: %v", err)
+		return nil, 0, fmt.Errorf("addr is bad hex: %v", err)
 	}
 
 	height, err := strconv.ParseInt(heightS, 10, 32)
 	if err != nil {
-		return nil, fmt.Errorf("height is misformatted: %v", err)
+		return nil, 0, fmt.Errorf("height is misformatted: %v", err)
 	}
-
-	return &types.RebondTx{
-		Address: addrBytes,
-		Height:  int(height),
-	}, nil
+	return addrBytes, int(height), nil
 }
 
 //------------------------------------------------------------------------------------
 // sign and broadcast
 
 func Sign(signBytes, signAddr, signRPC string) (sig [64]byte, err error) {
-	args := map[string]string{
-		"hash": signBytes,
-		"addr": signAddr,
-	}
-	b, err := json.Marshal(args)
+	signBytesB, err := hex.DecodeString(signBytes)
 	if err != nil {
-		return
+		return sig, fmt.Errorf("sign bytes are bad hex: %v", err)
 	}
-	logger.Debugln("Sending request body:", string(b))
-	req, err := http.NewRequest("POST", signRPC+"/sign", bytes.NewBuffer(b))
+	addrBytes, err := hex.DecodeString(signAddr)
 	if err != nil {
-		return
+		return sig, fmt.Errorf("addr is bad hex: %v", err)
 	}
-	req.Header.Add("Content-Type", "application/json")
-	sigS, errS, err := requestResponse(req)
+	return client.NewKeysDaemon(signRPC).Sign(context.Background(), addrBytes, signBytesB)
+}
+
+// fetch the public key for an address from the signing daemon
+func Pub(addr, signRPC string) (pub account.PubKeyEd25519, err error) {
+	addrBytes, err := hex.DecodeString(addr)
 	if err != nil {
-		return sig, fmt.Errorf("Error calling signing daemon: %s", err.Error())
+		return pub, fmt.Errorf("addr is bad hex: %v", err)
 	}
-	if errS != "" {
-		return sig, fmt.Errorf("Error (string) calling signing daemon: %s", errS)
-	}
-	sigBytes, err := hex.DecodeString(sigS)
+	p, err := client.NewKeysDaemon(signRPC).PubKey(context.Background(), addrBytes)
 	if err != nil {
 		return
 	}
-	copy(sig[:], sigBytes)
-	return
+	return p.(account.PubKeyEd25519), nil
 }
 
 func Broadcast(tx types.Tx, broadcastRPC string) (*rtypes.Receipt, error) {
-	client := cclient.NewClient(broadcastRPC, "JSONRPC")
-	rec, err := client.BroadcastTx(tx)
-	if err != nil {
-		return nil, err
-	}
-	return &rec.Receipt, nil
+	return newClient(broadcastRPC, "").Broadcast(context.Background(), tx)
 }
 
-//------------------------------------------------------------------------------------
-// utils for talking to the key server
+type TxResult client.TxResult
 
-type HTTPResponse struct {
-	Response string
-	Error    string
-}
-
-func requestResponse(req *http.Request) (string, string, error) {
-	client := new(http.Client)
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", "", err
-	}
-	if resp.StatusCode >= 400 {
-		return "", "", fmt.Errorf(resp.Status)
-	}
-	return unpackResponse(resp)
-}
-
-func unpackResponse(resp *http.Response) (string, string, error) {
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", "", err
-	}
-	r := new(HTTPResponse)
-	if err := json.Unmarshal(b, r); err != nil {
-		return "", "", err
-	}
-	return r.Response, r.Error, nil
+func SignAndBroadcast(chainID, nodeAddr, signAddr string, tx types.Tx, sign, broadcast, wait bool) (*TxResult, error) {
+	c := client.New(chainID, nodeAddr, client.NewKeysDaemon(signAddr))
+	result, err := c.SignAndBroadcast(context.Background(), tx, sign, broadcast, wait)
+	return (*TxResult)(result), err
 }
 
 //------------------------------------------------------------------------------------
-// sign and broadcast convenience
-
-// tx has either one input or we default to the first one (ie for send/bond)
-// TODO: better support for multisig and bonding
-func signTx(signAddr, chainID string, tx_ types.Tx) ([]byte, types.Tx, error) {
-	signBytes := fmt.Sprintf("%X", account.SignBytes(chainID, tx_))
-	var inputAddr []byte
-	var sigED account.SignatureEd25519
-	switch tx := tx_.(type) {
-	case *types.SendTx:
-		inputAddr = tx.Inputs[0].Address
-		defer func(s *account.SignatureEd25519) { tx.Inputs[0].Signature = *s }(&sigED)
-	case *types.NameTx:
-		inputAddr = tx.Input.Address
-		defer func(s *account.SignatureEd25519) { tx.Input.Signature = *s }(&sigED)
-	case *types.CallTx:
-		inputAddr = tx.Input.Address
-		defer func(s *account.SignatureEd25519) { tx.Input.Signature = *s }(&sigED)
-	case *types.PermissionsTx:
-		inputAddr = tx.Input.Address
-		defer func(s *account.SignatureEd25519) { tx.Input.Signature = *s }(&sigED)
-	case *types.BondTx:
-		inputAddr = tx.Inputs[0].Address
-		defer func(s *account.SignatureEd25519) {
-			tx.Signature = *s
-			tx.Inputs[0].Signature = *s
-		}(&sigED)
-	case *types.UnbondTx:
-		inputAddr = tx.Address
-		defer func(s *account.SignatureEd25519) { tx.Signature = *s }(&sigED)
-	case *types.RebondTx:
-		inputAddr = tx.Address
-		defer func(s *account.SignatureEd25519) { tx.Signature = *s }(&sigED)
-	}
-	addrHex := fmt.Sprintf("%X", inputAddr)
-	sig, err := Sign(signBytes, addrHex, signAddr)
-	if err != nil {
-		return nil, nil, err
-	}
-	sigED = account.SignatureEd25519(sig)
-	logger.Debugf("SIG: %X\n", sig)
-	return inputAddr, tx_, nil
-}
-
-type TxResult struct {
-	BlockHash []byte // all txs get in a block
-	Hash      []byte // all txs get a hash
-
-	// only CallTx
-	Address   []byte // only for new contracts
-	Return    []byte
-	Exception string
-
-	//TODO: make Broadcast() errors more responsive so we
-	// can differentiate mempool errors from other
-}
+// convenience function
 
-func SignAndBroadcast(chainID, nodeAddr, signAddr string, tx types.Tx, sign, broadcast, wait bool) (txResult *TxResult, err error) {
-	var inputAddr []byte
-	if sign {
-		inputAddr, tx, err = signTx(signAddr, chainID, tx)
-		if err != nil {
-			return nil, err
-		}
+// a client for building txs, that fetches nonces from the node at nodeAddr (if it's given)
+// and pubkeys for addresses from the keys daemon at signAddr (if it's given)
+func newClient(nodeAddr, signAddr string) *client.Client {
+	var signer client.Signer
+	if signAddr != "" {
+		signer = client.NewKeysDaemon(signAddr)
 	}
-
-	if broadcast {
-		if wait {
-			var ch chan Msg
-			ch, err = subscribeAndWait(tx, chainID, nodeAddr, inputAddr)
-			if err != nil {
-				return nil, err
-			} else {
-				defer func() {
-					if err != nil {
-						// if broadcast threw an error, just return
-						return
-					}
-					logger.Debugln("Waiting for tx to be committed ...")
-					msg := <-ch
-					if msg.Error != nil {
-						logger.Infof("Encountered error waiting for event: %v\n", msg.Error)
-						err = msg.Error
-					} else {
-						txResult.BlockHash = msg.BlockHash
-						txResult.Return = msg.Value
-						txResult.Exception = msg.Exception
-					}
-				}()
-			}
-		}
-		var receipt *rtypes.Receipt
-		receipt, err = Broadcast(tx, nodeAddr)
-		if err != nil {
-			return nil, err
-		}
-		txResult = &TxResult{
-			Hash: receipt.TxHash,
-		}
-		if tx_, ok := tx.(*types.CallTx); ok {
-			if len(tx_.Address) == 0 {
-				txResult.Address = types.NewContractAddress(tx_.Input.Address, tx_.Input.Sequence)
-			}
-		}
-	}
-	return
-}
-
-//------------------------------------------------------------------------------------
-// wait for events
-
-type Msg struct {
-	BlockHash []byte
-	Value     []byte
-	Exception string
-	Error     error
-}
-
-func subscribeAndWait(tx types.Tx, chainID, nodeAddr string, inputAddr []byte) (chan Msg, error) {
-	// subscribe to event and wait for tx to be committed
-	wsAddr := strings.TrimPrefix(nodeAddr, "http://")
-	wsAddr = "ws://" + wsAddr + "websocket"
-	logger.Debugln(wsAddr)
-	wsClient := cclient.NewWSClient(wsAddr)
-	wsClient.Start()
-	eid := types.EventStringAccInput(inputAddr)
-	if err := wsClient.Subscribe(eid); err != nil {
-		return nil, fmt.Errorf("Error subscribing to AccInput event: %v", err)
-	}
-	if err := wsClient.Subscribe(types.EventStringNewBlock()); err != nil {
-		return nil, fmt.Errorf("Error subscribing to NewBlock event: %v", err)
-	}
-
-	resultChan := make(chan Msg, 1)
-
-	var latestBlockHash []byte
-
-	// Read message
-	go func() {
-		for {
-			result := <-wsClient.EventsCh
-			// if its a block, remember the block hash
-			blockData, ok := result.Data.(types.EventDataNewBlock)
-			if ok {
-				latestBlockHash = blockData.Block.Hash()
-				continue
-			}
-
-			// we don't accept events unless they came after a new block (ie. in)
-			if latestBlockHash == nil {
-				continue
-			}
-
-			if result.Event != eid {
-				logger.Debugf("received unsolicited event! Got %s, expected %s\n", result.Event, eid)
-				continue
-			}
-
-			data, ok := result.Data.(types.EventDataTx)
-			if !ok {
-				resultChan <- Msg{Error: fmt.Errorf("response error: expected result.Data to be *types.EventDataTx")}
-				return
-			}
-
-			if !bytes.Equal(types.TxID(chainID, data.Tx), types.TxID(chainID, tx)) {
-				logger.Debugf("Received event for same input from another transaction: %X\n", types.TxID(chainID, data.Tx))
-				continue
-			}
-
-			if data.Exception != "" {
-				resultChan <- Msg{BlockHash: latestBlockHash, Value: data.Return, Exception: data.Exception}
-				return
-			}
-
-			// GOOD!
-			resultChan <- Msg{BlockHash: latestBlockHash, Value: data.Return}
-			return
-		}
-	}()
-
-	// txs should take no more than 10 seconds
-	timeoutTicker := time.Tick(10 * time.Second)
-
-	go func() {
-		<-timeoutTicker
-		resultChan <- Msg{Error: fmt.Errorf("timed out waiting for event")}
-		return
-	}()
-	return resultChan, nil
+	return client.New("", nodeAddr, signer)
 }
 
-//------------------------------------------------------------------------------------
-// convenience function
-
-func checkCommon(nodeAddr, pubkey, addr, amtS, nonceS string) (pub account.PubKey, addrBytes []byte, amt int64, nonce int64, err error) {
+// the flags common to txs with an input. An empty nonce is fetched from the node
+func parseInput(pubkey, addr, amtS, nonceS string) (in client.Input, amt int64, err error) {
 	if amtS == "" {
 		err = fmt.Errorf("input must specify an amount with the --amt flag")
 		return
@@ -603,7 +329,7 @@ func checkCommon(nodeAddr, pubkey, addr, amtS, nonceS string) (pub account.PubKe
 		return
 	}
 
-	addrBytes, err = hex.DecodeString(addr)
+	in.Address, err = hex.DecodeString(addr)
 	if err != nil {
 		err = fmt.Errorf("addr is bad hex: %v", err)
 		return
@@ -612,40 +338,24 @@ func checkCommon(nodeAddr, pubkey, addr, amtS, nonceS string) (pub account.PubKe
 	amt, err = strconv.ParseInt(amtS, 10, 64)
 	if err != nil {
 		err = fmt.Errorf("amt is misformatted: %v", err)
+		return
 	}
 
 	if len(pubKeyBytes) > 0 {
-		var pubArray [32]byte
+		var pubArray account.PubKeyEd25519
 		copy(pubArray[:], pubKeyBytes)
-		pub = account.PubKeyEd25519(pubArray)
-		addrBytes = pub.Address()
+		in.PubKey = pubArray
+		in.Address = pubArray.Address()
 	}
 
-	if nonceS == "" {
-		if nodeAddr == "" {
-			err = fmt.Errorf("input must specify a nonce with the --nonce flag or use --node-addr (or MINTX_NODE_ADDR) to fetch the nonce from a node")
-			return
-		}
-
-		// fetch nonce from node
-		client := cclient.NewClient(nodeAddr, "HTTP")
-		ac, err2 := client.GetAccount(addrBytes)
-		if err2 != nil {
-			err = fmt.Errorf("Error connecting to node (%s) to fetch nonce: %s", nodeAddr, err2.Error())
-			return
-		}
-		if ac == nil || ac.Account == nil {
-			err = fmt.Errorf("unknown account %X", addrBytes)
-			return
-		}
-		nonce = int64(ac.Account.Sequence) + 1
-	} else {
+	if nonceS != "" {
+		var nonce int64
 		nonce, err = strconv.ParseInt(nonceS, 10, 64)
 		if err != nil {
 			err = fmt.Errorf("nonce is misformatted: %v", err)
 			return
 		}
+		in.Nonce = int(nonce)
 	}
-
 	return
 }
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/eris-ltd/mint-client/fakenode"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+)
+
+// the --wait path of mintx name and mintx send, against an in-process node
+func TestSignAndBroadcastWait(t *testing.T) {
+	node := fakenode.New(fakenode.Config{Accounts: 2})
+	defer node.Close()
+	from, to := node.Accounts[0], node.Accounts[1]
+	pub := from.PubKey.(account.PubKeyEd25519)
+	pubS := fmt.Sprintf("%X", pub[:])
+
+	nameTx, err := Name(node.URL, "", pubS, "", "1000", "", "0", "wait", "for it")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := SignAndBroadcast(node.ChainID, node.URL, node.KeysURL, nameTx, true, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.BlockHash == nil {
+		t.Fatal("expected a block hash")
+	}
+	rpc := cclient.NewClient(node.URL, "JSONRPC")
+	if entry := (NameGetter{rpc}).GetNameRegEntry("wait"); entry == nil || entry.Data != "for it" {
+		t.Fatalf("bad entry %v", entry)
+	}
+
+	// the nonce is fetched from the node after the name tx is committed.
+	// Without a signer, core can't look up the pubkey for an address
+	_, err = Send(node.URL, "", "", fmt.Sprintf("%X", from.Address), fmt.Sprintf("%X", to.Address), "5", "")
+	if err == nil {
+		t.Fatal("expected error without a pubkey")
+	}
+	sendTx, err := Send(node.URL, node.KeysURL, "", fmt.Sprintf("%X", from.Address), fmt.Sprintf("%X", to.Address), "5", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sendTx.Inputs[0].Sequence != 2 {
+		t.Fatalf("expected nonce 2, got %d", sendTx.Inputs[0].Sequence)
+	}
+	if _, err := SignAndBroadcast(node.ChainID, node.URL, node.KeysURL, sendTx, true, true, true); err != nil {
+		t.Fatal(err)
+	}
+	acc, err := rpc.GetAccount(to.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acc.Account.Balance != fakenode.DefaultBalance+5 || !bytes.Equal(acc.Account.Address, to.Address) {
+		t.Fatalf("bad account %v", acc.Account)
+	}
+}
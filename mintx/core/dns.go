@@ -0,0 +1,206 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------------
+// dns records in the name registry
+
+var DNSRecordTypes = []string{"A", "AAAA", "CNAME", "NS", "TXT"}
+
+// the json stored under the fqdn in the name registry.
+// "address" holds the value for every record type
+type DNSRecord struct {
+	FQDN    string `json:"fqdn"`
+	Address string `json:"address"`
+	Type    string `json:"type"`
+}
+
+// build and validate a record. The fqdn is returned without a trailing dot
+func NewDNSRecord(fqdn, typ, value string) (*DNSRecord, error) {
+	fqdn = strings.TrimSuffix(fqdn, ".")
+	if err := validateHostname(fqdn); err != nil {
+		return nil, fmt.Errorf("fqdn %q is invalid: %v", fqdn, err)
+	}
+	r := &DNSRecord{
+		FQDN:    fqdn,
+		Address: value,
+		Type:    strings.ToUpper(typ),
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *DNSRecord) Validate() error {
+	if r.Address == "" {
+		return fmt.Errorf("%s record for %s must have a value", r.Type, r.FQDN)
+	}
+	switch r.Type {
+	case "A":
+		if ip := net.ParseIP(r.Address); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("A record value must be an IPv4 address, got %q", r.Address)
+		}
+	case "AAAA":
+		if ip := net.ParseIP(r.Address); ip == nil || ip.To4() != nil {
+			return fmt.Errorf("AAAA record value must be an IPv6 address, got %q", r.Address)
+		}
+	case "CNAME":
+		if err := validateHostname(strings.TrimSuffix(r.Address, ".")); err != nil {
+			return fmt.Errorf("CNAME record value %q is invalid: %v", r.Address, err)
+		}
+	case "NS":
+		// the name server's address or hostname
+		if net.ParseIP(r.Address) == nil {
+			if err := validateHostname(strings.TrimSuffix(r.Address, ".")); err != nil {
+				return fmt.Errorf("NS record value must be an IP address or hostname, got %q: %v", r.Address, err)
+			}
+		}
+	case "TXT":
+		if len(r.Address) > 255 {
+			return fmt.Errorf("TXT record value is too long. Max 255 bytes")
+		}
+	default:
+		return fmt.Errorf("Unknown record type %q. Must be one of %s", r.Type, strings.Join(DNSRecordTypes, ", "))
+	}
+	return nil
+}
+
+func validateHostname(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty name")
+	}
+	if len(name) > 253 {
+		return fmt.Errorf("name is too long")
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 {
+			return fmt.Errorf("bad label %q", label)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("label %q must not start or end with a hyphen", label)
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return fmt.Errorf("label %q has invalid character %q", label, c)
+			}
+		}
+	}
+	return nil
+}
+
+// amount needed to hold a name reg entry for the given number of blocks
+func NameCost(name, data string, blocks int) int64 {
+	return int64(blocks) * types.NameCostPerBlock * types.NameCostPerByte * types.BaseEntryCost(name, data)
+}
+
+func parseLease(ttlBlocksS string) (int, error) {
+	if ttlBlocksS == "" {
+		return 0, fmt.Errorf("must specify the length of the lease in blocks with --ttl-blocks")
+	}
+	blocks, err := strconv.Atoi(ttlBlocksS)
+	if err != nil {
+		return 0, fmt.Errorf("ttl-blocks is misformatted: %v", err)
+	}
+	if blocks < types.MinNameRegistrationPeriod {
+		return 0, fmt.Errorf("names must be registered for at least %d blocks", types.MinNameRegistrationPeriod)
+	}
+	return blocks, nil
+}
+
+func parseFee(feeS string) (int64, error) {
+	if feeS == "" {
+		return 0, nil
+	}
+	fee, err := strconv.ParseInt(feeS, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("fee is misformatted: %v", err)
+	}
+	return fee, nil
+}
+
+// the NameTx will also fail if the name or data has characters the registry doesn't allow
+func dnsNameTx(nodeAddr, signAddr, pubkey, addr, nonceS string, fee, amt int64, name, data string) (*types.NameTx, error) {
+	tx, err := Name(nodeAddr, signAddr, pubkey, addr, strconv.FormatInt(amt+fee, 10), nonceS, strconv.FormatInt(fee, 10), name, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.ValidateStrings(); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// register or update a record, paying for ttlBlocks blocks
+func DNSSet(nodeAddr, signAddr, pubkey, addr, nonceS, feeS, fqdn, typ, value, ttlBlocksS string) (*types.NameTx, error) {
+	r, err := NewDNSRecord(fqdn, typ, value)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := parseLease(ttlBlocksS)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := parseFee(feeS)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	data := string(b)
+	return dnsNameTx(nodeAddr, signAddr, pubkey, addr, nonceS, fee, NameCost(r.FQDN, data, blocks), r.FQDN, data)
+}
+
+// no value and no data removes an entry
+func DNSRm(nodeAddr, signAddr, pubkey, addr, nonceS, feeS, fqdn string) (*types.NameTx, error) {
+	fqdn = strings.TrimSuffix(fqdn, ".")
+	if fqdn == "" {
+		return nil, fmt.Errorf("must specify the record to remove with --fqdn")
+	}
+	fee, err := parseFee(feeS)
+	if err != nil {
+		return nil, err
+	}
+	return dnsNameTx(nodeAddr, signAddr, pubkey, addr, nonceS, fee, 0, fqdn, "")
+}
+
+// extend an entry by ttlBlocks blocks, keeping its data.
+// If it has already expired, it's registered again for ttlBlocks from now
+func DNSRenew(nodeAddr, signAddr, pubkey, addr, nonceS, feeS, fqdn, ttlBlocksS string) (*types.NameTx, error) {
+	fqdn = strings.TrimSuffix(fqdn, ".")
+	if fqdn == "" {
+		return nil, fmt.Errorf("must specify the record to renew with --fqdn")
+	}
+	if nodeAddr == "" {
+		return nil, fmt.Errorf("must specify a node with --node-addr (or MINTX_NODE_ADDR) to fetch the record from")
+	}
+	blocks, err := parseLease(ttlBlocksS)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := parseFee(feeS)
+	if err != nil {
+		return nil, err
+	}
+
+	client := cclient.NewClient(nodeAddr, "HTTP")
+	r, err := client.GetName(fqdn)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching %s from node (%s): %v", fqdn, nodeAddr, err)
+	}
+	if r == nil || r.Entry == nil {
+		return nil, fmt.Errorf("%s is not registered", fqdn)
+	}
+	return RenewEntry(nodeAddr, signAddr, pubkey, addr, nonceS, fee, blocks, r.Entry)
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+var testPubKey = "F6C79CF0CB9D66B677988BCB9B8EADD9A091CD465A60542A8AB85476256DBA92"
+
+func TestDNSRecord(t *testing.T) {
+	good := [][3]string{
+		{"magma.interblock.io", "A", "1.2.3.4"},
+		{"magma.interblock.io.", "aaaa", "2001:db8::1"},
+		{"www.interblock.io", "CNAME", "magma.interblock.io."},
+		{"newdomain.io", "NS", "4.3.2.1"},
+		{"newdomain.io", "NS", "ns1.newdomain.io"},
+		{"newdomain.io", "TXT", "v=spf1 -all"},
+	}
+	for _, r := range good {
+		if _, err := NewDNSRecord(r[0], r[1], r[2]); err != nil {
+			t.Fatalf("expected %v to be valid: %v", r, err)
+		}
+	}
+
+	bad := [][3]string{
+		{"magma.interblock.io", "A", "1.2.3"},
+		{"magma.interblock.io", "A", "2001:db8::1"},
+		{"magma.interblock.io", "AAAA", "1.2.3.4"},
+		{"www.interblock.io", "CNAME", "not a host"},
+		{"newdomain.io", "MX", "mail.newdomain.io"},
+		{"-bad.io", "A", "1.2.3.4"},
+		{"bad..io", "A", "1.2.3.4"},
+		{"newdomain.io", "TXT", ""},
+	}
+	for _, r := range bad {
+		if _, err := NewDNSRecord(r[0], r[1], r[2]); err == nil {
+			t.Fatalf("expected %v to be invalid", r)
+		}
+	}
+}
+
+func TestDNSSet(t *testing.T) {
+	tx, err := DNSSet("", "", testPubKey, "", "1", "10", "magma.interblock.io", "A", "1.2.3.4", "100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := `{"fqdn":"magma.interblock.io","address":"1.2.3.4","type":"A"}`
+	if tx.Name != "magma.interblock.io" || tx.Data != data {
+		t.Fatalf("bad name tx %s %s", tx.Name, tx.Data)
+	}
+	// the fee comes on top of the cost of the lease
+	if cost := 100 * types.BaseEntryCost(tx.Name, tx.Data); tx.Input.Amount != cost+10 || tx.Fee != 10 {
+		t.Fatalf("bad amount %d, fee %d, expected %d + 10", tx.Input.Amount, tx.Fee, cost)
+	}
+
+	// too short a lease
+	if _, err := DNSSet("", "", testPubKey, "", "1", "0", "magma.interblock.io", "A", "1.2.3.4", "1"); err == nil {
+		t.Fatal("expected error for a lease shorter than the minimum")
+	}
+	// the name registry doesn't allow hyphens in names
+	if _, err := DNSSet("", "", testPubKey, "", "1", "0", "my-domain.io", "A", "1.2.3.4", "100"); err == nil {
+		t.Fatal("expected error for a name the registry won't accept")
+	}
+}
+
+func TestDNSRm(t *testing.T) {
+	tx, err := DNSRm("", "", testPubKey, "", "1", "5", "magma.interblock.io.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Name != "magma.interblock.io" || tx.Data != "" || tx.Input.Amount != tx.Fee {
+		t.Fatalf("bad rm tx %v", tx)
+	}
+}
@@ -0,0 +1,225 @@
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------------
+// faucet for development chains
+
+type FaucetConfig struct {
+	ChainID  string
+	NodeAddr string
+	SignAddr string
+
+	Pub    account.PubKey // the faucet's key
+	Amount int64          // sent per request
+
+	AddrInterval time.Duration // how often an address may be funded
+	IPInterval   time.Duration // how often an ip may request funds
+	DailyBudget  int64         // total to send per (utc) day. No limit if 0
+
+	// check the faucet can create the accounts it's asked to fund
+	CheckPerms bool
+}
+
+// Faucet is an http handler that sends SendTxs to the addresses it's asked for.
+// Requests are handled one at a time, so we can track our own nonce
+// while our txs are still in the mempool
+type Faucet struct {
+	config FaucetConfig
+	client cclient.Client
+	addr   []byte
+
+	mtx    sync.Mutex
+	nonce  int // the last nonce we used
+	byAddr map[string]time.Time
+	byIP   map[string]time.Time
+	day    string
+	spent  int64
+
+	// overridden in tests
+	now  func() time.Time
+	send func(tx *types.SendTx) ([]byte, error)
+}
+
+func NewFaucet(config FaucetConfig) (*Faucet, error) {
+	if config.Pub == nil {
+		return nil, fmt.Errorf("faucet must have a key to send from")
+	}
+	if config.Amount <= 0 {
+		return nil, fmt.Errorf("faucet amount must be positive")
+	}
+	if config.DailyBudget != 0 && config.DailyBudget < config.Amount {
+		return nil, fmt.Errorf("daily budget must be at least the amount")
+	}
+	f := &Faucet{
+		config: config,
+		client: cclient.NewClient(config.NodeAddr, "JSONRPC"),
+		addr:   config.Pub.Address(),
+		byAddr: make(map[string]time.Time),
+		byIP:   make(map[string]time.Time),
+		now:    time.Now,
+	}
+	f.send = func(tx *types.SendTx) ([]byte, error) {
+		result, err := SignAndBroadcast(config.ChainID, config.NodeAddr, config.SignAddr, tx, true, true, false)
+		if err != nil {
+			return nil, err
+		}
+		return result.Hash, nil
+	}
+	return f, nil
+}
+
+func (f *Faucet) Address() []byte {
+	return f.addr
+}
+
+type faucetResponse struct {
+	Address string `json:"address,omitempty"`
+	Amount  int64  `json:"amount,omitempty"`
+	TxHash  string `json:"tx_hash,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// an error and the status to send it with
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func httpErrorf(status int, format string, args ...interface{}) *httpError {
+	return &httpError{status, fmt.Errorf(format, args...)}
+}
+
+// request funds with GET or POST /?address=<hex address>
+func (f *Faucet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resp := faucetResponse{Address: r.FormValue("address")}
+	status := http.StatusOK
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	hash, err := f.Request(resp.Address, ip)
+	if err != nil {
+		status = http.StatusInternalServerError
+		if herr, ok := err.(*httpError); ok {
+			status = herr.status
+		}
+		resp.Error = err.Error()
+		logger.Infof("Faucet request for %s from %s refused: %v\n", resp.Address, ip, err)
+	} else {
+		resp.Amount = f.config.Amount
+		resp.TxHash = fmt.Sprintf("%X", hash)
+		logger.Infof("Faucet sent %d to %s for %s: %X\n", resp.Amount, resp.Address, ip, hash)
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// send funds to an address, if it and the ip haven't had any lately and there's budget left.
+// Returns the tx hash
+func (f *Faucet) Request(addrS, ip string) ([]byte, error) {
+	to, err := hex.DecodeString(addrS)
+	if err != nil || len(to) != 20 {
+		return nil, httpErrorf(http.StatusBadRequest, "address must be 20 bytes of hex")
+	}
+	addrS = fmt.Sprintf("%X", to)
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	now := f.now()
+	if last, ok := f.byAddr[addrS]; ok && now.Sub(last) < f.config.AddrInterval {
+		return nil, httpErrorf(http.StatusTooManyRequests, "%s was funded recently. Try again in %v", addrS, last.Add(f.config.AddrInterval).Sub(now))
+	}
+	if last, ok := f.byIP[ip]; ok && now.Sub(last) < f.config.IPInterval {
+		return nil, httpErrorf(http.StatusTooManyRequests, "too many requests. Try again in %v", last.Add(f.config.IPInterval).Sub(now))
+	}
+	if day := now.UTC().Format("2006-01-02"); day != f.day {
+		f.day, f.spent = day, 0
+	}
+	if f.config.DailyBudget > 0 && f.spent+f.config.Amount > f.config.DailyBudget {
+		return nil, httpErrorf(http.StatusServiceUnavailable, "the faucet has run out for today")
+	}
+
+	acc, err := f.client.GetAccount(f.addr)
+	if err != nil {
+		return nil, httpErrorf(http.StatusBadGateway, "error fetching faucet account: %v", err)
+	}
+	if acc == nil || acc.Account == nil {
+		return nil, httpErrorf(http.StatusServiceUnavailable, "the faucet account %X does not exist", f.addr)
+	}
+	if acc.Account.Balance < f.config.Amount {
+		return nil, httpErrorf(http.StatusServiceUnavailable, "the faucet is empty")
+	}
+	if f.config.CheckPerms {
+		if err := f.checkCreateAccount(acc.Account, to); err != nil {
+			return nil, err
+		}
+	}
+
+	// our last tx may not have been committed yet,
+	// and someone else may have used the account
+	if acc.Account.Sequence > f.nonce {
+		f.nonce = acc.Account.Sequence
+	}
+	tx := types.NewSendTx()
+	tx.AddInputWithNonce(f.config.Pub, f.config.Amount, f.nonce+1)
+	tx.AddOutput(to, f.config.Amount)
+	hash, err := f.send(tx)
+	if err != nil {
+		// the nonce may be why. Start again from the chain's
+		f.nonce = 0
+		return nil, httpErrorf(http.StatusBadGateway, "error sending tx: %v", err)
+	}
+	f.nonce++
+	f.byAddr[addrS] = now
+	f.byIP[ip] = now
+	f.spent += f.config.Amount
+	return hash, nil
+}
+
+// sending to an address that doesn't exist creates it,
+// which needs the CreateAccount permission, set on the faucet account or globally
+func (f *Faucet) checkCreateAccount(faucet *account.Account, to []byte) error {
+	r, err := f.client.GetAccount(to)
+	if err != nil {
+		return httpErrorf(http.StatusBadGateway, "error fetching account: %v", err)
+	}
+	if r != nil && r.Account != nil {
+		return nil
+	}
+	can, err := faucet.Permissions.Base.Get(ptypes.CreateAccount)
+	if _, ok := err.(ptypes.ErrValueNotSet); ok {
+		g, err := f.client.GetAccount(ptypes.GlobalPermissionsAddress)
+		if err != nil {
+			return httpErrorf(http.StatusBadGateway, "error fetching global permissions: %v", err)
+		}
+		can = false
+		if g != nil && g.Account != nil {
+			can, _ = g.Account.Permissions.Base.Get(ptypes.CreateAccount)
+		}
+	}
+	if !can {
+		return httpErrorf(http.StatusForbidden, "the faucet can't create accounts. %X must already exist", to)
+	}
+	return nil
+}
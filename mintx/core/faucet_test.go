@@ -0,0 +1,156 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	ctypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core/types"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+type fakeAccounts struct {
+	cclient.Client
+	accounts map[string]*account.Account
+}
+
+func (f *fakeAccounts) GetAccount(addr []byte) (*ctypes.ResultGetAccount, error) {
+	return &ctypes.ResultGetAccount{Account: f.accounts[string(addr)]}, nil
+}
+
+func testFaucet(t *testing.T, config FaucetConfig) (*Faucet, *fakeAccounts, *[]int) {
+	pubBytes, _ := hex.DecodeString(testPubKey)
+	var pub account.PubKeyEd25519
+	copy(pub[:], pubBytes)
+	config.Pub = pub
+	f, err := NewFaucet(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	perms := ptypes.ZeroAccountPermissions
+	accounts := &fakeAccounts{accounts: map[string]*account.Account{
+		string(pub.Address()): {Address: pub.Address(), Balance: 1000, Sequence: 4, Permissions: perms},
+	}}
+	f.client = accounts
+
+	var nonces []int
+	var mtx sync.Mutex
+	f.send = func(tx *types.SendTx) ([]byte, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		nonces = append(nonces, tx.Inputs[0].Sequence)
+		return []byte{0xAB}, nil
+	}
+	return f, accounts, &nonces
+}
+
+func TestFaucetLimits(t *testing.T) {
+	f, _, nonces := testFaucet(t, FaucetConfig{
+		Amount:       10,
+		AddrInterval: time.Hour,
+		IPInterval:   time.Minute,
+		DailyBudget:  30,
+	})
+	now := time.Date(2015, 8, 1, 12, 0, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+
+	addr := func(b byte) string { return hex.EncodeToString(bytes.Repeat([]byte{b}, 20)) }
+	expectErr := func(addrS, ip, expected string) {
+		if _, err := f.Request(addrS, ip); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected error containing %q, got %v", expected, err)
+		}
+	}
+
+	if _, err := f.Request(addr(1), "1.1.1.1"); err != nil {
+		t.Fatal(err)
+	}
+	expectErr("abcd", "2.2.2.2", "20 bytes")
+	expectErr(strings.ToUpper(addr(1)), "2.2.2.2", "funded recently")
+	expectErr(addr(2), "1.1.1.1", "too many requests")
+
+	now = now.Add(2 * time.Minute)
+	if _, err := f.Request(addr(2), "1.1.1.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Request(addr(3), "3.3.3.3"); err != nil {
+		t.Fatal(err)
+	}
+	expectErr(addr(4), "4.4.4.4", "run out for today")
+
+	// a new day
+	now = now.Add(24 * time.Hour)
+	if _, err := f.Request(addr(1), "1.1.1.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// nonces count up from the account's while our txs aren't committed
+	if len(*nonces) != 4 || (*nonces)[0] != 5 || (*nonces)[3] != 8 {
+		t.Fatalf("bad nonces %v", *nonces)
+	}
+}
+
+func TestFaucetConcurrent(t *testing.T) {
+	f, _, nonces := testFaucet(t, FaucetConfig{Amount: 10})
+	server := httptest.NewServer(f)
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i byte) {
+			defer wg.Done()
+			resp, err := http.Get(server.URL + "/?address=" + hex.EncodeToString(bytes.Repeat([]byte{i}, 20)))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+			var r faucetResponse
+			json.NewDecoder(resp.Body).Decode(&r)
+			if resp.StatusCode != http.StatusOK || r.TxHash != "AB" || r.Amount != 10 {
+				t.Errorf("bad response %d %v", resp.StatusCode, r)
+			}
+		}(byte(i))
+	}
+	wg.Wait()
+
+	seen := make(map[int]bool)
+	for _, n := range *nonces {
+		if seen[n] || n < 5 || n > 24 {
+			t.Fatalf("bad nonces %v", *nonces)
+		}
+		seen[n] = true
+	}
+}
+
+func TestFaucetPerms(t *testing.T) {
+	f, accounts, _ := testFaucet(t, FaucetConfig{Amount: 10, CheckPerms: true})
+	existing := bytes.Repeat([]byte{1}, 20)
+	accounts.accounts[string(existing)] = &account.Account{Address: existing}
+	fresh := hex.EncodeToString(bytes.Repeat([]byte{2}, 20))
+
+	// no global permissions account, so nothing is allowed
+	if _, err := f.Request(fresh, "1.1.1.1"); err == nil || !strings.Contains(err.Error(), "can't create accounts") {
+		t.Fatalf("expected permission error, got %v", err)
+	}
+	if _, err := f.Request(hex.EncodeToString(existing), "1.1.1.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	global := ptypes.ZeroAccountPermissions
+	global.Base.Set(ptypes.CreateAccount, true)
+	accounts.accounts[string(ptypes.GlobalPermissionsAddress)] = &account.Account{Permissions: global}
+	if _, err := f.Request(fresh, "1.1.1.1"); err != nil {
+		t.Fatal(err)
+	}
+}
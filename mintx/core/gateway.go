@@ -0,0 +1,323 @@
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/wire"
+)
+
+//------------------------------------------------------------------------------------
+// http gateway for building, signing and broadcasting txs
+
+// the tx types the gateway knows, by the name used in its urls and token files
+var GatewayTxTypes = []string{"send", "call", "name", "permissions", "bond", "unbond", "rebond"}
+
+// GatewayToken is an api key and what it may be used for.
+// "*" in Txs or From allows any tx type or any from-address
+type GatewayToken struct {
+	Token string   `json:"token"`
+	Txs   []string `json:"txs"`
+	From  []string `json:"from"`
+}
+
+func (t *GatewayToken) allows(txType string, from []byte) bool {
+	return allowed(t.Txs, txType) && allowed(t.From, fmt.Sprintf("%X", from))
+}
+
+func allowed(list []string, s string) bool {
+	for _, l := range list {
+		if l == "*" || strings.EqualFold(l, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// read the tokens from a json file, eg.
+//
+//	[{"token": "secret", "txs": ["send", "name"], "from": ["<hex address>"]}]
+func LoadGatewayTokens(file string) ([]*GatewayToken, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var tokens []*GatewayToken
+	if err := json.Unmarshal(b, &tokens); err != nil {
+		return nil, fmt.Errorf("Error reading tokens from %s: %v", file, err)
+	}
+	seen := make(map[string]bool)
+	for i, t := range tokens {
+		if t.Token == "" {
+			return nil, fmt.Errorf("token %d in %s is empty", i, file)
+		}
+		if seen[t.Token] {
+			return nil, fmt.Errorf("token %d in %s is a duplicate", i, file)
+		}
+		seen[t.Token] = true
+		for _, txType := range t.Txs {
+			if txType != "*" && !allowed(GatewayTxTypes, txType) {
+				return nil, fmt.Errorf("token %d in %s allows unknown tx type %s. Expected one of %s", i, file, txType, strings.Join(GatewayTxTypes, ", "))
+			}
+		}
+		for _, from := range t.From {
+			if from == "*" {
+				continue
+			}
+			if b, err := hex.DecodeString(from); err != nil || len(b) != 20 {
+				return nil, fmt.Errorf("token %d in %s allows bad address %s. Addresses must be 20 bytes of hex", i, file, from)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+type GatewayConfig struct {
+	ChainID  string
+	NodeAddr string
+	SignAddr string
+
+	Tokens []*GatewayToken
+}
+
+// Gateway is an http handler exposing the tx builders and SignAndBroadcast as json endpoints:
+//
+//	POST /tx/<type>  build a tx from the same fields as the mintx flags, and optionally sign, broadcast and wait on it
+//	POST /sign       sign a tx built elsewhere
+//	POST /broadcast  broadcast a signed tx, and optionally wait on it
+//
+// Every request must carry an "Authorization: Bearer <token>" header,
+// and the token must allow the tx's type and input address
+type Gateway struct {
+	config GatewayConfig
+	tokens map[string]*GatewayToken
+
+	// overridden in tests
+	signAndBroadcast func(tx types.Tx, sign, broadcast, wait bool) (*TxResult, error)
+	pub              func(addr string) (string, error)
+}
+
+func NewGateway(config GatewayConfig) (*Gateway, error) {
+	if len(config.Tokens) == 0 {
+		return nil, fmt.Errorf("gateway must have at least one token")
+	}
+	g := &Gateway{
+		config: config,
+		tokens: make(map[string]*GatewayToken),
+	}
+	for _, t := range config.Tokens {
+		g.tokens[t.Token] = t
+	}
+	g.signAndBroadcast = func(tx types.Tx, sign, broadcast, wait bool) (*TxResult, error) {
+		return SignAndBroadcast(config.ChainID, config.NodeAddr, config.SignAddr, tx, sign, broadcast, wait)
+	}
+	g.pub = func(addr string) (string, error) {
+		pub, err := Pub(addr, config.SignAddr)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%X", pub[:]), nil
+	}
+	return g, nil
+}
+
+// the body of POST /tx/<type>. Fields are strings, as for the mintx flags
+type GatewayTxRequest struct {
+	Pubkey string `json:"pubkey"`
+	Addr   string `json:"addr"`
+	To     string `json:"to"`
+	Amt    string `json:"amt"`
+	Nonce  string `json:"nonce"`
+	Fee    string `json:"fee"`
+	Gas    string `json:"gas"`
+	Data   string `json:"data"`
+
+	Name     string   `json:"name"`
+	PermFunc string   `json:"perm_func"`
+	PermArgs []string `json:"perm_args"`
+	UnbondTo string   `json:"unbond_to"`
+	Height   string   `json:"height"`
+
+	Sign      bool `json:"sign"`
+	Broadcast bool `json:"broadcast"`
+	Wait      bool `json:"wait"`
+}
+
+// the body of POST /sign and /broadcast. It's read with wire, so Tx is [typeByte, {...}]
+type gatewaySignedTx struct {
+	Tx   types.Tx `json:"tx"`
+	Wait bool     `json:"wait"`
+}
+
+type GatewayResult struct {
+	BlockHash string `json:"block_hash,omitempty"`
+	Hash      string `json:"hash,omitempty"`
+	Address   string `json:"address,omitempty"`
+	Return    string `json:"return,omitempty"`
+	Exception string `json:"exception,omitempty"`
+}
+
+type GatewayResponse struct {
+	Tx     json.RawMessage `json:"tx,omitempty"`
+	Result *GatewayResult  `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resp, err := g.handle(w, r)
+	status := http.StatusOK
+	if err != nil {
+		status = http.StatusInternalServerError
+		if herr, ok := err.(*httpError); ok {
+			status = herr.status
+		}
+		resp = &GatewayResponse{Error: err.Error()}
+		logger.Infof("Gateway %s %s from %s failed: %v\n", r.Method, r.URL.Path, r.RemoteAddr, err)
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (g *Gateway) handle(w http.ResponseWriter, r *http.Request) (*GatewayResponse, error) {
+	token, ok := g.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	if !ok {
+		return nil, httpErrorf(http.StatusUnauthorized, "missing or unknown api token")
+	}
+	if r.Method != "POST" {
+		return nil, httpErrorf(http.StatusMethodNotAllowed, "expected POST")
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		return nil, httpErrorf(http.StatusBadRequest, "error reading request: %v", err)
+	}
+
+	var (
+		tx                    types.Tx
+		sign, broadcast, wait bool
+	)
+	switch path := r.URL.Path; {
+	case strings.HasPrefix(path, "/tx/"):
+		var req GatewayTxRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, httpErrorf(http.StatusBadRequest, "error reading request: %v", err)
+		}
+		if tx, err = g.buildTx(strings.TrimPrefix(path, "/tx/"), &req); err != nil {
+			return nil, err
+		}
+		sign, broadcast, wait = req.Sign, req.Broadcast, req.Wait
+	case path == "/sign" || path == "/broadcast":
+		var req gatewaySignedTx
+		wire.ReadJSON(&req, body, &err)
+		if err != nil || req.Tx == nil {
+			return nil, httpErrorf(http.StatusBadRequest, "error reading tx: %v", err)
+		}
+		tx = req.Tx
+		sign, broadcast, wait = path == "/sign", path == "/broadcast", req.Wait
+	default:
+		return nil, httpErrorf(http.StatusNotFound, "unknown endpoint %s", path)
+	}
+
+	txType, inputs := txTypeAndInputs(tx)
+	if len(inputs) == 0 {
+		return nil, httpErrorf(http.StatusBadRequest, "tx has no inputs")
+	}
+	for _, from := range inputs {
+		if !token.allows(txType, from) {
+			return nil, httpErrorf(http.StatusForbidden, "token may not be used for %s txs from %X", txType, from)
+		}
+	}
+
+	resp := new(GatewayResponse)
+	if sign || broadcast {
+		result, err := g.signAndBroadcast(tx, sign, broadcast, wait)
+		if err != nil {
+			return nil, httpErrorf(http.StatusBadGateway, "%v", err)
+		}
+		if result != nil {
+			resp.Result = &GatewayResult{
+				BlockHash: fmt.Sprintf("%X", result.BlockHash),
+				Hash:      fmt.Sprintf("%X", result.Hash),
+				Address:   fmt.Sprintf("%X", result.Address),
+				Return:    fmt.Sprintf("%X", result.Return),
+				Exception: result.Exception,
+			}
+		}
+	}
+	resp.Tx = wire.JSONBytes(&tx)
+	return resp, nil
+}
+
+func (g *Gateway) buildTx(txType string, req *GatewayTxRequest) (tx types.Tx, err error) {
+	// the signer knows the pubkeys of its keys, so the address is enough
+	if req.Pubkey == "" && req.Addr != "" && txType != "unbond" && txType != "rebond" {
+		if addr, err := hex.DecodeString(req.Addr); err != nil || len(addr) != 20 {
+			return nil, httpErrorf(http.StatusBadRequest, "addr must be 20 bytes of hex")
+		}
+		if req.Pubkey, err = g.pub(req.Addr); err != nil {
+			return nil, httpErrorf(http.StatusBadGateway, "error fetching pubkey for %s: %v", req.Addr, err)
+		}
+	}
+	nodeAddr, signAddr := g.config.NodeAddr, g.config.SignAddr
+	switch txType {
+	case "send":
+		tx, err = Send(nodeAddr, signAddr, req.Pubkey, req.Addr, req.To, req.Amt, req.Nonce)
+	case "call":
+		tx, err = Call(nodeAddr, signAddr, req.Pubkey, req.Addr, req.To, req.Amt, req.Nonce, req.Gas, req.Fee, req.Data)
+	case "name":
+		if err = CheckNameData(req.Name, req.Data, ""); err == nil {
+			tx, err = Name(nodeAddr, signAddr, req.Pubkey, req.Addr, req.Amt, req.Nonce, req.Fee, req.Name, req.Data)
+		}
+	case "permissions":
+		tx, err = Permissions(nodeAddr, signAddr, req.Pubkey, req.Addr, req.Nonce, req.PermFunc, req.PermArgs)
+	case "bond":
+		tx, err = Bond(nodeAddr, req.Pubkey, req.UnbondTo, req.Amt, req.Nonce)
+	case "unbond":
+		tx, err = Unbond(req.Addr, req.Height)
+	case "rebond":
+		tx, err = Rebond(req.Addr, req.Height)
+	default:
+		return nil, httpErrorf(http.StatusNotFound, "unknown tx type %s. Expected one of %s", txType, strings.Join(GatewayTxTypes, ", "))
+	}
+	if err != nil {
+		return nil, httpErrorf(http.StatusBadRequest, "%v", err)
+	}
+	return tx, nil
+}
+
+// the gateway's name for a tx's type, and the addresses it's from.
+// A SendTx or BondTx with several inputs needs a token that allows all of them
+func txTypeAndInputs(tx types.Tx) (string, [][]byte) {
+	switch tx := tx.(type) {
+	case *types.SendTx:
+		return "send", inputAddresses(tx.Inputs)
+	case *types.CallTx:
+		return "call", inputAddresses([]*types.TxInput{tx.Input})
+	case *types.NameTx:
+		return "name", inputAddresses([]*types.TxInput{tx.Input})
+	case *types.PermissionsTx:
+		return "permissions", inputAddresses([]*types.TxInput{tx.Input})
+	case *types.BondTx:
+		return "bond", inputAddresses(tx.Inputs)
+	case *types.UnbondTx:
+		return "unbond", [][]byte{tx.Address}
+	case *types.RebondTx:
+		return "rebond", [][]byte{tx.Address}
+	}
+	return "unknown", nil
+}
+
+func inputAddresses(inputs []*types.TxInput) [][]byte {
+	var addrs [][]byte
+	for _, in := range inputs {
+		if in != nil {
+			addrs = append(addrs, in.Address)
+		}
+	}
+	return addrs
+}
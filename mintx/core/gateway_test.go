@@ -0,0 +1,153 @@
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/wire"
+)
+
+func testGateway(t *testing.T, tokens string) (*httptest.Server, *[]types.Tx) {
+	dir, err := ioutil.TempDir("", "mintx-gateway")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "tokens.json")
+	ioutil.WriteFile(file, []byte(tokens), 0600)
+	toks, err := LoadGatewayTokens(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGateway(GatewayConfig{Tokens: toks})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sent []types.Tx
+	g.signAndBroadcast = func(tx types.Tx, sign, broadcast, wait bool) (*TxResult, error) {
+		sent = append(sent, tx)
+		if !broadcast {
+			return nil, nil
+		}
+		return &TxResult{Hash: []byte{0xAB}}, nil
+	}
+	g.pub = func(addr string) (string, error) {
+		return testPubKey, nil
+	}
+	return httptest.NewServer(g), &sent
+}
+
+func gatewayPost(t *testing.T, url, token, body string) (int, GatewayResponse) {
+	req, _ := http.NewRequest("POST", url, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var r GatewayResponse
+	json.NewDecoder(resp.Body).Decode(&r)
+	return resp.StatusCode, r
+}
+
+func TestGateway(t *testing.T) {
+	pubBytes, _ := hex.DecodeString(testPubKey)
+	var pub account.PubKeyEd25519
+	copy(pub[:], pubBytes)
+	addr := hex.EncodeToString(pub.Address())
+
+	server, sent := testGateway(t, `[
+		{"token": "names", "txs": ["name"], "from": ["`+addr+`"]},
+		{"token": "all", "txs": ["*"], "from": ["*"]}
+	]`)
+	defer server.Close()
+
+	nameReq := `{"addr": "` + addr + `", "amt": "10", "nonce": "1", "fee": "0", "name": "x", "data": "y", "broadcast": true}`
+	for _, c := range []struct {
+		url, token, body string
+		status           int
+	}{
+		{"/tx/name", "", nameReq, http.StatusUnauthorized},
+		{"/tx/name", "nope", nameReq, http.StatusUnauthorized},
+		{"/tx/send", "names", `{"pubkey": "` + testPubKey + `", "to": "` + addr + `", "amt": "10", "nonce": "1"}`, http.StatusForbidden},
+		{"/tx/name", "names", strings.Replace(nameReq, addr, "zz", 1), http.StatusBadRequest},
+		{"/tx/name", "names", strings.Replace(nameReq, `"amt": "10"`, `"amt": "ten"`, 1), http.StatusBadRequest},
+		{"/tx/nope", "all", `{}`, http.StatusNotFound},
+		{"/tx/name", "names", nameReq, http.StatusOK},
+	} {
+		status, r := gatewayPost(t, server.URL+c.url, c.token, c.body)
+		if status != c.status {
+			t.Fatalf("POST %s with %q: expected %d, got %d (%s)", c.url, c.token, c.status, status, r.Error)
+		}
+	}
+
+	// the pubkey came from the signer, and the tx was broadcast
+	if len(*sent) != 1 {
+		t.Fatalf("expected one tx sent, got %d", len(*sent))
+	}
+	tx, ok := (*sent)[0].(*types.NameTx)
+	if !ok || tx.Input.PubKey == nil || tx.Name != "x" || tx.Input.Amount != 10 {
+		t.Fatalf("bad tx %v", (*sent)[0])
+	}
+
+	// round trip the built tx through /sign
+	status, r := gatewayPost(t, server.URL+"/tx/name", "names", strings.Replace(nameReq, `"broadcast": true`, `"broadcast": false`, 1))
+	if status != http.StatusOK || r.Result != nil {
+		t.Fatalf("bad build response %d %v", status, r)
+	}
+	status, r = gatewayPost(t, server.URL+"/sign", "names", `{"tx": `+string(r.Tx)+`}`)
+	if status != http.StatusOK {
+		t.Fatalf("bad sign response %d %v", status, r.Error)
+	}
+	var signed gatewaySignedTx
+	var err error
+	wire.ReadJSON(&signed, []byte(`{"tx": `+string(r.Tx)+`}`), &err)
+	if named, ok := signed.Tx.(*types.NameTx); err != nil || !ok || named.Name != "x" {
+		t.Fatalf("bad signed tx %s: %v", r.Tx, err)
+	}
+
+	// the names token can't broadcast someone else's tx
+	other := types.NewSendTx()
+	other.AddInputWithNonce(pub, 10, 1)
+	other.AddOutput(pub.Address(), 10)
+	other.Inputs[0].Address = make([]byte, 20)
+	var otherTx types.Tx = other
+	body := `{"tx": ` + string(wire.JSONBytes(&otherTx)) + `}`
+	if status, _ := gatewayPost(t, server.URL+"/broadcast", "names", body); status != http.StatusForbidden {
+		t.Fatalf("expected forbidden, got %d", status)
+	}
+	if status, r := gatewayPost(t, server.URL+"/broadcast", "all", body); status != http.StatusOK || r.Result.Hash != "AB" {
+		t.Fatalf("bad broadcast response %d %v", status, r)
+	}
+}
+
+func TestLoadGatewayTokens(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mintx-gateway")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "tokens.json")
+	for _, bad := range []string{
+		`{}`,
+		`[{"token": "", "txs": ["*"]}]`,
+		`[{"token": "a"}, {"token": "a"}]`,
+		`[{"token": "a", "txs": ["dupeout"]}]`,
+		`[{"token": "a", "from": ["abcd"]}]`,
+	} {
+		ioutil.WriteFile(file, []byte(bad), 0600)
+		if _, err := LoadGatewayTokens(file); err == nil {
+			t.Fatalf("expected error loading %s", bad)
+		}
+	}
+}
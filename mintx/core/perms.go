@@ -0,0 +1,261 @@
+package core
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/eris-ltd/mint-client/mintx/client"
+
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------------
+// the snative functions, as PermissionsTx args
+
+// a snative function and how to build its args from strings
+type PermFunc struct {
+	Name string
+	Args []string // names of the args, for help and errors
+	Help string
+
+	// only contracts may call it. The chain rejects it in a PermissionsTx
+	ContractOnly bool
+
+	build func(args []string) (ptypes.PermArgs, error)
+}
+
+func (f *PermFunc) Usage() string {
+	return f.Name + " <" + strings.Join(f.Args, "> <") + ">"
+}
+
+// every function in permission/types/snatives.go
+var PermFuncs = []*PermFunc{
+	{
+		Name: "has_base", Args: []string{"address", "permission"},
+		Help:         "check if an account has a base permission",
+		ContractOnly: true,
+		build: func(args []string) (ptypes.PermArgs, error) {
+			addr, pF, err := decodeAddressPermFlag(args[0], args[1])
+			return &ptypes.HasBaseArgs{Address: addr, Permission: pF}, err
+		},
+	},
+	{
+		Name: "set_base", Args: []string{"address", "permission", "value"},
+		Help: "set a base permission on an account",
+		build: func(args []string) (ptypes.PermArgs, error) {
+			addr, pF, err := decodeAddressPermFlag(args[0], args[1])
+			if err != nil {
+				return nil, err
+			}
+			value, err := parsePermValue(args[2])
+			return &ptypes.SetBaseArgs{Address: addr, Permission: pF, Value: value}, err
+		},
+	},
+	{
+		Name: "unset_base", Args: []string{"address", "permission"},
+		Help: "unset a base permission on an account, so it falls back to the global one",
+		build: func(args []string) (ptypes.PermArgs, error) {
+			addr, pF, err := decodeAddressPermFlag(args[0], args[1])
+			return &ptypes.UnsetBaseArgs{Address: addr, Permission: pF}, err
+		},
+	},
+	{
+		Name: "set_global", Args: []string{"permission", "value"},
+		Help: "set a permission for every account that hasn't set it",
+		build: func(args []string) (ptypes.PermArgs, error) {
+			pF, err := parsePermFlag(args[0])
+			if err != nil {
+				return nil, err
+			}
+			value, err := parsePermValue(args[1])
+			return &ptypes.SetGlobalArgs{Permission: pF, Value: value}, err
+		},
+	},
+	{
+		Name: "has_role", Args: []string{"address", "role"},
+		Help:         "check if an account has a role",
+		ContractOnly: true,
+		build: func(args []string) (ptypes.PermArgs, error) {
+			addr, role, err := decodeAddressRole(args[0], args[1])
+			return &ptypes.HasRoleArgs{Address: addr, Role: role}, err
+		},
+	},
+	{
+		Name: "add_role", Args: []string{"address", "role"},
+		Help: "give an account a role",
+		build: func(args []string) (ptypes.PermArgs, error) {
+			addr, role, err := decodeAddressRole(args[0], args[1])
+			return &ptypes.AddRoleArgs{Address: addr, Role: role}, err
+		},
+	},
+	{
+		Name: "rm_role", Args: []string{"address", "role"},
+		Help: "take a role from an account",
+		build: func(args []string) (ptypes.PermArgs, error) {
+			addr, role, err := decodeAddressRole(args[0], args[1])
+			return &ptypes.RmRoleArgs{Address: addr, Role: role}, err
+		},
+	},
+}
+
+func LookupPermFunc(name string) (*PermFunc, error) {
+	for _, f := range PermFuncs {
+		if f.Name == name {
+			return f, nil
+		}
+	}
+	names := make([]string, len(PermFuncs))
+	for i, f := range PermFuncs {
+		names[i] = f.Name
+	}
+	return nil, fmt.Errorf("Unknown permission function %q. Options are %s", name, strings.Join(names, ", "))
+}
+
+// the functions and their args, one per line
+func PermFuncsHelp() string {
+	var lines []string
+	for _, f := range PermFuncs {
+		line := fmt.Sprintf("%-45s %s", f.Usage(), f.Help)
+		if f.ContractOnly {
+			line += " (contracts only)"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// check the args for a function, and build them
+func ParsePermArgs(permFunc string, argsS []string) (ptypes.PermArgs, error) {
+	f, err := LookupPermFunc(permFunc)
+	if err != nil {
+		return nil, err
+	}
+	if f.ContractOnly {
+		return nil, fmt.Errorf("%s is for contracts and can't be sent in a PermissionsTx. Query the account instead", f.Name)
+	}
+	if len(argsS) != len(f.Args) {
+		return nil, fmt.Errorf("%s takes %d args (%s), got %d", f.Name, len(f.Args), f.Usage(), len(argsS))
+	}
+	args, err := f.build(argsS)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", f.Name, err)
+	}
+	return args, nil
+}
+
+// split "func args... func args..." into one change per function, using their arity
+func SplitPermChanges(argsS []string) ([][]string, error) {
+	var changes [][]string
+	for len(argsS) > 0 {
+		f, err := LookupPermFunc(argsS[0])
+		if err != nil {
+			return nil, err
+		}
+		n := len(f.Args) + 1
+		if len(argsS) < n {
+			return nil, fmt.Errorf("%s takes %d args (%s), got %d", f.Name, len(f.Args), f.Usage(), len(argsS)-1)
+		}
+		changes = append(changes, argsS[:n])
+		argsS = argsS[n:]
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("Please enter the permission function you'd like to call, followed by its arguments")
+	}
+	return changes, nil
+}
+
+// a PermissionsTx for each change, with sequential nonces.
+// Every change is checked before any tx is made
+func PermissionsBatch(nodeAddr, signAddr, pubkey, addrS, nonceS string, argsS []string) ([]*types.PermissionsTx, error) {
+	changes, err := SplitPermChanges(argsS)
+	if err != nil {
+		return nil, err
+	}
+	permArgs := make([]ptypes.PermArgs, len(changes))
+	for i, change := range changes {
+		if permArgs[i], err = ParsePermArgs(change[0], change[1:]); err != nil {
+			return nil, err
+		}
+	}
+
+	in, _, err := parseInput(pubkey, addrS, "0", nonceS)
+	if err != nil {
+		return nil, err
+	}
+	c := newClient(nodeAddr, signAddr)
+	pub, nonce, err := c.ResolveInput(context.Background(), in)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]*types.PermissionsTx, len(permArgs))
+	for i, args := range permArgs {
+		txs[i], err = c.Permissions(context.Background(), client.PermissionsOpts{
+			Input: client.Input{PubKey: pub, Nonce: nonce + i},
+			Args:  args,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return txs, nil
+}
+
+// a permission by name (eg. send) or by its flag (eg. 2)
+func parsePermFlag(s string) (ptypes.PermFlag, error) {
+	if pF, err := ptypes.PermStringToFlag(strings.ToLower(s)); err == nil {
+		return pF, nil
+	}
+	n, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Unknown permission %s", s)
+	}
+	pF := ptypes.PermFlag(n)
+	if pF == 0 || pF > ptypes.TopPermFlag || pF&(pF-1) != 0 {
+		return 0, fmt.Errorf("Permission %s must be a single flag, a power of 2 up to %d", s, ptypes.TopPermFlag)
+	}
+	return pF, nil
+}
+
+func parsePermValue(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "true", "1":
+		return true, nil
+	case "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("Unknown value %s. Use true, false, 1 or 0", s)
+}
+
+func decodeAddressPermFlag(addrS, permFlagS string) (addr []byte, pFlag ptypes.PermFlag, err error) {
+	if addr, err = decodeAddress(addrS); err != nil {
+		return
+	}
+	pFlag, err = parsePermFlag(permFlagS)
+	return
+}
+
+func decodeAddressRole(addrS, role string) (addr []byte, _ string, err error) {
+	if addr, err = decodeAddress(addrS); err != nil {
+		return
+	}
+	// roles are stored in a word
+	if role == "" || len(role) > 32 {
+		return nil, "", fmt.Errorf("role must be 1 to 32 bytes, got %d", len(role))
+	}
+	return addr, role, nil
+}
+
+func decodeAddress(addrS string) ([]byte, error) {
+	addr, err := hex.DecodeString(addrS)
+	if err != nil {
+		return nil, fmt.Errorf("address is bad hex: %v", err)
+	}
+	if len(addr) != 20 {
+		return nil, fmt.Errorf("address must be 20 bytes, got %d", len(addr))
+	}
+	return addr, nil
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eris-ltd/mint-client/fakenode"
+
+	ptypes "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/permission/types"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+)
+
+const permAddr = "0102030405060708090A0B0C0D0E0F1011121314"
+
+func TestParsePermArgs(t *testing.T) {
+	args, err := ParsePermArgs("set_base", []string{permAddr, "2", "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := args.(*ptypes.SetBaseArgs); a.Permission != ptypes.Send || !a.Value {
+		t.Fatalf("bad args %v", a)
+	}
+	args, err = ParsePermArgs("set_global", []string{"Create_Account", "false"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := args.(*ptypes.SetGlobalArgs); a.Permission != ptypes.CreateAccount || a.Value {
+		t.Fatalf("bad args %v", a)
+	}
+
+	// typos are errors, not panics
+	bad := [][]string{
+		{"set_base"},
+		{"set_base", permAddr},
+		{"set_base", permAddr, "send"},
+		{"set_base", permAddr, "send", "yes"},
+		{"set_base", permAddr, "sned", "true"},
+		{"set_base", permAddr, "3", "true"},
+		{"set_base", "0102", "send", "true"},
+		{"unset_base", "zz", "send"},
+		{"set_global", "send"},
+		{"add_role", permAddr, ""},
+		{"rm_role", permAddr},
+		{"has_base", permAddr, "send"},
+		{"has_role", permAddr, "admin"},
+		{"set_bass", permAddr, "send", "true"},
+	}
+	for _, b := range bad {
+		if _, err := ParsePermArgs(b[0], b[1:]); err == nil {
+			t.Fatalf("expected error for %v", b)
+		}
+	}
+
+	changes, err := SplitPermChanges([]string{"set_global", "send", "1", "add_role", permAddr, "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || len(changes[1]) != 3 {
+		t.Fatalf("bad changes %v", changes)
+	}
+	if _, err := SplitPermChanges([]string{"add_role", permAddr, "admin", "rm_role", permAddr}); err == nil {
+		t.Fatal("expected error for a short last change")
+	}
+}
+
+func TestPermissionsBatch(t *testing.T) {
+	node := fakenode.New(fakenode.Config{Accounts: 2})
+	defer node.Close()
+	root, other := node.Accounts[0], node.Accounts[1]
+	otherS := fmt.Sprintf("%X", other.Address)
+
+	// the pubkey for the address comes from the keys daemon
+	txs, err := PermissionsBatch(node.URL, node.KeysURL, "", fmt.Sprintf("%X", root.Address), "", []string{
+		"set_base", otherS, "bond", "true",
+		"add_role", otherS, "admin",
+		"unset_base", otherS, "64",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 3 {
+		t.Fatalf("expected 3 txs, got %d", len(txs))
+	}
+	for i, tx := range txs {
+		if tx.Input.Sequence != i+1 {
+			t.Fatalf("expected nonce %d, got %d", i+1, tx.Input.Sequence)
+		}
+		if _, err := SignAndBroadcast(node.ChainID, node.URL, node.KeysURL, tx, true, true, i == len(txs)-1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	acc, err := cclient.NewClient(node.URL, "JSONRPC").GetAccount(other.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	perms := acc.Account.Permissions
+	if v, err := perms.Base.Get(ptypes.Bond); err != nil || !v {
+		t.Fatalf("expected bond to be set, got %v %v", v, err)
+	}
+	if _, err := perms.Base.Get(ptypes.Name); err == nil {
+		t.Fatal("expected name to be unset")
+	}
+	if !perms.HasRole("admin") {
+		t.Fatalf("expected admin role, got %v", perms.Roles)
+	}
+}
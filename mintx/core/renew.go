@@ -0,0 +1,136 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------------
+// renewing name reg entries before they expire
+
+// how many blocks to wait for a renewal to land before sending it again
+const renewPendingBlocks = 10
+
+// extend an entry by blocks blocks, keeping its data
+func RenewEntry(nodeAddr, signAddr, pubkey, addr, nonceS string, fee int64, blocks int, entry *types.NameRegEntry) (*types.NameTx, error) {
+	return dnsNameTx(nodeAddr, signAddr, pubkey, addr, nonceS, fee, NameCost(entry.Name, entry.Data, blocks), entry.Name, entry.Data)
+}
+
+// entries owned by owner that expire within `within` blocks of height.
+// If names are given, only those are watched, and they're renewed even if they've already expired
+// (so long as no one else has taken them)
+func ExpiringNames(entries []*types.NameRegEntry, height int, owner []byte, within int, names []string) []*types.NameRegEntry {
+	watch := make(map[string]bool)
+	for _, name := range names {
+		watch[name] = true
+	}
+	var expiring []*types.NameRegEntry
+	for _, entry := range entries {
+		if !bytes.Equal(entry.Owner, owner) || entry.Expires > height+within {
+			continue
+		}
+		if len(names) > 0 && !watch[entry.Name] {
+			continue
+		}
+		if entry.Expires <= height && !watch[entry.Name] {
+			continue
+		}
+		expiring = append(expiring, entry)
+	}
+	return expiring
+}
+
+type pendingRenewal struct {
+	expires int // the entry's expiry when we sent the renewal
+	height  int
+}
+
+// Renewer finds the names we own that are about to expire and builds the txs to renew them
+type Renewer struct {
+	nodeAddr string
+	signAddr string
+	pubkey   string
+	addr     string
+	fee      int64
+	blocks   int // how long to extend names by
+	within   int // renew names expiring within this many blocks
+	names    []string
+	owner    []byte
+
+	pending map[string]pendingRenewal // sent, but not landed yet
+	checked map[string]pendingRenewal // built by the last Check, but not sent
+}
+
+func NewRenewer(nodeAddr, signAddr, pubkey, addr, feeS, ttlBlocksS string, within int, names []string) (*Renewer, error) {
+	if nodeAddr == "" {
+		return nil, fmt.Errorf("must specify a node with --node-addr (or MINTX_NODE_ADDR) to watch names on")
+	}
+	blocks, err := parseLease(ttlBlocksS)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := parseFee(feeS)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := signerAddress(pubkey, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Renewer{
+		nodeAddr: nodeAddr,
+		signAddr: signAddr,
+		pubkey:   pubkey,
+		addr:     addr,
+		fee:      fee,
+		blocks:   blocks,
+		within:   within,
+		names:    names,
+		owner:    owner,
+		pending:  make(map[string]pendingRenewal),
+	}, nil
+}
+
+// the txs to renew our names that are about to expire, with sequential nonces
+// starting from the account's next nonce on the node.
+// Names we've sent a renewal for (see Sent) are skipped until it lands,
+// or until renewPendingBlocks have passed without it landing
+func (r *Renewer) Check() ([]*types.NameTx, error) {
+	client := cclient.NewClient(r.nodeAddr, "HTTP")
+	res, err := client.ListNames()
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching names from node (%s): %v", r.nodeAddr, err)
+	}
+	height := res.BlockHeight
+
+	var (
+		txs    []*types.NameTx
+		nonceS string
+	)
+	r.checked = make(map[string]pendingRenewal)
+	for _, entry := range ExpiringNames(res.Names, height, r.owner, r.within, r.names) {
+		if p, ok := r.pending[entry.Name]; ok && p.expires == entry.Expires && height < p.height+renewPendingBlocks {
+			continue
+		}
+		tx, err := RenewEntry(r.nodeAddr, r.signAddr, r.pubkey, r.addr, nonceS, r.fee, r.blocks, entry)
+		if err != nil {
+			return txs, fmt.Errorf("Error building renewal for %s: %v", entry.Name, err)
+		}
+		nonceS = strconv.Itoa(tx.Input.Sequence + 1)
+		r.checked[entry.Name] = pendingRenewal{entry.Expires, height}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// record that a renewal from the last Check was broadcast
+func (r *Renewer) Sent(tx *types.NameTx) {
+	if p, ok := r.checked[tx.Name]; ok {
+		r.pending[tx.Name] = p
+		delete(r.checked, tx.Name)
+	}
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/eris-ltd/mint-client/fakenode"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+func TestExpiringNames(t *testing.T) {
+	me, them := []byte{0x01}, []byte{0x02}
+	entries := []*types.NameRegEntry{
+		{Name: "soon", Owner: me, Expires: 150},
+		{Name: "later", Owner: me, Expires: 500},
+		{Name: "theirs", Owner: them, Expires: 120},
+		{Name: "lapsed", Owner: me, Expires: 90},
+	}
+	names := func(watch ...string) (s []string) {
+		for _, entry := range ExpiringNames(entries, 100, me, 100, watch) {
+			s = append(s, entry.Name)
+		}
+		return
+	}
+
+	// lapsed names are only renewed if we ask for them
+	if got := names(); len(got) != 1 || got[0] != "soon" {
+		t.Fatalf("expected [soon], got %v", got)
+	}
+	if got := names("lapsed", "theirs", "later"); len(got) != 1 || got[0] != "lapsed" {
+		t.Fatalf("expected [lapsed], got %v", got)
+	}
+}
+
+func TestRenewer(t *testing.T) {
+	node := fakenode.New(fakenode.Config{})
+	defer node.Close()
+	addr := fmt.Sprintf("%X", node.Accounts[0].Address)
+
+	amt := strconv.FormatInt(NameCost("renew.me", "x", 50), 10)
+	tx, err := Name(node.URL, node.KeysURL, "", addr, amt, "", "0", "renew.me", "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SignAndBroadcast(node.ChainID, node.URL, node.KeysURL, tx, true, true, true); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewRenewer(node.URL, node.KeysURL, "", addr, "0", "100", 1000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func(expected int) []*types.NameTx {
+		txs, err := r.Check()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(txs) != expected {
+			t.Fatalf("expected %d renewals, got %d", expected, len(txs))
+		}
+		return txs
+	}
+
+	// renewals that weren't sent are built again, with the node's nonce
+	check(1)
+	txs := check(1)
+	if txs[0].Name != "renew.me" || txs[0].Input.Sequence != 2 {
+		t.Fatalf("bad renewal %s with nonce %d", txs[0].Name, txs[0].Input.Sequence)
+	}
+	r.Sent(txs[0])
+	check(0)
+}
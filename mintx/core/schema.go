@@ -0,0 +1,430 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------------
+// checking name reg data before we pay to store it
+
+func ValidateNameData(name, data string) error {
+	if len(name) == 0 {
+		return fmt.Errorf("name must not be empty")
+	}
+	if len(name) > types.MaxNameLength {
+		return fmt.Errorf("name is %d bytes. Max %d", len(name), types.MaxNameLength)
+	}
+	if loc := invalidChar(types.RegexpAlphaNum, name); loc != nil {
+		return fmt.Errorf("name has invalid character %q at %d. Only alphanumerics, '.', '_' and '/' are allowed", name[loc[0]:loc[1]], loc[0])
+	}
+	if len(data) > types.MaxDataLength {
+		return fmt.Errorf("data is %d bytes. Max %d", len(data), types.MaxDataLength)
+	}
+	if loc := invalidChar(types.RegexpJSON, data); loc != nil {
+		return fmt.Errorf("data has invalid character %q at %d. Only alphanumerics, whitespace and the punctuation found in json are allowed", data[loc[0]:loc[1]], loc[0])
+	}
+	// the length limits may have moved on
+	return (&types.NameTx{Name: name, Data: data}).ValidateStrings()
+}
+
+// the position of the first character the registry's rule rejects, so the
+// error can say what's wrong
+func invalidChar(rule *regexp.Regexp, s string) []int {
+	for i, c := range s {
+		if !rule.MatchString(string(c)) {
+			return []int{i, i + utf8.RuneLen(c)}
+		}
+	}
+	return nil
+}
+
+// check the name and data against the registry's rules, and the schema if one is given
+func CheckNameData(name, data, schemaS string) error {
+	if err := ValidateNameData(name, data); err != nil {
+		return err
+	}
+	if schemaS == "" {
+		return nil
+	}
+	schema, err := LoadSchema(schemaS)
+	if err != nil {
+		return err
+	}
+	return schema.ValidateData(data)
+}
+
+//------------------------------------------------------------------------------------
+// schemas for name reg data.
+// A subset of json schema: type, properties, required, additionalProperties,
+// items, enum, pattern, format (ipv4, ipv6, hostname), and the length, item and number bounds
+// Any other keyword is an error, since we can't check it
+
+// built in schemas, by name
+var Schemas = map[string]string{
+	"dns": dnsSchema,
+}
+
+const dnsSchema = `{
+	"title": "dns record",
+	"type": "object",
+	"properties": {
+		"fqdn": {"type": "string", "format": "hostname", "description": "fully qualified domain name"},
+		"address": {"type": "string", "minLength": 1, "maxLength": 255, "description": "ip address, hostname or text"},
+		"type": {"type": "string", "enum": ["A", "AAAA", "CNAME", "NS", "TXT"], "description": "record type"}
+	},
+	"required": ["fqdn", "address", "type"],
+	"additionalProperties": false
+}`
+
+type Schema struct {
+	Title       string
+	Description string
+
+	Types                []string
+	Properties           map[string]*Schema
+	Required             []string
+	AdditionalProperties *Schema
+	NoAdditional         bool
+	Items                *Schema
+	Enum                 []interface{}
+	Pattern              *regexp.Regexp
+	Format               string
+	MinLength, MaxLength *int
+	MinItems, MaxItems   *int
+	Minimum, Maximum     *float64
+
+	// extra checks for built in schemas
+	check func(v interface{}) error
+}
+
+// a built in schema by name, or a json schema file
+func LoadSchema(nameOrFile string) (*Schema, error) {
+	if s, ok := Schemas[nameOrFile]; ok {
+		schema, err := ParseSchema([]byte(s))
+		if err != nil {
+			return nil, err
+		}
+		if nameOrFile == "dns" {
+			schema.check = checkDNSRecord
+		}
+		return schema, nil
+	}
+	b, err := ioutil.ReadFile(nameOrFile)
+	if err != nil {
+		return nil, fmt.Errorf("Unknown schema %s. Must be a json schema file or one of %s", nameOrFile, strings.Join(SchemaNames(), ", "))
+	}
+	schema, err := ParseSchema(b)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing schema %s: %v", nameOrFile, err)
+	}
+	return schema, nil
+}
+
+func SchemaNames() []string {
+	var names []string
+	for name := range Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func checkDNSRecord(v interface{}) error {
+	m := v.(map[string]interface{})
+	r := &DNSRecord{FQDN: m["fqdn"].(string), Address: m["address"].(string), Type: m["type"].(string)}
+	return r.Validate()
+}
+
+type rawSchema struct {
+	Title                string                     `json:"title"`
+	Description          string                     `json:"description"`
+	Type                 json.RawMessage            `json:"type"`
+	Properties           map[string]json.RawMessage `json:"properties"`
+	Required             []string                   `json:"required"`
+	AdditionalProperties json.RawMessage            `json:"additionalProperties"`
+	Items                json.RawMessage            `json:"items"`
+	Enum                 []interface{}              `json:"enum"`
+	Pattern              string                     `json:"pattern"`
+	Format               string                     `json:"format"`
+	MinLength            *int                       `json:"minLength"`
+	MaxLength            *int                       `json:"maxLength"`
+	MinItems             *int                       `json:"minItems"`
+	MaxItems             *int                       `json:"maxItems"`
+	Minimum              *float64                   `json:"minimum"`
+	Maximum              *float64                   `json:"maximum"`
+}
+
+// the keywords rawSchema implements. "$schema" only names the draft
+var schemaKeywords = map[string]bool{
+	"$schema": true, "title": true, "description": true, "type": true, "properties": true,
+	"required": true, "additionalProperties": true, "items": true, "enum": true, "pattern": true,
+	"format": true, "minLength": true, "maxLength": true, "minItems": true, "maxItems": true,
+	"minimum": true, "maximum": true,
+}
+
+var schemaTypes = map[string]bool{"object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true, "null": true}
+
+func ParseSchema(b []byte) (*Schema, error) {
+	var raw rawSchema
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	// rather than validate less than the schema says
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(b, &keywords); err != nil {
+		return nil, err
+	}
+	var unknown []string
+	for key := range keywords {
+		if !schemaKeywords[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unsupported keyword %s", strings.Join(unknown, ", "))
+	}
+	s := &Schema{
+		Title:       raw.Title,
+		Description: raw.Description,
+		Required:    raw.Required,
+		Enum:        raw.Enum,
+		Format:      raw.Format,
+		MinLength:   raw.MinLength,
+		MaxLength:   raw.MaxLength,
+		MinItems:    raw.MinItems,
+		MaxItems:    raw.MaxItems,
+		Minimum:     raw.Minimum,
+		Maximum:     raw.Maximum,
+	}
+
+	if len(raw.Type) > 0 {
+		var typ string
+		if err := json.Unmarshal(raw.Type, &typ); err == nil {
+			s.Types = []string{typ}
+		} else if err := json.Unmarshal(raw.Type, &s.Types); err != nil {
+			return nil, fmt.Errorf("type must be a string or a list of strings")
+		}
+		for _, typ := range s.Types {
+			if !schemaTypes[typ] {
+				return nil, fmt.Errorf("unknown type %q", typ)
+			}
+		}
+	}
+
+	switch s.Format {
+	case "", "ipv4", "ipv6", "hostname":
+	default:
+		return nil, fmt.Errorf("unknown format %q", s.Format)
+	}
+
+	if raw.Pattern != "" {
+		re, err := regexp.Compile(raw.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("bad pattern: %v", err)
+		}
+		s.Pattern = re
+	}
+
+	if len(raw.Properties) > 0 {
+		s.Properties = make(map[string]*Schema)
+		for name, b := range raw.Properties {
+			prop, err := ParseSchema(b)
+			if err != nil {
+				return nil, fmt.Errorf("property %s: %v", name, err)
+			}
+			s.Properties[name] = prop
+		}
+	}
+
+	if len(raw.AdditionalProperties) > 0 {
+		var allowed bool
+		if err := json.Unmarshal(raw.AdditionalProperties, &allowed); err == nil {
+			s.NoAdditional = !allowed
+		} else if s.AdditionalProperties, err = ParseSchema(raw.AdditionalProperties); err != nil {
+			return nil, fmt.Errorf("additionalProperties: %v", err)
+		}
+	}
+
+	if len(raw.Items) > 0 {
+		items, err := ParseSchema(raw.Items)
+		if err != nil {
+			return nil, fmt.Errorf("items: %v", err)
+		}
+		s.Items = items
+	}
+	return s, nil
+}
+
+// validate name reg data, which must be json
+func (s *Schema) ValidateData(data string) error {
+	var v interface{}
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		return fmt.Errorf("data is not valid json: %v", err)
+	}
+	return s.Validate(v)
+}
+
+// validate a decoded json value
+func (s *Schema) Validate(v interface{}) error {
+	if err := s.validate(v, "data"); err != nil {
+		return err
+	}
+	if s.check != nil {
+		return s.check(v)
+	}
+	return nil
+}
+
+func (s *Schema) validate(v interface{}, path string) error {
+	if len(s.Types) > 0 {
+		ok := false
+		for _, typ := range s.Types {
+			if isType(v, typ) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%s must be %s", path, strings.Join(s.Types, " or "))
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		ok := false
+		for _, e := range s.Enum {
+			if fmt.Sprint(e) == fmt.Sprint(v) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%s must be one of %v", path, s.Enum)
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		return s.validateString(v, path)
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			return fmt.Errorf("%s must be at least %v", path, *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			return fmt.Errorf("%s must be at most %v", path, *s.Maximum)
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			return fmt.Errorf("%s must have at least %d items", path, *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			return fmt.Errorf("%s must have at most %d items", path, *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s is missing %s", path, name)
+			}
+		}
+		// in order, so the errors are deterministic
+		var keys []string
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			prop, ok := s.Properties[key]
+			if !ok {
+				if s.NoAdditional {
+					return fmt.Errorf("%s has unknown property %s", path, key)
+				}
+				prop = s.AdditionalProperties
+			}
+			if prop != nil {
+				if err := prop.validate(v[key], path+"."+key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateString(v, path string) error {
+	if s.MinLength != nil && len(v) < *s.MinLength {
+		return fmt.Errorf("%s must be at least %d characters", path, *s.MinLength)
+	}
+	if s.MaxLength != nil && len(v) > *s.MaxLength {
+		return fmt.Errorf("%s must be at most %d characters", path, *s.MaxLength)
+	}
+	if s.Pattern != nil && !s.Pattern.MatchString(v) {
+		return fmt.Errorf("%s must match %s", path, s.Pattern)
+	}
+	switch s.Format {
+	case "ipv4":
+		if ip := net.ParseIP(v); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("%s must be an IPv4 address", path)
+		}
+	case "ipv6":
+		if ip := net.ParseIP(v); ip == nil || ip.To4() != nil {
+			return fmt.Errorf("%s must be an IPv6 address", path)
+		}
+	case "hostname":
+		if err := validateHostname(strings.TrimSuffix(v, ".")); err != nil {
+			return fmt.Errorf("%s must be a hostname: %v", path, err)
+		}
+	}
+	return nil
+}
+
+func isType(v interface{}, typ string) bool {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return typ == "object"
+	case []interface{}:
+		return typ == "array"
+	case string:
+		return typ == "string"
+	case bool:
+		return typ == "boolean"
+	case nil:
+		return typ == "null"
+	case float64:
+		return typ == "number" || typ == "integer" && v == float64(int64(v))
+	}
+	return false
+}
+
+// the object properties in the order to show them: the schema's, then any others
+func (s *Schema) PropertyNames(v map[string]interface{}) []string {
+	var names, others []string
+	for name := range s.Properties {
+		if _, ok := v[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for name := range v {
+		if _, ok := s.Properties[name]; !ok {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	return append(names, others...)
+}
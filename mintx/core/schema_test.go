@@ -0,0 +1,94 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateNameData(t *testing.T) {
+	if err := ValidateNameData("casey", `{"a": [1, 2], "b": "c d"}`); err != nil {
+		t.Fatal(err)
+	}
+	bad := map[[2]string]string{
+		{"", "x"}:                       "empty",
+		{strings.Repeat("a", 33), "x"}:  "33 bytes",
+		{"my-name", "x"}:                `"-" at 2`,
+		{"casey", "v=spf1"}:             `"=" at 1`,
+		{"casey", "psh; we're lawyers"}: `";" at 3`,
+	}
+	for in, expected := range bad {
+		err := ValidateNameData(in[0], in[1])
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected error containing %q for %v, got %v", expected, in, err)
+		}
+	}
+}
+
+func TestSchema(t *testing.T) {
+	dns, err := LoadSchema("dns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dns.ValidateData(`{"fqdn":"magma.interblock.io","address":"1.2.3.4","type":"A"}`); err != nil {
+		t.Fatal(err)
+	}
+	for data, expected := range map[string]string{
+		`{"fqdn":"magma.interblock.io","address":"1.2.3.4"}`:                    "missing type",
+		`{"fqdn":"magma.interblock.io","address":"1.2.3.4","type":"MX"}`:        "data.type must be one of",
+		`{"fqdn":"magma.interblock.io","address":"1.2.3.4","type":"A","ttl":1}`: "unknown property ttl",
+		`{"fqdn":"magma.interblock.io","address":"2001:db8::1","type":"A"}`:     "IPv4",
+		`{"fqdn":"not a host","address":"1.2.3.4","type":"A"}`:                  "data.fqdn must be a hostname",
+		`["magma.interblock.io"]`:                                               "data must be object",
+		`{"fqdn":`:                                                              "not valid json",
+	} {
+		err := dns.ValidateData(data)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected error containing %q for %s, got %v", expected, data, err)
+		}
+	}
+
+	schema, err := ParseSchema([]byte(`{
+		"type": "object",
+		"properties": {
+			"owners": {"type": "array", "items": {"type": "string", "pattern": "^[0-9A-F]{40}$"}, "minItems": 1},
+			"threshold": {"type": "integer", "minimum": 1}
+		},
+		"additionalProperties": {"type": "string"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := strings.Repeat("AB", 20)
+	if err := schema.ValidateData(`{"owners":["` + owner + `"],"threshold":1,"note":"hi"}`); err != nil {
+		t.Fatal(err)
+	}
+	for data, expected := range map[string]string{
+		`{"owners":[]}`:     "at least 1 items",
+		`{"owners":["ab"]}`: "data.owners[0] must match",
+		`{"owners":["` + owner + `"],"threshold":1.5}`: "must be integer",
+		`{"threshold":0}`: "at least 1",
+		`{"note":1}`:      "data.note must be string",
+	} {
+		err := schema.ValidateData(data)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected error containing %q for %s, got %v", expected, data, err)
+		}
+	}
+
+	if _, err := ParseSchema([]byte(`{"type": "thing"}`)); err == nil {
+		t.Fatal("expected error for unknown type")
+	}
+	for in, expected := range map[string]string{
+		`{"$schema": "http://json-schema.org/draft-04/schema#", "oneOf": [{"type": "string"}], "not": {}}`: "unsupported keyword not, oneOf",
+		`{"properties": {"owner": {"$ref": "#/definitions/addr"}}}`:                                        "property owner: unsupported keyword $ref",
+		`{"items": {"type": "string", "const": "x"}}`:                                                      "items: unsupported keyword const",
+	} {
+		_, err := ParseSchema([]byte(in))
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected error containing %q for %s, got %v", expected, in, err)
+		}
+	}
+	if _, err := LoadSchema("nosuchschema"); err == nil {
+		t.Fatal("expected error for unknown schema")
+	}
+}
@@ -0,0 +1,608 @@
+package core
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/account"
+	cclient "github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/rpc/core_client"
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+//------------------------------------------------------------------------------------
+// bind zone files for the dns records in the name registry.
+// The registry holds one record per name, so a zone only round trips
+// if it has one A, AAAA, CNAME, NS or TXT record per name
+
+// default ttl for exported zones. The registry doesn't store ttls
+const ZoneTTL = 3600
+
+// the dns record in an entry's data, or nil if it isn't one
+func EntryDNSRecord(entry *types.NameRegEntry) *DNSRecord {
+	r := new(DNSRecord)
+	if err := json.Unmarshal([]byte(entry.Data), r); err != nil {
+		return nil
+	}
+	r.FQDN = entry.Name
+	r.Type = strings.ToUpper(r.Type)
+	if r.Validate() != nil {
+		return nil
+	}
+	return r
+}
+
+func inZone(name, domain string) bool {
+	name, domain = strings.ToLower(name), strings.ToLower(domain)
+	return name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+// the dns records under domain that haven't expired by height, sorted by name
+func ZoneRecords(domain string, height int, entries []*types.NameRegEntry) []*DNSRecord {
+	domain = strings.TrimSuffix(domain, ".")
+	var records []*DNSRecord
+	for _, entry := range entries {
+		if entry.Expires <= height || !inZone(entry.Name, domain) {
+			continue
+		}
+		if r := EntryDNSRecord(entry); r != nil {
+			records = append(records, r)
+		}
+	}
+	sort.Sort(byName(records))
+	return records
+}
+
+type byName []*DNSRecord
+
+func (r byName) Len() int           { return len(r) }
+func (r byName) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r byName) Less(i, j int) bool { return r[i].FQDN < r[j].FQDN }
+
+// write the unexpired dns records under domain as a zone file.
+// The SOA serial is the block height.
+// NS records holding an address are written as ns1.<fqdn> with a glue record, as mintdns serves them
+func WriteZone(w io.Writer, domain string, height int, entries []*types.NameRegEntry) error {
+	domain = strings.TrimSuffix(domain, ".")
+	if err := validateHostname(domain); err != nil {
+		return fmt.Errorf("domain %q is invalid: %v", domain, err)
+	}
+	records := ZoneRecords(domain, height, entries)
+
+	primary := "ns1." + domain + "."
+	for _, r := range records {
+		if r.Type == "NS" && r.FQDN == domain && net.ParseIP(r.Address) == nil {
+			primary = absName(r.Address)
+		}
+	}
+
+	fmt.Fprintf(w, "; name registry records under %s at block %d\n", domain, height)
+	fmt.Fprintf(w, "$ORIGIN %s.\n", domain)
+	fmt.Fprintf(w, "$TTL %d\n", ZoneTTL)
+	fmt.Fprintf(w, "@\tIN\tSOA\t%s hostmaster.%s. %d 3600 600 86400 %d\n", primary, domain, height, ZoneTTL)
+	for _, r := range records {
+		owner := relName(r.FQDN, domain)
+		switch r.Type {
+		case "CNAME":
+			fmt.Fprintf(w, "%s\tIN\tCNAME\t%s\n", owner, absName(r.Address))
+		case "TXT":
+			fmt.Fprintf(w, "%s\tIN\tTXT\t%s\n", owner, quoteTXT(r.Address))
+		case "NS":
+			ip := net.ParseIP(r.Address)
+			if ip == nil {
+				fmt.Fprintf(w, "%s\tIN\tNS\t%s\n", owner, absName(r.Address))
+				continue
+			}
+			typ := "A"
+			if ip.To4() == nil {
+				typ = "AAAA"
+			}
+			fmt.Fprintf(w, "%s\tIN\tNS\t%s\n", owner, "ns1."+r.FQDN+".")
+			fmt.Fprintf(w, "%s\tIN\t%s\t%s\n", relName("ns1."+r.FQDN, domain), typ, r.Address)
+		default:
+			fmt.Fprintf(w, "%s\tIN\t%s\t%s\n", owner, r.Type, r.Address)
+		}
+	}
+	return nil
+}
+
+func absName(name string) string {
+	return strings.TrimSuffix(name, ".") + "."
+}
+
+func relName(name, domain string) string {
+	if strings.EqualFold(name, domain) {
+		return "@"
+	}
+	return name[:len(name)-len(domain)-1]
+}
+
+func quoteTXT(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return `"` + strings.Replace(s, `"`, `\"`, -1) + `"`
+}
+
+//------------------------------------------------------------------------------------
+// parsing zone files
+
+// a record we can't put in the registry, and why
+type SkippedRecord struct {
+	Line   int
+	Name   string
+	Type   string
+	Reason string
+}
+
+func (s SkippedRecord) String() string {
+	return fmt.Sprintf("line %d: %s %s: %s", s.Line, s.Name, s.Type, s.Reason)
+}
+
+type Zone struct {
+	Origin  string // the origin of the first record
+	Records []*DNSRecord
+	Skipped []SkippedRecord
+}
+
+type zoneRecord struct {
+	line   int
+	record *DNSRecord
+}
+
+// parse the A, AAAA, CNAME, NS and TXT records in a zone file.
+// origin is used until the file sets its own with $ORIGIN.
+// Records the registry can't hold are skipped, so long as the file parses.
+// An NS record for ns1.<name> with an address record for ns1.<name> in the zone
+// is stored as an NS record holding the address, which is how mintdns serves them
+func ParseZone(r io.Reader, origin string) (*Zone, error) {
+	origin = strings.ToLower(strings.TrimSuffix(origin, "."))
+	var (
+		zone    = new(Zone)
+		records []zoneRecord
+		owner   string
+	)
+
+	lines, err := zoneLines(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		fields := l.fields
+		switch strings.ToUpper(fields[0]) {
+		case "$ORIGIN":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: $ORIGIN takes one name", l.line)
+			}
+			origin = strings.ToLower(qualify(fields[1], origin))
+			continue
+		case "$TTL":
+			continue
+		case "$INCLUDE", "$GENERATE":
+			return nil, fmt.Errorf("line %d: %s is not supported", l.line, fields[0])
+		}
+
+		if !l.continued {
+			if fields[0] == "@" {
+				if origin == "" {
+					return nil, fmt.Errorf("line %d: @ used without an origin", l.line)
+				}
+				owner = origin
+			} else {
+				owner = strings.ToLower(qualify(fields[0], origin))
+			}
+			fields = fields[1:]
+		} else if owner == "" {
+			return nil, fmt.Errorf("line %d: record has no name", l.line)
+		}
+
+		// optional ttl and class, in either order
+		class := "IN"
+		for len(fields) > 0 {
+			f := strings.ToUpper(fields[0])
+			if f == "IN" || f == "CH" || f == "HS" || f == "CS" {
+				class = f
+			} else if !isTTL(f) {
+				break
+			}
+			fields = fields[1:]
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected a type and data", l.line)
+		}
+		typ, rdata := strings.ToUpper(fields[0]), fields[1:]
+
+		if zone.Origin == "" {
+			zone.Origin = origin
+		}
+		skip := func(reason string) {
+			zone.Skipped = append(zone.Skipped, SkippedRecord{l.line, owner, typ, reason})
+		}
+		if class != "IN" {
+			skip("only IN records are supported")
+			continue
+		}
+
+		var value string
+		switch typ {
+		case "A", "AAAA":
+			value = rdata[0]
+		case "CNAME", "NS":
+			value = qualify(rdata[0], origin)
+		case "TXT":
+			value = strings.Join(rdata, "")
+		case "SOA":
+			skip("SOA records aren't stored in the registry")
+			continue
+		default:
+			skip("the registry only holds A, AAAA, CNAME, NS and TXT records")
+			continue
+		}
+		rec, err := NewDNSRecord(owner, typ, value)
+		if err != nil {
+			skip(err.Error())
+			continue
+		}
+		records = append(records, zoneRecord{l.line, rec})
+	}
+
+	records = collapseGlue(records)
+
+	// one record per name
+	seen := make(map[string]bool)
+	for _, zr := range records {
+		if seen[zr.record.FQDN] {
+			zone.Skipped = append(zone.Skipped, SkippedRecord{zr.line, zr.record.FQDN, zr.record.Type, "the registry holds one record per name"})
+			continue
+		}
+		seen[zr.record.FQDN] = true
+		zone.Records = append(zone.Records, zr.record)
+	}
+	return zone, nil
+}
+
+// turn NS records for ns1.<name> with an address in the zone
+// into NS records holding the address, and drop the address records
+func collapseGlue(records []zoneRecord) []zoneRecord {
+	addrs := make(map[string]string)
+	for _, zr := range records {
+		if zr.record.Type == "A" || zr.record.Type == "AAAA" {
+			if _, ok := addrs[zr.record.FQDN]; !ok {
+				addrs[zr.record.FQDN] = zr.record.Address
+			}
+		}
+	}
+	glue := make(map[string]bool)
+	for _, zr := range records {
+		r := zr.record
+		host := "ns1." + r.FQDN
+		if r.Type == "NS" && strings.ToLower(r.Address) == host {
+			if addr, ok := addrs[host]; ok {
+				r.Address = addr
+				glue[host] = true
+			}
+		}
+	}
+	var out []zoneRecord
+	for _, zr := range records {
+		if !glue[zr.record.FQDN] {
+			out = append(out, zr)
+		}
+	}
+	return out
+}
+
+// fully qualify a name, without the trailing dot
+func qualify(name, origin string) string {
+	if strings.HasSuffix(name, ".") {
+		return strings.TrimSuffix(name, ".")
+	}
+	if name == "@" {
+		return origin
+	}
+	if origin == "" {
+		return name
+	}
+	return name + "." + origin
+}
+
+// ttls are seconds or bind style durations like 1h30m
+func isTTL(s string) bool {
+	if s == "" {
+		return false
+	}
+	if _, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return true
+	}
+	digits := false
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			digits = true
+		case strings.ContainsRune("SMHDW", c) && digits:
+			digits = false
+		default:
+			return false
+		}
+	}
+	return !digits
+}
+
+type zoneLine struct {
+	line      int
+	continued bool // starts with whitespace, so it's for the previous name
+	fields    []string
+}
+
+// split a zone file into records, dropping comments and joining parentheses
+func zoneLines(r io.Reader) ([]zoneLine, error) {
+	var (
+		lines   []zoneLine
+		cur     *zoneLine
+		parens  int
+		lineNum int
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNum++
+		text := scanner.Text()
+		fields, open, err := tokenize(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+		if cur == nil {
+			if len(fields) == 0 && open == 0 {
+				continue
+			}
+			cur = &zoneLine{line: lineNum, continued: text[0] == ' ' || text[0] == '\t'}
+		}
+		cur.fields = append(cur.fields, fields...)
+		if parens += open; parens < 0 {
+			return nil, fmt.Errorf("line %d: unbalanced parentheses", lineNum)
+		}
+		if parens == 0 {
+			if len(cur.fields) > 0 {
+				lines = append(lines, *cur)
+			}
+			cur = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if parens != 0 {
+		return nil, fmt.Errorf("unclosed parentheses")
+	}
+	return lines, nil
+}
+
+// split a line into fields, unquoting strings.
+// Also returns the change in the number of open parentheses
+func tokenize(line string) (fields []string, open int, err error) {
+	var (
+		field   []byte
+		inField bool
+		quoted  bool
+	)
+	flush := func() {
+		if inField {
+			fields = append(fields, string(field))
+		}
+		field, inField = nil, false
+	}
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line):
+			i++
+			field, inField = append(field, line[i]), true
+		case quoted:
+			if c == '"' {
+				quoted = false
+			} else {
+				field = append(field, c)
+			}
+		case c == '"':
+			quoted, inField = true, true
+		case c == ';':
+			flush()
+			return fields, open, nil
+		case c == '(' || c == ')':
+			flush()
+			if c == '(' {
+				open++
+			} else {
+				open--
+			}
+		case c == ' ' || c == '\t':
+			flush()
+		default:
+			field, inField = append(field, c), true
+		}
+	}
+	if quoted {
+		return nil, 0, fmt.Errorf("unterminated string")
+	}
+	flush()
+	return fields, open, nil
+}
+
+//------------------------------------------------------------------------------------
+// importing zones
+
+const (
+	ZoneAdd       = "add"
+	ZoneUpdate    = "update"
+	ZoneUnchanged = "unchanged"
+	ZoneConflict  = "conflict"   // owned by someone else
+	ZoneChainOnly = "chain-only" // on chain but not in the zone. We leave these alone
+	ZoneInvalid   = "invalid"    // the registry won't take the name or data
+)
+
+type ZoneChange struct {
+	Action string
+	Name   string
+	Old    *DNSRecord
+	New    *DNSRecord
+	Owner  []byte
+	Err    error
+}
+
+func (c *ZoneChange) String() string {
+	switch c.Action {
+	case ZoneAdd:
+		return fmt.Sprintf("+ %s %s %s", c.Name, c.New.Type, c.New.Address)
+	case ZoneUpdate:
+		return fmt.Sprintf("~ %s %s %s -> %s %s", c.Name, c.Old.Type, c.Old.Address, c.New.Type, c.New.Address)
+	case ZoneConflict:
+		return fmt.Sprintf("! %s %s %s (owned by %X)", c.Name, c.New.Type, c.New.Address, c.Owner)
+	case ZoneInvalid:
+		return fmt.Sprintf("! %s %s %s (%v)", c.Name, c.New.Type, c.New.Address, c.Err)
+	case ZoneChainOnly:
+		return fmt.Sprintf("  %s %s %s (only on chain, not removed)", c.Name, c.Old.Type, c.Old.Address)
+	default:
+		return fmt.Sprintf("= %s %s %s", c.Name, c.New.Type, c.New.Address)
+	}
+}
+
+// compare zone records to the registry, sorted by name.
+// Unexpired entries with a different owner can't be overwritten
+func DiffZone(records []*DNSRecord, domain string, height int, entries []*types.NameRegEntry, owner []byte) []*ZoneChange {
+	domain = strings.TrimSuffix(domain, ".")
+	onChain := make(map[string]*types.NameRegEntry)
+	for _, entry := range entries {
+		if entry.Expires > height {
+			onChain[entry.Name] = entry
+		}
+	}
+
+	var changes []*ZoneChange
+	inFile := make(map[string]bool)
+	for _, r := range records {
+		inFile[r.FQDN] = true
+		c := &ZoneChange{Action: ZoneAdd, Name: r.FQDN, New: r}
+		if entry, ok := onChain[r.FQDN]; ok {
+			c.Old = EntryDNSRecord(entry)
+			switch {
+			case owner != nil && string(entry.Owner) != string(owner):
+				c.Action, c.Owner = ZoneConflict, entry.Owner
+			case c.Old != nil && sameRecord(c.Old, r):
+				c.Action = ZoneUnchanged
+			default:
+				c.Action = ZoneUpdate
+				if c.Old == nil {
+					c.Old = &DNSRecord{FQDN: entry.Name, Type: "-", Address: entry.Data}
+				}
+			}
+		}
+		changes = append(changes, c)
+	}
+	for _, r := range ZoneRecords(domain, height, entries) {
+		if !inFile[r.FQDN] {
+			changes = append(changes, &ZoneChange{Action: ZoneChainOnly, Name: r.FQDN, Old: r})
+		}
+	}
+	sort.Sort(byChangeName(changes))
+	return changes
+}
+
+type byChangeName []*ZoneChange
+
+func (c byChangeName) Len() int           { return len(c) }
+func (c byChangeName) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c byChangeName) Less(i, j int) bool { return c[i].Name < c[j].Name }
+
+func sameRecord(a, b *DNSRecord) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	ipA, ipB := net.ParseIP(a.Address), net.ParseIP(b.Address)
+	switch {
+	case ipA != nil || ipB != nil:
+		return ipA.Equal(ipB)
+	case a.Type == "TXT":
+		return a.Address == b.Address
+	default:
+		return strings.EqualFold(strings.TrimSuffix(a.Address, "."), strings.TrimSuffix(b.Address, "."))
+	}
+}
+
+type ZoneImport struct {
+	Changes []*ZoneChange
+	Txs     []*types.NameTx
+	Total   int64 // sum of the tx amounts, fees included
+}
+
+// diff a zone file against the registry and build a NameTx for each added or updated record,
+// paying for ttlBlocks blocks. Nonces count up from nonceS (or the account's next nonce)
+func ImportZone(nodeAddr, signAddr, pubkey, addr, nonceS, feeS, ttlBlocksS string, zone *Zone) (*ZoneImport, error) {
+	if nodeAddr == "" {
+		return nil, fmt.Errorf("must specify a node with --node-addr (or MINTX_NODE_ADDR) to diff the zone against")
+	}
+	blocks, err := parseLease(ttlBlocksS)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := parseFee(feeS)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := signerAddress(pubkey, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	client := cclient.NewClient(nodeAddr, "HTTP")
+	r, err := client.ListNames()
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching names from node (%s): %v", nodeAddr, err)
+	}
+
+	imp := &ZoneImport{Changes: DiffZone(zone.Records, zone.Origin, r.BlockHeight, r.Names, owner)}
+	for _, c := range imp.Changes {
+		if c.Action != ZoneAdd && c.Action != ZoneUpdate {
+			continue
+		}
+		b, err := json.Marshal(c.New)
+		if err != nil {
+			return nil, err
+		}
+		data := string(b)
+		if err := (&types.NameTx{Name: c.Name, Data: data}).ValidateStrings(); err != nil {
+			c.Action, c.Err = ZoneInvalid, err
+			continue
+		}
+		tx, err := dnsNameTx(nodeAddr, signAddr, pubkey, addr, nonceS, fee, NameCost(c.Name, data, blocks), c.Name, data)
+		if err != nil {
+			return nil, fmt.Errorf("Error building tx for %s: %v", c.Name, err)
+		}
+		nonceS = strconv.Itoa(tx.Input.Sequence + 1)
+		imp.Txs = append(imp.Txs, tx)
+		imp.Total += tx.Input.Amount
+	}
+	return imp, nil
+}
+
+func signerAddress(pubkey, addr string) ([]byte, error) {
+	if pubkey != "" {
+		pubKeyBytes, err := hex.DecodeString(pubkey)
+		if err != nil {
+			return nil, fmt.Errorf("pubkey is bad hex: %v", err)
+		}
+		var pubArray [32]byte
+		copy(pubArray[:], pubKeyBytes)
+		return account.PubKeyEd25519(pubArray).Address(), nil
+	}
+	if addr == "" {
+		return nil, fmt.Errorf("at least one of --pubkey or --addr must be given")
+	}
+	addrBytes, err := hex.DecodeString(addr)
+	if err != nil {
+		return nil, fmt.Errorf("addr is bad hex: %v", err)
+	}
+	return addrBytes, nil
+}
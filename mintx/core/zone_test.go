@@ -0,0 +1,145 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/tendermint/tendermint/types"
+)
+
+var testZone = `$ORIGIN interblock.io.
+$TTL 1h
+@	IN	SOA	ns1.interblock.io. hostmaster.interblock.io. (
+		2015080101 ; serial
+		3600 600 86400 60 )
+magma		IN	A	1.2.3.4
+	300	IN	MX	10 mail.interblock.io.
+www	60	IN	CNAME	magma
+six	IN	AAAA	2001:db8::1
+txt	IN	TXT	"v=spf1 -all" " and more; not a comment"
+newdomain.io.	IN	NS	ns1.newdomain.io.
+ns1.newdomain.io.	IN	A	4.3.2.1
+magma		IN	A	5.6.7.8
+`
+
+func TestParseZone(t *testing.T) {
+	zone, err := ParseZone(strings.NewReader(testZone), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zone.Origin != "interblock.io" {
+		t.Fatalf("bad origin %q", zone.Origin)
+	}
+	var got []string
+	for _, r := range zone.Records {
+		got = append(got, fmt.Sprintf("%s %s %s", r.FQDN, r.Type, r.Address))
+	}
+	expected := []string{
+		"magma.interblock.io A 1.2.3.4",
+		"www.interblock.io CNAME magma.interblock.io",
+		"six.interblock.io AAAA 2001:db8::1",
+		"txt.interblock.io TXT v=spf1 -all and more; not a comment",
+		"newdomain.io NS 4.3.2.1",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("bad records:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+
+	// the SOA, the MX and the second A record for magma
+	if len(zone.Skipped) != 3 {
+		t.Fatalf("expected 3 skipped records, got %v", zone.Skipped)
+	}
+	if s := zone.Skipped[1]; s.Line != 7 || s.Name != "magma.interblock.io" || s.Type != "MX" {
+		t.Fatalf("bad skipped record %v", s)
+	}
+
+	for _, bad := range []string{
+		"@ IN A 1.2.3.4\n",          // no origin
+		"$ORIGIN io.\n@ IN SOA (\n", // unclosed
+		"$ORIGIN io.\nx IN TXT \"abc\n",
+		"$ORIGIN io.\n$INCLUDE other.zone\n",
+	} {
+		if _, err := ParseZone(strings.NewReader(bad), ""); err == nil {
+			t.Fatalf("expected error parsing %q", bad)
+		}
+	}
+}
+
+func testEntry(r *DNSRecord, expires int, owner []byte) *types.NameRegEntry {
+	b, _ := json.Marshal(r)
+	return &types.NameRegEntry{Name: r.FQDN, Data: string(b), Expires: expires, Owner: owner}
+}
+
+func TestZoneRoundTrip(t *testing.T) {
+	zone, err := ParseZone(strings.NewReader(testZone), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []*types.NameRegEntry
+	for _, r := range zone.Records {
+		entries = append(entries, testEntry(r, 100, nil))
+	}
+	entries = append(entries, &types.NameRegEntry{Name: "notdns.interblock.io", Data: "hello", Expires: 100})
+	entries = append(entries, testEntry(&DNSRecord{"old.interblock.io", "1.1.1.1", "A"}, 5, nil))
+
+	buf := new(bytes.Buffer)
+	if err := WriteZone(buf, "interblock.io", 10, entries); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"$ORIGIN interblock.io.\n",
+		"@\tIN\tSOA\tns1.interblock.io. hostmaster.interblock.io. 10 3600 600 86400 3600\n",
+		"magma\tIN\tA\t1.2.3.4\n",
+		"www\tIN\tCNAME\tmagma.interblock.io.\n",
+		"txt\tIN\tTXT\t\"v=spf1 -all and more; not a comment\"\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Fatalf("expected %q in zone:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, "notdns") || strings.Contains(out, "old") || strings.Contains(out, "newdomain") {
+		t.Fatalf("unexpected records in zone:\n%s", out)
+	}
+
+	// the other domain, with glue for the ns
+	buf.Reset()
+	WriteZone(buf, "newdomain.io", 10, entries)
+	zone2, err := ParseZone(buf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zone2.Records) != 1 || zone2.Records[0].Type != "NS" || zone2.Records[0].Address != "4.3.2.1" {
+		t.Fatalf("bad round trip of ns record: %v", zone2.Records)
+	}
+}
+
+func TestDiffZone(t *testing.T) {
+	me, them := []byte{0x01}, []byte{0x02}
+	records := []*DNSRecord{
+		{"a.interblock.io", "1.2.3.4", "A"},
+		{"b.interblock.io", "magma.interblock.io", "CNAME"},
+		{"c.interblock.io", "1.2.3.4", "A"},
+		{"d.interblock.io", "1.2.3.4", "A"},
+		{"e.interblock.io", "1.2.3.4", "A"},
+	}
+	entries := []*types.NameRegEntry{
+		testEntry(&DNSRecord{"b.interblock.io", "magma.interblock.io.", "CNAME"}, 100, me),
+		testEntry(&DNSRecord{"c.interblock.io", "4.3.2.1", "A"}, 100, me),
+		testEntry(&DNSRecord{"d.interblock.io", "4.3.2.1", "A"}, 100, them),
+		testEntry(&DNSRecord{"e.interblock.io", "4.3.2.1", "A"}, 5, them),
+		testEntry(&DNSRecord{"f.interblock.io", "4.3.2.1", "A"}, 100, them),
+		testEntry(&DNSRecord{"g.otherdomain.io", "4.3.2.1", "A"}, 100, them),
+	}
+	var got []string
+	for _, c := range DiffZone(records, "interblock.io", 10, entries, me) {
+		got = append(got, c.Name+" "+c.Action)
+	}
+	expected := "a.interblock.io add,b.interblock.io unchanged,c.interblock.io update,d.interblock.io conflict,e.interblock.io add,f.interblock.io chain-only"
+	if strings.Join(got, ",") != expected {
+		t.Fatalf("bad diff %v, expected %s", got, expected)
+	}
+}
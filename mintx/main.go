@@ -3,6 +3,10 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
+
+	"github.com/eris-ltd/mint-client/mintx/core"
 
 	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/codegangsta/cli"
 	"github.com/eris-ltd/mint-client/Godeps/_workspace/src/github.com/eris-ltd/common/go/log"
@@ -126,6 +130,11 @@ func main() {
 			Usage: "specify a file with some data",
 		}
 
+		schemaFlag = cli.StringFlag{
+			Name:  "schema",
+			Usage: "validate the data against a json schema file or a built in schema (dns)",
+		}
+
 		toFlag = cli.StringFlag{
 			Name:  "to",
 			Usage: "specify an address to send to",
@@ -151,6 +160,91 @@ func main() {
 			Usage: "specify a height to unbond at",
 		}
 
+		fqdnFlag = cli.StringFlag{
+			Name:  "fqdn",
+			Usage: "specify the fully qualified domain name of a dns record",
+		}
+
+		typeFlag = cli.StringFlag{
+			Name:  "type",
+			Usage: "specify the type of a dns record (A, AAAA, CNAME, NS or TXT)",
+		}
+
+		valueFlag = cli.StringFlag{
+			Name:  "value",
+			Usage: "specify the value of a dns record (an ip address, hostname or text)",
+		}
+
+		ttlBlocksFlag = cli.StringFlag{
+			Name:  "ttl-blocks",
+			Usage: "specify how many blocks to pay for a name registry entry",
+		}
+
+		renewWithinFlag = cli.IntFlag{
+			Name:  "renew-within",
+			Usage: "renew names that expire within this many blocks",
+			Value: 100,
+		}
+
+		intervalFlag = cli.DurationFlag{
+			Name:  "interval",
+			Usage: "how often to check for names to renew",
+			Value: 30 * time.Second,
+		}
+
+		namesFlag = cli.StringFlag{
+			Name:  "names",
+			Usage: "comma separated list of names to watch (defaults to all the names we own)",
+		}
+
+		listenFlag = cli.StringFlag{
+			Name:  "listen",
+			Usage: "address to serve on",
+			Value: ":8080",
+		}
+
+		fromFlag = cli.StringFlag{
+			Name:  "from",
+			Usage: "address of the key in the signing daemon to send from",
+		}
+
+		amountFlag = cli.StringFlag{
+			Name:  "amount",
+			Usage: "amount to send per request",
+		}
+
+		addrLimitFlag = cli.DurationFlag{
+			Name:  "addr-limit",
+			Usage: "how often an address may be funded",
+			Value: 24 * time.Hour,
+		}
+
+		ipLimitFlag = cli.DurationFlag{
+			Name:  "ip-limit",
+			Usage: "how often an ip may request funds",
+			Value: time.Hour,
+		}
+
+		dailyBudgetFlag = cli.StringFlag{
+			Name:  "daily-budget",
+			Usage: "total amount to send per day (0 for no limit)",
+		}
+
+		checkPermsFlag = cli.BoolFlag{
+			Name:  "check-perms",
+			Usage: "refuse to fund new accounts unless the faucet has the create_account permission",
+		}
+
+		tokensFlag = cli.StringFlag{
+			Name:  "tokens",
+			Usage: "json file of api tokens and the tx types and from-addresses each may use",
+		}
+
+		originFlag = cli.StringFlag{
+			Name:  "origin",
+			Usage: "specify the origin of a zone file that doesn't set one with $ORIGIN",
+		}
+
 		//Formatting Flags
 		debugFlag = cli.BoolFlag{
 			Name:  "debug",
@@ -202,11 +296,169 @@ func main() {
 				nameFlag,
 				dataFlag,
 				dataFileFlag,
+				schemaFlag,
 				feeFlag,
 				nonceFlag,
 			},
 		}
 
+		dnsCmd = cli.Command{
+			Name:  "dns",
+			Usage: "Manage dns records in the name registry",
+			Subcommands: []cli.Command{
+				{
+					Name:   "set",
+					Usage:  "mintx dns set --fqdn <fqdn> --type <A|AAAA|CNAME|NS|TXT> --value <value> --ttl-blocks <blocks>",
+					Action: cliDNSSet,
+					Flags: []cli.Flag{
+						signAddrFlag,
+						nodeAddrFlag,
+
+						chainidFlag,
+						pubkeyFlag,
+						addrFlag,
+
+						signFlag,
+						broadcastFlag,
+						waitFlag,
+
+						fqdnFlag,
+						typeFlag,
+						valueFlag,
+						ttlBlocksFlag,
+						feeFlag,
+						nonceFlag,
+					},
+				},
+				{
+					Name:   "rm",
+					Usage:  "mintx dns rm --fqdn <fqdn>",
+					Action: cliDNSRm,
+					Flags: []cli.Flag{
+						signAddrFlag,
+						nodeAddrFlag,
+
+						chainidFlag,
+						pubkeyFlag,
+						addrFlag,
+
+						signFlag,
+						broadcastFlag,
+						waitFlag,
+
+						fqdnFlag,
+						feeFlag,
+						nonceFlag,
+					},
+				},
+				{
+					Name:   "renew",
+					Usage:  "mintx dns renew --fqdn <fqdn> --ttl-blocks <blocks>",
+					Action: cliDNSRenew,
+					Flags: []cli.Flag{
+						signAddrFlag,
+						nodeAddrFlag,
+
+						chainidFlag,
+						pubkeyFlag,
+						addrFlag,
+
+						signFlag,
+						broadcastFlag,
+						waitFlag,
+
+						fqdnFlag,
+						ttlBlocksFlag,
+						feeFlag,
+						nonceFlag,
+					},
+				},
+			},
+		}
+
+		namesCmd = cli.Command{
+			Name:  "names",
+			Usage: "Manage batches of name registry entries",
+			Subcommands: []cli.Command{
+				{
+					Name:   "import-zone",
+					Usage:  "mintx names import-zone <file> --ttl-blocks <blocks>",
+					Action: cliImportZone,
+					Flags: []cli.Flag{
+						signAddrFlag,
+						nodeAddrFlag,
+
+						chainidFlag,
+						pubkeyFlag,
+						addrFlag,
+
+						signFlag,
+						broadcastFlag,
+						waitFlag,
+
+						originFlag,
+						ttlBlocksFlag,
+						feeFlag,
+						nonceFlag,
+					},
+				},
+				{
+					Name:   "renew-daemon",
+					Usage:  "mintx names renew-daemon --ttl-blocks <blocks> --renew-within <blocks>",
+					Action: cliRenewDaemon,
+					Flags: []cli.Flag{
+						signAddrFlag,
+						nodeAddrFlag,
+
+						chainidFlag,
+						pubkeyFlag,
+						addrFlag,
+
+						waitFlag,
+
+						namesFlag,
+						renewWithinFlag,
+						intervalFlag,
+						ttlBlocksFlag,
+						feeFlag,
+					},
+				},
+			},
+		}
+
+		faucetCmd = cli.Command{
+			Name:   "faucet",
+			Usage:  "mintx faucet --listen :8080 --from <addr> --amount <amt>",
+			Action: cliFaucet,
+			Flags: []cli.Flag{
+				signAddrFlag,
+				nodeAddrFlag,
+				chainidFlag,
+
+				listenFlag,
+				fromFlag,
+				amountFlag,
+				addrLimitFlag,
+				ipLimitFlag,
+				dailyBudgetFlag,
+				checkPermsFlag,
+			},
+		}
+
+		serveCmd = cli.Command{
+			Name:   "serve",
+			Usage:  "mintx serve --listen localhost:8080 --tokens <file>",
+			Action: cliServe,
+			Flags: []cli.Flag{
+				signAddrFlag,
+				nodeAddrFlag,
+				chainidFlag,
+
+				listenFlag,
+				tokensFlag,
+			},
+		}
+
 		callCmd = cli.Command{
 			Name:   "call",
 			Usage:  "mintx call --amt <amt> --fee <fee> --gas <gas> --to <contract addr> --data <data>",
@@ -293,8 +545,11 @@ func main() {
 		}
 
 		permissionsCmd = cli.Command{
-			Name:   "perm",
-			Usage:  "mintx perm <function name> <args ...>",
+			Name:  "perm",
+			Usage: "mintx perm <function name> <args ...> [<function name> <args ...> ...]",
+			Description: "set permissions and roles. Permissions are names (eg. send) or flags (eg. 2), values are true, false, 1 or 0.\n" +
+				"   Several changes may be given, and each is sent as a tx with the next nonce. The functions are:\n\n   " +
+				strings.Replace(core.PermFuncsHelp(), "\n", "\n   ", -1),
 			Action: cliPermissions,
 			Flags: []cli.Flag{
 				signAddrFlag,
@@ -369,6 +624,10 @@ func main() {
 		// outputCmd,
 		sendCmd,
 		nameCmd,
+		dnsCmd,
+		namesCmd,
+		faucetCmd,
+		serveCmd,
 		callCmd,
 		bondCmd,
 		unbondCmd,